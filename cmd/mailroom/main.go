@@ -24,6 +24,7 @@ import (
 	_ "github.com/nyaruka/mailroom/core/tasks/handler"
 	_ "github.com/nyaruka/mailroom/core/tasks/handler/ctasks"
 	_ "github.com/nyaruka/mailroom/core/tasks/interrupts"
+	_ "github.com/nyaruka/mailroom/core/tasks/lookups"
 	_ "github.com/nyaruka/mailroom/core/tasks/msgs"
 	_ "github.com/nyaruka/mailroom/core/tasks/starts"
 	_ "github.com/nyaruka/mailroom/services/airtime/dtone"
@@ -35,6 +36,7 @@ import (
 	_ "github.com/nyaruka/mailroom/services/llm/google"
 	_ "github.com/nyaruka/mailroom/services/llm/openai"
 	_ "github.com/nyaruka/mailroom/services/llm/openai_azure"
+	_ "github.com/nyaruka/mailroom/services/lookup/dtone"
 	_ "github.com/nyaruka/mailroom/web/android"
 	_ "github.com/nyaruka/mailroom/web/campaign"
 	_ "github.com/nyaruka/mailroom/web/contact"
@@ -45,8 +47,10 @@ import (
 	_ "github.com/nyaruka/mailroom/web/msg"
 	_ "github.com/nyaruka/mailroom/web/org"
 	_ "github.com/nyaruka/mailroom/web/po"
+	_ "github.com/nyaruka/mailroom/web/schedule"
 	_ "github.com/nyaruka/mailroom/web/simulation"
 	_ "github.com/nyaruka/mailroom/web/ticket"
+	_ "github.com/nyaruka/mailroom/web/trigger"
 )
 
 var (
@@ -11,6 +11,12 @@ import (
 //go:embed templates/categorize.txt
 var categorize string
 
+//go:embed templates/detect_language.txt
+var detectLanguage string
+
+//go:embed templates/moderate.txt
+var moderate string
+
 //go:embed templates/translate.txt
 var translate string
 
@@ -19,6 +25,8 @@ var translateUnknownFrom string
 
 var templates = map[string]*template.Template{
 	"categorize":             template.Must(template.New("").Parse(categorize)),
+	"detect_language":        template.Must(template.New("").Parse(detectLanguage)),
+	"moderate":               template.Must(template.New("").Parse(moderate)),
 	"translate":              template.Must(template.New("").Parse(translate)),
 	"translate_unknown_from": template.Must(template.New("").Parse(translateUnknownFrom)),
 }
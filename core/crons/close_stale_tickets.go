@@ -0,0 +1,179 @@
+package crons
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/msgio"
+	"github.com/nyaruka/mailroom/core/tasks/handler"
+	"github.com/nyaruka/mailroom/core/tasks/handler/ctasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+func init() {
+	Register("close_stale_tickets", &CloseStaleTicketsCron{FetchBatchSize: 1_000})
+}
+
+type CloseStaleTicketsCron struct {
+	FetchBatchSize int
+}
+
+func (c *CloseStaleTicketsCron) Next(last time.Time) time.Time {
+	return Next(last, 15*time.Minute)
+}
+
+func (c *CloseStaleTicketsCron) AllInstances() bool {
+	return false
+}
+
+// Run closes open tickets which have been inactive for longer than their topic or org auto-close policy allows,
+// optionally sending the contact a message before the ticket is closed
+func (c *CloseStaleTicketsCron) Run(ctx context.Context, rt *runtime.Runtime) (map[string]any, error) {
+	systemUserID, err := models.GetSystemUserID(ctx, rt.DB.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	numClosed, numMessaged := 0, 0
+
+	for {
+		stale, err := models.LoadStaleTickets(ctx, rt.DB, dates.Now(), c.FetchBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		byOrg := make(map[models.OrgID][]*models.StaleTicket)
+		for _, s := range stale {
+			byOrg[s.OrgID] = append(byOrg[s.OrgID], s)
+		}
+
+		batchClosed := 0
+
+		for orgID, orgStale := range byOrg {
+			closed, messaged, err := c.closeForOrg(ctx, rt, orgID, systemUserID, orgStale)
+			if err != nil {
+				return nil, fmt.Errorf("error closing stale tickets for org #%d: %w", orgID, err)
+			}
+			batchClosed += closed
+			numMessaged += messaged
+		}
+
+		numClosed += batchClosed
+
+		// stop if that was the last batch or if we couldn't close any of it, as we'd just fetch the same tickets again
+		if len(stale) < c.FetchBatchSize || batchClosed == 0 {
+			break
+		}
+	}
+
+	return map[string]any{"closed": numClosed, "messaged": numMessaged}, nil
+}
+
+func (c *CloseStaleTicketsCron) closeForOrg(ctx context.Context, rt *runtime.Runtime, orgID models.OrgID, userID models.UserID, stale []*models.StaleTicket) (int, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, orgID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error loading org assets: %w", err)
+	}
+
+	ids := make([]models.TicketID, len(stale))
+	messages := make(map[models.TicketID]string, len(stale))
+	for i, s := range stale {
+		ids[i] = s.TicketID
+		if s.Message != "" {
+			messages[s.TicketID] = s.Message
+		}
+	}
+
+	tickets, err := models.LoadTickets(ctx, rt.DB, ids)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error loading tickets: %w", err)
+	}
+
+	// tickets may have been closed since we fetched them
+	open := make([]*models.Ticket, 0, len(tickets))
+	for _, t := range tickets {
+		if t.Status() == models.TicketStatusOpen {
+			open = append(open, t)
+		}
+	}
+
+	// send contacts their closing messages first so that they're part of the ticket before it's closed
+	msgs, err := c.createClosingMessages(ctx, rt, oa, userID, open, messages)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	evts, err := models.CloseTickets(ctx, rt, oa, userID, open)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error closing tickets: %w", err)
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	for t, e := range evts {
+		if err := handler.QueueTask(rc, e.OrgID(), e.ContactID(), ctasks.NewTicketClosed(t.ID())); err != nil {
+			return 0, 0, fmt.Errorf("error queueing ticket closed task %d: %w", t.ID(), err)
+		}
+	}
+
+	return len(evts), len(msgs), nil
+}
+
+// creates and queues the messages to send to contacts whose tickets are being closed
+func (c *CloseStaleTicketsCron) createClosingMessages(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, userID models.UserID, tickets []*models.Ticket, messages map[models.TicketID]string) ([]*models.Msg, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	contactIDs := make([]models.ContactID, 0, len(messages))
+	for _, t := range tickets {
+		if messages[t.ID()] != "" {
+			contactIDs = append(contactIDs, t.ContactID())
+		}
+	}
+
+	contacts, err := models.LoadContacts(ctx, rt.DB, oa, contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error loading contacts: %w", err)
+	}
+	contactsByID := make(map[models.ContactID]*models.Contact, len(contacts))
+	for _, mc := range contacts {
+		contactsByID[mc.ID()] = mc
+	}
+
+	msgs := make([]*models.Msg, 0, len(messages))
+
+	for _, t := range tickets {
+		text, mc := messages[t.ID()], contactsByID[t.ContactID()]
+		if text == "" || mc == nil {
+			continue
+		}
+
+		contact, err := mc.FlowContact(oa)
+		if err != nil {
+			return nil, fmt.Errorf("error creating flow contact: %w", err)
+		}
+
+		content := &flows.MsgContent{Text: text}
+		out, ch := models.CreateMsgOut(rt, oa, contact, content, models.NilTemplateID, nil, contact.Locale(oa.Env()), nil)
+
+		msg, err := models.NewOutgoingTicketMsg(rt, oa.Org(), ch, contact, out, t.ID(), userID)
+		if err != nil {
+			return nil, fmt.Errorf("error creating outgoing message: %w", err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := models.InsertMessages(ctx, rt.DB, msgs); err != nil {
+		return nil, fmt.Errorf("error inserting messages: %w", err)
+	}
+
+	msgio.QueueMessages(ctx, rt, msgs)
+
+	return msgs, nil
+}
@@ -0,0 +1,53 @@
+package crons_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/crons"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseStaleTickets(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	now := time.Date(2024, 11, 15, 13, 59, 0, 0, time.UTC)
+	defer dates.SetNowFunc(time.Now)
+	dates.SetNowFunc(dates.NewFixedNow(now))
+
+	// org 1 closes tickets after 7 days but sales topic closes them after 2 days with a message
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"ticket_auto_close": {"inactive_days": 7}}'::jsonb WHERE id = $1`, testdata.Org1.ID)
+	rt.DB.MustExec(`UPDATE tickets_topic SET config = '{"auto_close": {"inactive_days": 2, "message": "We're closing your ticket"}}' WHERE id = $1`, testdata.SalesTopic.ID)
+
+	ticket1 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, now.Add(-8*24*time.Hour), nil)
+	ticket2 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.DefaultTopic, now.Add(-3*24*time.Hour), nil)
+	ticket3 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.George, testdata.SalesTopic, now.Add(-3*24*time.Hour), nil)
+
+	// org 2 has no policy
+	ticket4 := testdata.InsertOpenTicket(rt, testdata.Org2, testdata.Org2Contact, testdata.DefaultTopic, now.Add(-30*24*time.Hour), nil)
+
+	cron := &crons.CloseStaleTicketsCron{FetchBatchSize: 1}
+	res, err := cron.Run(ctx, rt)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"closed": 2, "messaged": 1}, res)
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticket WHERE status = 'C' AND id IN ($1, $2)`, ticket1.ID, ticket3.ID).Returns(2)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticket WHERE status = 'O' AND id IN ($1, $2)`, ticket2.ID, ticket4.ID).Returns(2)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE event_type = 'C' AND created_by_id = (SELECT id FROM users_user WHERE email = 'system')`).Returns(2)
+	assertdb.Query(t, rt.DB, `SELECT text, ticket_id FROM msgs_msg WHERE contact_id = $1 AND direction = 'O'`, testdata.George.ID).Columns(map[string]any{"text": "We're closing your ticket", "ticket_id": int64(ticket3.ID)})
+
+	testsuite.AssertContactTasks(t, testdata.Org1, testdata.Cathy, []string{
+		fmt.Sprintf(`{"type":"ticket_closed","task":{"ticket_id":%d},"queued_on":"2024-11-15T13:59:00Z"}`, ticket1.ID),
+	})
+
+	res, err = cron.Run(ctx, rt)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"closed": 0, "messaged": 0}, res)
+}
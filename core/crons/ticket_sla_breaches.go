@@ -0,0 +1,113 @@
+package crons
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks/handler"
+	"github.com/nyaruka/mailroom/core/tasks/handler/ctasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+const (
+	// how far back we look for breaches, so breaches are missed if this cron doesn't run for this long
+	slaBreachWindow = 24 * time.Hour
+
+	// how long we remember that we've handled a breach, which must be longer than the window
+	slaBreachHandledExpires = 48 * 60 * 60
+)
+
+func init() {
+	Register("ticket_sla_breaches", &TicketSLABreachesCron{})
+}
+
+type TicketSLABreachesCron struct{}
+
+func (c *TicketSLABreachesCron) Next(last time.Time) time.Time {
+	return Next(last, time.Minute)
+}
+
+func (c *TicketSLABreachesCron) AllInstances() bool {
+	return false
+}
+
+// Run looks for open tickets which have breached their topic's SLA, notifies their assignees and admins, reassigns
+// them if the SLA requires it, and queues tasks to fire any SLA breached triggers
+func (c *TicketSLABreachesCron) Run(ctx context.Context, rt *runtime.Runtime) (map[string]any, error) {
+	now := dates.Now()
+
+	breaches, err := models.LoadTicketSLABreaches(ctx, rt.DB, now, now.Add(-slaBreachWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	// filter out breaches we've already handled and organize by org
+	byOrg := make(map[models.OrgID][]*models.TicketSLABreach)
+	for _, b := range breaches {
+		handled, err := redis.Bool(rc.Do("EXISTS", slaBreachHandledKey(b)))
+		if err != nil {
+			return nil, fmt.Errorf("error checking ticket SLA breach: %w", err)
+		}
+		if !handled {
+			byOrg[b.OrgID] = append(byOrg[b.OrgID], b)
+		}
+	}
+
+	numBreaches, numReassigned := 0, 0
+
+	for orgID, orgBreaches := range byOrg {
+		oa, err := models.GetOrgAssets(ctx, rt, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading org assets for org #%d: %w", orgID, err)
+		}
+
+		if err := models.NotifyTicketSLABreaches(ctx, rt.DB, oa, orgBreaches); err != nil {
+			return nil, fmt.Errorf("error notifying ticket SLA breaches: %w", err)
+		}
+
+		reassignIDs := make([]models.TicketID, 0, len(orgBreaches))
+		for _, b := range orgBreaches {
+			topic := oa.TopicByID(b.TopicID)
+			if topic != nil && topic.SLA() != nil && topic.SLA().Reassign {
+				reassignIDs = append(reassignIDs, b.TicketID)
+			}
+
+			if err := handler.QueueTask(rc, b.OrgID, b.ContactID, ctasks.NewTicketSLABreached(b.TicketID, b.Type)); err != nil {
+				return nil, fmt.Errorf("error queueing ticket SLA breached task for ticket #%d: %w", b.TicketID, err)
+			}
+		}
+
+		if len(reassignIDs) > 0 {
+			tickets, err := models.LoadTickets(ctx, rt.DB, reassignIDs)
+			if err != nil {
+				return nil, fmt.Errorf("error loading tickets to reassign: %w", err)
+			}
+			if err := models.AutoReassignTickets(ctx, rt, oa, tickets); err != nil {
+				return nil, fmt.Errorf("error reassigning tickets: %w", err)
+			}
+			numReassigned += len(tickets)
+		}
+
+		// only now record these breaches as handled so that they're retried if anything above failed
+		for _, b := range orgBreaches {
+			if _, err := rc.Do("SET", slaBreachHandledKey(b), "1", "EX", slaBreachHandledExpires); err != nil {
+				return nil, fmt.Errorf("error recording ticket SLA breach: %w", err)
+			}
+		}
+
+		numBreaches += len(orgBreaches)
+	}
+
+	return map[string]any{"breaches": numBreaches, "reassigned": numReassigned}, nil
+}
+
+func slaBreachHandledKey(b *models.TicketSLABreach) string {
+	return fmt.Sprintf("ticket_sla_breach:%d:%s", b.TicketID, b.Type)
+}
@@ -0,0 +1,62 @@
+package crons_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/crons"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketSLABreaches(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData | testsuite.ResetRedis)
+
+	now := time.Date(2024, 11, 15, 13, 59, 0, 0, time.UTC)
+	defer dates.SetNowFunc(time.Now)
+	dates.SetNowFunc(dates.NewFixedNow(now))
+
+	rt.DB.MustExec(`UPDATE tickets_topic SET config = '{"sla": {"first_response": 60}}' WHERE id = $1`, testdata.SalesTopic.ID)
+	rt.DB.MustExec(`UPDATE tickets_topic SET config = '{"sla": {"resolution": 120, "reassign": true}, "assignment": {"mode": "least_open"}}' WHERE id = $1`, testdata.SupportTopic.ID)
+
+	testdata.InsertTicketSLABreachedTrigger(rt, testdata.Org1, testdata.Favorites)
+
+	// unreplied ticket opened 2 hours ago breaches first response SLA
+	ticket1 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.SalesTopic, now.Add(-2*time.Hour), nil)
+
+	// ticket opened 30 minutes ago hasn't breached anything yet
+	testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.SalesTopic, now.Add(-30*time.Minute), nil)
+
+	// ticket opened 3 hours ago breaches resolution SLA and should be reassigned
+	ticket3 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.George, testdata.SupportTopic, now.Add(-3*time.Hour), testdata.Agent)
+	testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Alexandria, testdata.SupportTopic, now, testdata.Agent)
+
+	// ticket which breached too long ago is ignored
+	testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.SupportTopic, now.Add(-72*time.Hour), nil)
+
+	// topic without SLA
+	testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.DefaultTopic, now.Add(-3*time.Hour), nil)
+
+	cron := &crons.TicketSLABreachesCron{}
+	res, err := cron.Run(ctx, rt)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"breaches": 2, "reassigned": 1}, res)
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM notifications_notification WHERE notification_type = 'tickets:response_breached' AND scope = $1 AND user_id = $2`, ticket1.UUID, testdata.Admin.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM notifications_notification WHERE notification_type = 'tickets:resolution_breached' AND scope = $1 AND user_id = $2`, ticket3.UUID, testdata.Agent.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticket WHERE id = $1 AND assignee_id != $2`, ticket3.ID, testdata.Agent.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = $1 AND event_type = 'A' AND created_by_id IS NULL`, ticket3.ID).Returns(1)
+
+	testsuite.AssertContactTasks(t, testdata.Org1, testdata.Cathy, []string{fmt.Sprintf(`{"type":"ticket_sla_breached","task":{"ticket_id":%d,"sla_type":"first_response"},"queued_on":"2024-11-15T13:59:00Z"}`, ticket1.ID)})
+
+	// breaches are only handled once
+	res, err = cron.Run(ctx, rt)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"breaches": 0, "reassigned": 0}, res)
+}
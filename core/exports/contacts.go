@@ -0,0 +1,227 @@
+package exports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/search"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+const (
+	// number of contact ids to fetch from Elastic at a time
+	contactsSearchBatchSize = 10_000
+
+	// number of contacts to load from the database at a time
+	contactsBatchSize = 500
+)
+
+// ExportContacts writes the contacts of the given export to a file which is streamed to storage, returning the number
+// of contacts written and the storage path of the file
+func ExportContacts(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, e *models.Export) (int, string, error) {
+	config, err := e.ContactConfig()
+	if err != nil {
+		return 0, "", err
+	}
+
+	group := oa.GroupByID(config.GroupID)
+	if group == nil {
+		return 0, "", fmt.Errorf("no such group with id %d", config.GroupID)
+	}
+
+	// write to a temporary file rather than memory as exports can be very large
+	f, err := os.CreateTemp("", fmt.Sprintf("export-*.%s", config.Format))
+	if err != nil {
+		return 0, "", fmt.Errorf("error creating temporary export file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	ex := newContactExporter(oa, config)
+
+	w, err := NewWriter(config.Format, f, ex.columns())
+	if err != nil {
+		return 0, "", err
+	}
+
+	// page through the matching contacts rather than loading all their ids into memory, writing each page as we go
+	numWritten := 0
+	err = search.GetContactIDsForQueryBatches(ctx, rt, oa, group, models.NilContactStatus, config.Query, contactsSearchBatchSize, func(ids []models.ContactID) error {
+		for idBatch := range slices.Chunk(ids, contactsBatchSize) {
+			n, err := ex.writeBatch(ctx, rt, w, idBatch)
+			if err != nil {
+				return err
+			}
+			numWritten += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("error exporting contacts: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, "", fmt.Errorf("error finishing export file: %w", err)
+	}
+
+	path := fmt.Sprintf("orgs/%d/exports/%s.%s", e.OrgID, e.UUID, config.Format)
+
+	if err := uploadFile(ctx, rt, f, path, ContentType(config.Format)); err != nil {
+		return 0, "", err
+	}
+
+	return numWritten, path, nil
+}
+
+// uploads the given file to storage without reading it into memory
+func uploadFile(ctx context.Context, rt *runtime.Runtime, f *os.File, path, contentType string) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error rewinding export file: %w", err)
+	}
+
+	_, err := rt.S3.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(rt.Config.S3AttachmentsBucket),
+		Key:         aws.String(path),
+		Body:        f,
+		ContentType: aws.String(contentType),
+		ACL:         types.ObjectCannedACLPrivate,
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading export file to storage: %w", err)
+	}
+	return nil
+}
+
+// turns contacts into export rows according to the selected fields, URN schemes and groups
+type contactExporter struct {
+	oa         *models.OrgAssets
+	tz         *time.Location
+	urnSchemes []string
+	fields     []*flows.Field
+	groups     []*models.Group
+}
+
+func newContactExporter(oa *models.OrgAssets, config *models.ContactExportConfig) *contactExporter {
+	ex := &contactExporter{oa: oa, tz: oa.Env().Timezone(), urnSchemes: config.URNSchemes}
+
+	// fields and groups which have been deleted since the export was requested are skipped
+	for _, key := range config.Fields {
+		if f := oa.SessionAssets().Fields().Get(key); f != nil {
+			ex.fields = append(ex.fields, f)
+		}
+	}
+	for _, id := range config.GroupIDs {
+		if g := oa.GroupByID(id); g != nil {
+			ex.groups = append(ex.groups, g)
+		}
+	}
+
+	return ex
+}
+
+func (x *contactExporter) columns() []Column {
+	cols := []Column{
+		{"uuid", "Contact UUID"},
+		{"name", "Name"},
+		{"language", "Language"},
+		{"status", "Status"},
+		{"created_on", "Created On"},
+		{"last_seen_on", "Last Seen On"},
+	}
+	for _, scheme := range x.urnSchemes {
+		cols = append(cols, Column{"urn:" + scheme, "URN:" + strings.ToUpper(scheme[:1]) + scheme[1:]})
+	}
+	for _, f := range x.fields {
+		cols = append(cols, Column{"field:" + f.Key(), "Field:" + f.Name()})
+	}
+	for _, g := range x.groups {
+		cols = append(cols, Column{"group:" + string(g.UUID()), "Group:" + g.Name()})
+	}
+	return cols
+}
+
+// writes the given contacts, returning how many were written as contacts may have been deleted since the search
+func (x *contactExporter) writeBatch(ctx context.Context, rt *runtime.Runtime, w Writer, ids []models.ContactID) (int, error) {
+	contacts, err := models.LoadContacts(ctx, rt.ReadonlyDB, x.oa, ids)
+	if err != nil {
+		return 0, fmt.Errorf("error loading contacts to export: %w", err)
+	}
+
+	// keep contacts in the order they were found in
+	byID := make(map[models.ContactID]*models.Contact, len(contacts))
+	for _, c := range contacts {
+		byID[c.ID()] = c
+	}
+
+	for _, id := range ids {
+		c := byID[id]
+		if c == nil {
+			continue
+		}
+
+		fc, err := c.FlowContact(x.oa)
+		if err != nil {
+			return 0, fmt.Errorf("error creating flow contact: %w", err)
+		}
+
+		if err := w.WriteRow(x.row(fc)); err != nil {
+			return 0, fmt.Errorf("error writing contact to export: %w", err)
+		}
+	}
+
+	return len(byID), nil
+}
+
+func (x *contactExporter) row(c *flows.Contact) []any {
+	row := []any{string(c.UUID()), nilIfEmpty(c.Name()), nilIfEmpty(string(c.Language())), string(c.Status()), x.formatTime(c.CreatedOn())}
+	if c.LastSeenOn() != nil {
+		row = append(row, x.formatTime(*c.LastSeenOn()))
+	} else {
+		row = append(row, nil)
+	}
+
+	for _, scheme := range x.urnSchemes {
+		paths := []string{}
+		for _, u := range c.URNs() {
+			if u.URN().Scheme() == scheme {
+				paths = append(paths, u.URN().Path())
+			}
+		}
+		row = append(row, paths)
+	}
+
+	for _, f := range x.fields {
+		if v := c.Fields().Get(f); v != nil {
+			row = append(row, v.Text.Native())
+		} else {
+			row = append(row, nil)
+		}
+	}
+
+	for _, g := range x.groups {
+		row = append(row, c.Groups().FindByUUID(g.UUID()) != nil)
+	}
+
+	return row
+}
+
+func (x *contactExporter) formatTime(t time.Time) string {
+	return t.In(x.tz).Format(time.RFC3339)
+}
+
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
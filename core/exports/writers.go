@@ -0,0 +1,285 @@
+package exports
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/stringsx"
+	"github.com/nyaruka/mailroom/core/models"
+)
+
+const (
+	// maximum number of rows in an XLSX sheet including the header row, after which we start a new sheet
+	xlsxMaxRows = 1048576
+
+	// maximum number of characters in an XLSX cell
+	xlsxMaxCellLength = 32767
+)
+
+// Column is a column of an export, with a key used in NDJSON and a header used in CSV and XLSX
+type Column struct {
+	Key    string
+	Header string
+}
+
+// Writer streams the rows of an export to a file of a particular format. Row values can be strings, bools, lists of
+// strings or nil.
+type Writer interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter creates a new writer of the given format which writes to w
+func NewWriter(format models.ExportFormat, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case models.ExportFormatCSV:
+		return newCSVWriter(w, columns)
+	case models.ExportFormatXLSX:
+		return newXLSXWriter(w, columns), nil
+	case models.ExportFormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+// ContentType returns the content type of files of the given format
+func ContentType(format models.ExportFormat) string {
+	switch format {
+	case models.ExportFormatCSV:
+		return "text/csv"
+	case models.ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case models.ExportFormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/octet-stream"
+}
+
+// formats a value as text for the tabular formats
+func formatText(v any) string {
+	switch typed := v.(type) {
+	case string:
+		return typed
+	case bool:
+		if typed {
+			return "true"
+		}
+		return "false"
+	case []string:
+		return strings.Join(typed, ", ")
+	}
+	return ""
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	if err := cw.w.Write(headers); err != nil {
+		return nil, fmt.Errorf("error writing CSV header: %w", err)
+	}
+
+	return cw, nil
+}
+
+func (w *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatText(v)
+	}
+	return w.w.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, c := range columns {
+		keys[i] = jsonx.MustMarshal(c.Key)
+	}
+	return &ndjsonWriter{w: bufio.NewWriter(w), keys: keys}
+}
+
+// writes each row as a JSON object on its own line, with keys in column order
+func (w *ndjsonWriter) WriteRow(values []any) error {
+	w.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		val, err := jsonx.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("error marshaling value for %s: %w", w.keys[i], err)
+		}
+		w.w.Write(w.keys[i])
+		w.w.WriteByte(':')
+		w.w.Write(val)
+	}
+	w.w.WriteByte('}')
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.w.Flush()
+}
+
+// writes a minimal XLSX workbook, streaming rows to one sheet after another as each fills up. Cells are written as
+// inline strings so that there's no shared strings table to hold in memory.
+type xlsxWriter struct {
+	zw      *zip.Writer
+	headers []any
+
+	sheet     *bufio.Writer
+	numSheets int
+	numRows   int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) *xlsxWriter {
+	headers := make([]any, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	return &xlsxWriter{zw: zip.NewWriter(w), headers: headers}
+}
+
+func (w *xlsxWriter) WriteRow(values []any) error {
+	if w.sheet == nil || w.numRows >= xlsxMaxRows {
+		if err := w.startSheet(); err != nil {
+			return err
+		}
+	}
+	return w.writeRow(values)
+}
+
+func (w *xlsxWriter) startSheet() error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	f, err := w.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", w.numSheets+1))
+	if err != nil {
+		return fmt.Errorf("error creating XLSX sheet: %w", err)
+	}
+
+	w.sheet = bufio.NewWriter(f)
+	w.numSheets++
+	w.numRows = 0
+
+	w.sheet.WriteString(xml.Header)
+	w.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return w.writeRow(w.headers)
+}
+
+func (w *xlsxWriter) writeRow(values []any) error {
+	w.sheet.WriteString("<row>")
+	for _, v := range values {
+		switch typed := v.(type) {
+		case nil:
+			w.sheet.WriteString("<c/>")
+		case bool:
+			if typed {
+				w.sheet.WriteString(`<c t="b"><v>1</v></c>`)
+			} else {
+				w.sheet.WriteString(`<c t="b"><v>0</v></c>`)
+			}
+		default:
+			w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(w.sheet, []byte(stringsx.Truncate(formatText(v), xlsxMaxCellLength))); err != nil {
+				return fmt.Errorf("error writing XLSX cell: %w", err)
+			}
+			w.sheet.WriteString("</t></is></c>")
+		}
+	}
+	_, err := w.sheet.WriteString("</row>")
+	w.numRows++
+	return err
+}
+
+func (w *xlsxWriter) endSheet() error {
+	if w.sheet == nil {
+		return nil
+	}
+
+	w.sheet.WriteString("</sheetData></worksheet>")
+	if err := w.sheet.Flush(); err != nil {
+		return fmt.Errorf("error writing XLSX sheet: %w", err)
+	}
+	w.sheet = nil
+	return nil
+}
+
+// finishes the last sheet and writes the parts of the workbook that need to know how many sheets there are
+func (w *xlsxWriter) Close() error {
+	if w.sheet == nil {
+		if err := w.startSheet(); err != nil {
+			return err
+		}
+	}
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	var contentTypes, sheets, sheetRels strings.Builder
+	for i := 1; i <= w.numSheets; i++ {
+		name := "Contacts"
+		if i > 1 {
+			name = fmt.Sprintf("Contacts (%d)", i)
+		}
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, i, i)
+		fmt.Fprintf(&sheetRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			contentTypes.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			sheetRels.String() + `</Relationships>`},
+	}
+
+	for _, p := range parts {
+		f, err := w.zw.Create(p.name)
+		if err != nil {
+			return fmt.Errorf("error creating XLSX part %s: %w", p.name, err)
+		}
+		if _, err := io.WriteString(f, xml.Header+p.content); err != nil {
+			return fmt.Errorf("error writing XLSX part %s: %w", p.name, err)
+		}
+	}
+
+	return w.zw.Close()
+}
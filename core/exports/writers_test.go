@@ -0,0 +1,70 @@
+package exports_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/nyaruka/mailroom/core/exports"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriters(t *testing.T) {
+	cols := []exports.Column{{"name", "Name"}, {"urn:tel", "URN:Tel"}, {"group:1234", "Group:Doctors"}}
+	rows := [][]any{
+		{"Bob", []string{"+16055741111", "+16055742222"}, true},
+		{nil, []string{}, false},
+		{`Jim "<&>"`, []string{"+16055743333"}, false},
+	}
+
+	write := func(format models.ExportFormat) []byte {
+		b := &bytes.Buffer{}
+		w, err := exports.NewWriter(format, b, cols)
+		require.NoError(t, err)
+		for _, r := range rows {
+			require.NoError(t, w.WriteRow(r))
+		}
+		require.NoError(t, w.Close())
+		return b.Bytes()
+	}
+
+	assert.Equal(t, "Name,URN:Tel,Group:Doctors\nBob,\"+16055741111, +16055742222\",true\n,,false\n\"Jim \"\"<&>\"\"\",+16055743333,false\n", string(write(models.ExportFormatCSV)))
+
+	assert.Equal(t, `{"name":"Bob","urn:tel":["+16055741111","+16055742222"],"group:1234":true}
+{"name":null,"urn:tel":[],"group:1234":false}
+{"name":"Jim \"<&>\"","urn:tel":["+16055743333"],"group:1234":false}
+`, string(write(models.ExportFormatNDJSON)))
+
+	xlsx := write(models.ExportFormatXLSX)
+	zr, err := zip.NewReader(bytes.NewReader(xlsx), int64(len(xlsx)))
+	require.NoError(t, err)
+
+	parts := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = string(content)
+	}
+
+	assert.Len(t, parts, 5)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Contacts" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["[Content_Types].xml"], `<Override PartName="/xl/worksheets/sheet1.xml"`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<row><c t="inlineStr"><is><t xml:space="preserve">Name</t></is></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<row><c/><c t="inlineStr"><is><t xml:space="preserve"></t></is></c><c t="b"><v>0</v></c></row>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<t xml:space="preserve">Jim &#34;&lt;&amp;&gt;&#34;</t>`)
+
+	// an export with no rows still has a header row
+	b := &bytes.Buffer{}
+	w, err := exports.NewWriter(models.ExportFormatCSV, b, cols)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "Name,URN:Tel,Group:Doctors\n", b.String())
+
+	_, err = exports.NewWriter("pdf", b, cols)
+	assert.EqualError(t, err, "unsupported export format: pdf")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/nyaruka/gocommon/i18n"
 	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/modifiers"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/runner"
@@ -57,11 +58,20 @@ func ImportBatch(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets,
 	}
 
 	// and apply in bulk
-	_, err := runner.ApplyModifiers(ctx, rt, oa, userID, modifiersByContact)
+	eventsByContact, err := runner.ApplyModifiers(ctx, rt, oa, userID, modifiersByContact)
 	if err != nil {
 		return fmt.Errorf("error applying modifiers: %w", err)
 	}
 
+	// field values which were rejected for breaking validation rules are errors for their import
+	for _, imp := range imports {
+		for _, e := range eventsByContact[imp.flowContact] {
+			if warning, ok := e.(*events.WarningEvent); ok {
+				imp.errors = append(imp.errors, warning.Text)
+			}
+		}
+	}
+
 	if err := markBatchComplete(ctx, rt.DB, b, imports); err != nil {
 		return fmt.Errorf("unable to mark as complete: %w", err)
 	}
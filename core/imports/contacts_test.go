@@ -148,6 +148,37 @@ func TestContactImports(t *testing.T) {
 	}
 }
 
+func TestContactImportsWithFieldValidation(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	rt.DB.MustExec(`UPDATE contacts_contactfield SET validation = '{"min": 18}' WHERE id = $1`, testdata.AgeField.ID)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshFields)
+	require.NoError(t, err)
+
+	importID := testdata.InsertContactImport(rt, testdata.Org1, testdata.Admin)
+	batchID := testdata.InsertContactImportBatch(rt, importID, []byte(`[
+		{"name": "Ann", "urns": ["tel:+16055700001"], "fields": {"age": "40"}, "_import_row": 2},
+		{"name": "Bea", "urns": ["tel:+16055700002"], "fields": {"age": "12"}, "_import_row": 3}
+	]`))
+
+	batch, err := models.LoadContactImportBatch(ctx, rt.DB, batchID)
+	require.NoError(t, err)
+
+	err = imports.ImportBatch(ctx, rt, oa, batch, testdata.Admin.ID)
+	require.NoError(t, err)
+
+	// Bea is still imported but without her invalid age
+	assertdb.Query(t, rt.DB, `SELECT num_created, num_errored FROM contacts_contactimportbatch WHERE id = $1`, batchID).
+		Columns(map[string]any{"num_created": int64(2), "num_errored": int64(0)})
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactimportbatch WHERE id = $1 AND errors = $2::jsonb`, batchID,
+		`[{"record": 1, "row": 3, "message": "'12' is less than the minimum of 18 for contact field 'age'"}]`).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT fields->$2->>'text' FROM contacts_contact WHERE name = $1`, "Ann", testdata.AgeField.UUID).Returns("40")
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contact WHERE name = $1 AND fields ? $2`, "Bea", testdata.AgeField.UUID).Returns(0)
+}
+
 func TestLoadContactImport(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
@@ -0,0 +1,259 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// Validation is the result of validating a contact import without writing anything, i.e. what would happen if the
+// import was performed
+type Validation struct {
+	NumRecords int                  `json:"num_records"`
+	NumCreated int                  `json:"num_created"`
+	NumUpdated int                  `json:"num_updated"`
+	NumErrored int                  `json:"num_errored"`
+	Errors     []models.ImportError `json:"errors"`
+}
+
+// holds state across all the batches of an import being validated so that we can find duplicates within the file
+type validator struct {
+	oa  *models.OrgAssets
+	env envs.Environment
+
+	recordsByUUID map[flows.ContactUUID]int
+	recordsByURN  map[urns.URN]int
+
+	result *Validation
+}
+
+// ValidateImport validates all the batches of the given import, checking URNs, languages, statuses, field values and
+// groups, and looking for contacts which appear more than once. Nothing is written to the database.
+func ValidateImport(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, importID models.ContactImportID) (*Validation, error) {
+	batches, err := models.LoadContactImportBatches(ctx, rt.DB, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{
+		oa:            oa,
+		env:           flows.NewAssetsEnvironment(oa.Env(), oa.SessionAssets()),
+		recordsByUUID: make(map[flows.ContactUUID]int),
+		recordsByURN:  make(map[urns.URN]int),
+		result:        &Validation{Errors: make([]models.ImportError, 0, 10)},
+	}
+
+	for _, b := range batches {
+		var specs []*models.ContactSpec
+		if err := jsonx.Unmarshal(b.Specs, &specs); err != nil {
+			return nil, fmt.Errorf("error unmarsaling specs: %w", err)
+		}
+
+		if err := v.validateBatch(ctx, rt, b.RecordStart, specs); err != nil {
+			return nil, err
+		}
+	}
+
+	return v.result, nil
+}
+
+func (v *validator) validateBatch(ctx context.Context, rt *runtime.Runtime, recordStart int, specs []*models.ContactSpec) error {
+	imports := make([]*importContact, len(specs))
+	for i := range imports {
+		imports[i] = &importContact{record: recordStart + i, spec: specs[i]}
+	}
+
+	contactsByUUID, err := loadContactsByUUID(ctx, rt.DB, v.oa, imports)
+	if err != nil {
+		return fmt.Errorf("error loading contacts by UUID: %w", err)
+	}
+
+	// normalize all URNs so that we can look up their current owners in one query
+	normURNs := make([][]urns.URN, len(imports))
+	allURNs := make([]urns.URN, 0, len(imports))
+	for i, imp := range imports {
+		normURNs[i] = make([]urns.URN, 0, len(imp.spec.URNs))
+		for _, u := range imp.spec.URNs {
+			norm := u.Normalize()
+			if err := norm.Validate(); err != nil {
+				imp.errors = append(imp.errors, fmt.Sprintf("'%s' is not a valid URN", u))
+				continue
+			}
+			normURNs[i] = append(normURNs[i], norm)
+			allURNs = append(allURNs, norm)
+		}
+	}
+
+	owners, err := models.GetContactIDsFromURNs(ctx, rt.DB, v.oa.OrgID(), allURNs)
+	if err != nil {
+		return fmt.Errorf("error looking up contacts for URNs: %w", err)
+	}
+
+	for i, imp := range imports {
+		found, created := v.validateContact(imp, contactsByUUID, normURNs[i], owners)
+		v.validateProperties(imp)
+
+		// like a real import, a record is only errored if we couldn't get or create its contact
+		v.result.NumRecords++
+		if created {
+			v.result.NumCreated++
+		} else if found {
+			v.result.NumUpdated++
+		} else {
+			v.result.NumErrored++
+		}
+
+		for _, e := range imp.errors {
+			v.result.Errors = append(v.result.Errors, models.ImportError{Record: imp.record, Row: imp.spec.ImportRow, Message: e})
+		}
+	}
+
+	return nil
+}
+
+// works out whether the contact for this import would be found, created or neither
+func (v *validator) validateContact(imp *importContact, contactsByUUID map[flows.ContactUUID]*models.Contact, urnz []urns.URN, owners map[urns.URN]models.ContactID) (bool, bool) {
+	addError := func(s string, args ...any) { imp.errors = append(imp.errors, fmt.Sprintf(s, args...)) }
+	spec := imp.spec
+
+	// URNs shared with earlier records will belong to the same contact by the time this record is imported
+	for _, u := range urnz {
+		identity := u.Identity()
+		if record, seen := v.recordsByURN[identity]; seen && record != imp.record {
+			addError("URN '%s' is also used by record %d", identity, record)
+		} else if !seen {
+			v.recordsByURN[identity] = imp.record
+		}
+	}
+
+	if spec.UUID != "" {
+		if contactsByUUID[spec.UUID] == nil {
+			addError("Unable to find contact with UUID '%s'", spec.UUID)
+			return false, false
+		}
+
+		if record, seen := v.recordsByUUID[spec.UUID]; seen {
+			addError("Contact with UUID '%s' is also in record %d", spec.UUID, record)
+		} else {
+			v.recordsByUUID[spec.UUID] = imp.record
+		}
+		return true, false
+	}
+
+	// if any URNs are invalid, the contact can't be found or created
+	if len(urnz) < len(spec.URNs) {
+		return false, false
+	}
+
+	ownerIDs := make(map[models.ContactID]bool, len(urnz))
+	for _, u := range urnz {
+		if id := owners[u]; id != models.NilContactID {
+			ownerIDs[id] = true
+		}
+	}
+
+	if len(ownerIDs) > 1 {
+		urnStrs := make([]string, len(urnz))
+		for i := range urnz {
+			urnStrs[i] = string(urnz[i].Identity())
+		}
+		addError("URNs %s belong to different contacts", strings.Join(urnStrs, ", "))
+		return false, false
+	}
+
+	// created unless an existing contact or an earlier record has one of these URNs
+	created := len(ownerIDs) == 0
+	for _, u := range urnz {
+		if v.recordsByURN[u.Identity()] != imp.record {
+			created = false
+		}
+	}
+	return true, created
+}
+
+// checks the name, language, status, fields and groups of the import
+func (v *validator) validateProperties(imp *importContact) {
+	addError := func(s string, args ...any) { imp.errors = append(imp.errors, fmt.Sprintf(s, args...)) }
+	spec := imp.spec
+	sa := v.oa.SessionAssets()
+
+	isActive := spec.Status == "" || spec.Status == flows.ContactStatusActive
+
+	if spec.Language != nil {
+		if _, err := i18n.ParseLanguage(*spec.Language); err != nil {
+			addError("'%s' is not a valid language code", *spec.Language)
+		}
+	}
+	if !isActive && spec.Status != flows.ContactStatusArchived && spec.Status != flows.ContactStatusBlocked && spec.Status != flows.ContactStatusStopped {
+		addError("'%s' is not a valid status", spec.Status)
+	}
+
+	// parse location values in order of level so that districts and wards can be found within their parents
+	values := make(flows.FieldValues, len(spec.Fields))
+	for _, typ := range []assets.FieldType{assets.FieldTypeText, assets.FieldTypeNumber, assets.FieldTypeDatetime, assets.FieldTypeState, assets.FieldTypeDistrict, assets.FieldTypeWard} {
+		for _, key := range slices.Sorted(maps.Keys(spec.Fields)) {
+			raw := spec.Fields[key]
+			field := sa.Fields().Get(key)
+			if field == nil {
+				if typ == assets.FieldTypeText {
+					addError("'%s' is not a valid contact field key", key)
+				}
+				continue
+			}
+			if field.Type() != typ {
+				continue
+			}
+
+			var value *flows.Value
+			if raw != "" {
+				value = values.Parse(v.env, sa.Fields(), field, raw)
+				values.Set(field, value)
+
+				if !isValidFieldValue(typ, value) {
+					addError("'%s' is not a valid %s value for contact field '%s'", raw, typ, key)
+					continue
+				}
+			}
+
+			if err := v.oa.FieldByKey(key).Validate(value); err != nil {
+				addError("%s", err)
+			}
+		}
+	}
+
+	if len(spec.Groups) > 0 && isActive {
+		for _, uuid := range spec.Groups {
+			if sa.Groups().Get(uuid) == nil {
+				addError("'%s' is not a valid contact group UUID", uuid)
+			}
+		}
+	}
+}
+
+// checks that a parsed field value has a value of the field's type
+func isValidFieldValue(typ assets.FieldType, v *flows.Value) bool {
+	switch typ {
+	case assets.FieldTypeNumber:
+		return v.Number != nil
+	case assets.FieldTypeDatetime:
+		return v.Datetime != nil
+	case assets.FieldTypeState:
+		return v.State != ""
+	case assets.FieldTypeDistrict:
+		return v.District != ""
+	case assets.FieldTypeWard:
+		return v.Ward != ""
+	}
+	return true
+}
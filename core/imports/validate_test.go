@@ -0,0 +1,86 @@
+package imports_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/imports"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImport(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	oa := testdata.Org1.Load(rt)
+
+	importID := testdata.InsertContactImport(rt, testdata.Org1, testdata.Admin)
+	testdata.InsertContactImportBatch(rt, importID, []byte(`[
+		{"name": "Ann", "urns": ["tel:+16055700001"], "fields": {"age": "40", "joined": "2020-01-02"}, "_import_row": 2},
+		{"name": "Cathy", "urns": ["tel:+16055741111"], "_import_row": 3},
+		{"urns": ["tel:+16055742222", "tel:+16055743333"], "_import_row": 4}
+	]`))
+	batch2ID := testdata.InsertContactImportBatch(rt, importID, []byte(`[
+		{"name": "Ann", "language": "xx", "urns": ["tel:+16055700001"], "fields": {"age": "forty", "goats": "7"}, "_import_row": 5},
+		{"uuid": "8c3e2aa2-0ab1-4b7d-b1ac-1c3a0b0e4c3f", "name": "Nobody", "_import_row": 6},
+		{"name": "Zed", "urns": ["xyz:123"], "status": "sleeping", "_import_row": 7}
+	]`))
+	rt.DB.MustExec(`UPDATE contacts_contactimportbatch SET record_start = 3, record_end = 6 WHERE id = $1`, batch2ID)
+
+	validation, err := imports.ValidateImport(ctx, rt, oa, importID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, validation.NumRecords)
+	assert.Equal(t, 1, validation.NumCreated)
+	assert.Equal(t, 2, validation.NumUpdated)
+	assert.Equal(t, 3, validation.NumErrored)
+	assert.Equal(t, []models.ImportError{
+		{Record: 2, Row: 4, Message: "URNs tel:+16055742222, tel:+16055743333 belong to different contacts"},
+		{Record: 3, Row: 5, Message: "URN 'tel:+16055700001' is also used by record 0"},
+		{Record: 3, Row: 5, Message: "'xx' is not a valid language code"},
+		{Record: 3, Row: 5, Message: "'goats' is not a valid contact field key"},
+		{Record: 3, Row: 5, Message: "'forty' is not a valid number value for contact field 'age'"},
+		{Record: 4, Row: 6, Message: "Unable to find contact with UUID '8c3e2aa2-0ab1-4b7d-b1ac-1c3a0b0e4c3f'"},
+		{Record: 5, Row: 7, Message: "'xyz:123' is not a valid URN"},
+		{Record: 5, Row: 7, Message: "'sleeping' is not a valid status"},
+	}, validation.Errors)
+
+	// nothing should have been written
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE identity = 'tel:+16055700001'`).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactimportbatch WHERE contact_import_id = $1 AND status = 'P'`, importID).Returns(2)
+}
+
+func TestValidateImportWithFieldValidation(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	rt.DB.MustExec(`UPDATE contacts_contactfield SET validation = '{"min": 18}' WHERE id = $1`, testdata.AgeField.ID)
+	rt.DB.MustExec(`UPDATE contacts_contactfield SET validation = '{"required": true, "allowed": ["F", "M"]}' WHERE id = $1`, testdata.GenderField.ID)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshFields)
+	require.NoError(t, err)
+
+	importID := testdata.InsertContactImport(rt, testdata.Org1, testdata.Admin)
+	testdata.InsertContactImportBatch(rt, importID, []byte(`[
+		{"name": "Ann", "urns": ["tel:+16055700001"], "fields": {"age": "40", "gender": "F"}, "_import_row": 2},
+		{"name": "Bea", "urns": ["tel:+16055700002"], "fields": {"age": "12", "gender": ""}, "_import_row": 3},
+		{"name": "Cat", "urns": ["tel:+16055700003"], "fields": {"age": "old", "gender": "X"}, "_import_row": 4}
+	]`))
+
+	validation, err := imports.ValidateImport(ctx, rt, oa, importID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, validation.NumCreated)
+	assert.Equal(t, []models.ImportError{
+		{Record: 1, Row: 3, Message: "a value is required for contact field 'gender'"},
+		{Record: 1, Row: 3, Message: "'12' is less than the minimum of 18 for contact field 'age'"},
+		{Record: 2, Row: 4, Message: "'X' is not an allowed value for contact field 'gender'"},
+		{Record: 2, Row: 4, Message: "'old' is not a valid number value for contact field 'age'"},
+	}, validation.Errors)
+}
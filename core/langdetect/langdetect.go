@@ -0,0 +1,56 @@
+package langdetect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// Detector detects the language of the given text, returning nil if it couldn't be detected
+type Detector func(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, config *models.LanguageDetection, text string) (*models.DetectedLanguage, error)
+
+var detectors = map[string]Detector{}
+
+// detectors whose detections are reliable enough to be used to set a contact's language
+var reliable = map[string]bool{}
+
+func init() {
+	RegisterDetector("ngram", detectNGram, false)
+	RegisterDetector("llm", detectLLM, true)
+}
+
+// RegisterDetector registers a language detector which orgs can select by name in their config. Detections by
+// unreliable detectors are only recorded on messages and never used to set contact languages.
+func RegisterDetector(name string, fn Detector, isReliable bool) {
+	detectors[name] = fn
+	reliable[name] = isReliable
+}
+
+// IsReliable returns whether detections by the given detector can be used to set a contact's language
+func IsReliable(name string) bool {
+	return reliable[name]
+}
+
+// Detect detects the language of the given text using the org's configured detector. Returns the org's config, which
+// is nil if org doesn't have language detection enabled, and the detected language, which is nil if not detected.
+func Detect(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, text string) (*models.LanguageDetection, *models.DetectedLanguage, error) {
+	config := oa.Org().LanguageDetection()
+	if config == nil || strings.TrimSpace(text) == "" {
+		return config, nil, nil
+	}
+
+	fn := detectors[config.Detector]
+	if fn == nil {
+		return nil, nil, fmt.Errorf("no such language detector: %s", config.Detector)
+	}
+
+	detected, err := fn(ctx, rt, oa, config, text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error running %s language detector: %w", config.Detector, err)
+	}
+
+	return config, detected, nil
+}
@@ -0,0 +1,80 @@
+package langdetect_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/mailroom/core/langdetect"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	// no config means no detection
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	config, detected, err := langdetect.Detect(ctx, rt, oa, "Hello, how are you doing today?")
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+	assert.Nil(t, detected)
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"language_detection": {"detector": "ngram"}}' WHERE id = $1`, testdata.Org1.ID)
+
+	oa, err = models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	tcs := []struct {
+		text     string
+		expected string
+	}{
+		{"Hello, how are you doing today?", "eng"},
+		{"Hola, ¿cómo estás? Quiero información sobre la clínica", "spa"},
+		{"Bonjour, je voudrais savoir où est la clinique", "fra"},
+		{"Olá, eu preciso de ajuda com a minha conta", "por"},
+		{"Ich habe eine Frage zu meinem Konto", "deu"},
+		{"Habari yako, nahitaji msaada tafadhali", "swa"},
+		{"see you at the clinic", ""}, // too short
+		{"yes", ""},                   // too short
+		{"", ""},
+	}
+
+	for _, tc := range tcs {
+		config, detected, err := langdetect.Detect(ctx, rt, oa, tc.text)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.LanguageDetection{Detector: "ngram", MinConfidence: 0.8}, config)
+
+		if tc.expected == "" {
+			assert.Nil(t, detected, "expected no language for '%s'", tc.text)
+		} else if assert.NotNil(t, detected, "expected language for '%s'", tc.text) {
+			assert.Equal(t, tc.expected, string(detected.Language), "language mismatch for '%s'", tc.text)
+			assert.Greater(t, detected.Confidence, 0.8)
+		}
+	}
+
+	assert.False(t, langdetect.IsReliable("ngram"))
+	assert.True(t, langdetect.IsReliable("llm"))
+	assert.False(t, langdetect.IsReliable("xxx"))
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"language_detection": {"detector": "llm", "llm_uuid": "e5d8900a-ef54-4d2a-8214-ff7d3e903502", "min_confidence": 0.9}}' WHERE id = $1`, testdata.Org1.ID)
+
+	oa, err = models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	_, detected, err = langdetect.Detect(ctx, rt, oa, `\return kin 0.95`)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.DetectedLanguage{Language: "kin", Confidence: 0.95}, detected)
+
+	_, detected, err = langdetect.Detect(ctx, rt, oa, `\return <CANT>`)
+	assert.NoError(t, err)
+	assert.Nil(t, detected)
+
+	_, _, err = langdetect.Detect(ctx, rt, oa, `\error boom`)
+	assert.EqualError(t, err, "error running llm language detector: error calling LLM service: boom")
+}
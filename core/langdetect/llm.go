@@ -0,0 +1,59 @@
+package langdetect
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/mailroom/core/ai/prompts"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// detects language by asking the configured LLM
+func detectLLM(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, config *models.LanguageDetection, text string) (*models.DetectedLanguage, error) {
+	llm := oa.LLMByUUID(config.LLMUUID)
+	if llm == nil {
+		return nil, nil // LLM has been deleted
+	}
+
+	llmSvc, err := llm.AsService(http.DefaultClient)
+	if err != nil {
+		return nil, fmt.Errorf("error creating LLM service: %w", err)
+	}
+
+	start := time.Now()
+
+	resp, err := llmSvc.Response(ctx, prompts.Render("detect_language", nil), text, 20)
+	if err != nil {
+		return nil, fmt.Errorf("error calling LLM service: %w", err)
+	}
+
+	llm.RecordCall(rt, time.Since(start), resp.TokensUsed)
+
+	return parseLLMOutput(resp.Output), nil
+}
+
+// parses output like "eng 0.95", returning nil if it isn't valid
+func parseLLMOutput(output string) *models.DetectedLanguage {
+	parts := strings.Fields(output)
+	if len(parts) != 2 {
+		return nil
+	}
+
+	lang, err := i18n.ParseLanguage(strings.ToLower(parts[0]))
+	if err != nil {
+		return nil
+	}
+
+	confidence, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || confidence < 0 || confidence > 1 {
+		return nil
+	}
+
+	return &models.DetectedLanguage{Language: lang, Confidence: confidence}
+}
@@ -0,0 +1,97 @@
+package langdetect
+
+import (
+	"context"
+	"embed"
+	"math"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// texts are too short to detect if they have fewer trigrams than this, which is roughly 4 or 5 words. Note that the
+// model's confidence saturates quickly so even above this it's only good enough to record and not to act on.
+const minTrigrams = 20
+
+//go:embed samples/*.txt
+var samples embed.FS
+
+// a language's trigram frequencies
+type profile struct {
+	language i18n.Language
+	counts   map[string]int
+	total    int
+}
+
+var profiles []*profile
+var vocabSize int
+
+func init() {
+	files, _ := samples.ReadDir("samples")
+	vocab := make(map[string]bool)
+
+	for _, f := range files {
+		sample, _ := samples.ReadFile(path.Join("samples", f.Name()))
+
+		p := &profile{language: i18n.Language(strings.TrimSuffix(f.Name(), ".txt")), counts: make(map[string]int)}
+		for _, t := range trigrams(string(sample)) {
+			p.counts[t]++
+			p.total++
+			vocab[t] = true
+		}
+		profiles = append(profiles, p)
+	}
+
+	vocabSize = len(vocab)
+}
+
+// detects language using a local character trigram model built from sample texts. The model is small so this detector
+// is registered as unreliable.
+func detectNGram(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, config *models.LanguageDetection, text string) (*models.DetectedLanguage, error) {
+	return detectTrigrams(text), nil
+}
+
+func detectTrigrams(text string) *models.DetectedLanguage {
+	tris := trigrams(text)
+	if len(tris) < minTrigrams {
+		return nil
+	}
+
+	// calculate the log likelihood of the text under each language's model using add-one smoothing
+	scores := make([]float64, len(profiles))
+	best := 0
+	for i, p := range profiles {
+		for _, t := range tris {
+			scores[i] += math.Log(float64(p.counts[t]+1) / float64(p.total+vocabSize))
+		}
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	// confidence is the probability of the best language relative to the others
+	sum := 0.0
+	for _, s := range scores {
+		sum += math.Exp(s - scores[best])
+	}
+
+	return &models.DetectedLanguage{Language: profiles[best].language, Confidence: 1 / sum}
+}
+
+// extracts the character trigrams of each word in the given text, with words padded by spaces
+func trigrams(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' })
+	tris := make([]string, 0, len(text))
+
+	for _, w := range words {
+		runes := []rune(" " + w + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			tris = append(tris, string(runes[i:i+3]))
+		}
+	}
+	return tris
+}
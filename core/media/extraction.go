@@ -0,0 +1,93 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/core/goflow"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// org config keys for the LLMs used to extract text from attachments
+const (
+	ConfigTranscriptionLLM = "attachment_transcription_llm"
+	ConfigOCRLLM           = "attachment_ocr_llm"
+)
+
+// TextExtractor extracts text from media, e.g. transcribing audio or OCR of images
+type TextExtractor interface {
+	ExtractText(ctx context.Context, contentType, url string) (string, error)
+}
+
+var registeredExtractors = map[string]func(*models.LLM, *http.Client, *httpx.AccessConfig) (TextExtractor, error){}
+
+// RegisterTextExtractor registers a text extractor factory for the given LLM type. Extractors which fetch media
+// themselves must do so using the given access config.
+func RegisterTextExtractor(llmType string, fn func(*models.LLM, *http.Client, *httpx.AccessConfig) (TextExtractor, error)) {
+	registeredExtractors[llmType] = fn
+}
+
+func init() {
+	RegisterTextExtractor("test", func(*models.LLM, *http.Client, *httpx.AccessConfig) (TextExtractor, error) {
+		return &testExtractor{}, nil
+	})
+}
+
+// extracts text from attachments of a media type using the LLM configured on the org
+type extractor struct {
+	name      string
+	mediaType string
+	configKey string
+}
+
+func (e *extractor) Name() string { return e.name }
+
+func (e *extractor) Process(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, att utils.Attachment) (*Result, error) {
+	contentType := att.ContentType()
+	if contentType != e.mediaType && !strings.HasPrefix(contentType, e.mediaType+"/") {
+		return nil, nil
+	}
+
+	llmUUID := oa.Org().ConfigValue(e.configKey, "")
+	if llmUUID == "" {
+		return nil, nil
+	}
+
+	llm := oa.LLMByUUID(assets.LLMUUID(llmUUID))
+	if llm == nil {
+		return nil, nil // LLM has been deleted
+	}
+
+	fn := registeredExtractors[llm.Type()]
+	if fn == nil {
+		return nil, fmt.Errorf("LLM type '%s' doesn't support %s", llm.Type(), e.name)
+	}
+
+	client, _, access := goflow.HTTP(rt.Config)
+
+	ex, err := fn(llm, client, access)
+	if err != nil {
+		return nil, fmt.Errorf("error creating text extractor: %w", err)
+	}
+
+	text, err := ex.ExtractText(ctx, contentType, att.URL())
+	if err != nil {
+		return nil, fmt.Errorf("error extracting text: %w", err)
+	}
+
+	return &Result{Text: strings.TrimSpace(text)}, nil
+}
+
+// a local stub extractor for testing
+type testExtractor struct{}
+
+func (e *testExtractor) ExtractText(ctx context.Context, contentType, url string) (string, error) {
+	return fmt.Sprintf("text from %s", path.Base(url)), nil
+}
@@ -0,0 +1,75 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// Result is the result of processing an attachment
+type Result struct {
+	Text     string // any text extracted from the attachment
+	Rejected bool   // whether the attachment should be hidden from flows
+}
+
+// Processor processes a fetched incoming attachment. Returns nil if the processor doesn't apply to the attachment.
+type Processor interface {
+	Name() string
+	Process(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, att utils.Attachment) (*Result, error)
+}
+
+var processors []Processor
+
+func init() {
+	// validation goes first so that we don't extract text from attachments that will be rejected
+	RegisterProcessor(&validator{})
+	RegisterProcessor(&extractor{name: "transcription", mediaType: "audio", configKey: ConfigTranscriptionLLM})
+	RegisterProcessor(&extractor{name: "ocr", mediaType: "image", configKey: ConfigOCRLLM})
+}
+
+// RegisterProcessor registers an attachment processor. Processors are run in the order they're registered and once
+// one rejects an attachment, it isn't passed to the remaining processors.
+func RegisterProcessor(p Processor) {
+	processors = append(processors, p)
+}
+
+// Process runs the registered processors against the given attachments, returning the attachments with any rejected
+// ones marked as unavailable, and the text extracted from them
+func Process(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, atts []utils.Attachment) ([]utils.Attachment, string, error) {
+	processed := make([]utils.Attachment, len(atts))
+	texts := make([]string, 0, len(atts))
+
+	for i, att := range atts {
+		processed[i] = att
+
+		if att.ContentType() == utils.UnavailableType {
+			continue
+		}
+
+		for _, p := range processors {
+			res, err := p.Process(ctx, rt, oa, att)
+			if err != nil {
+				// processing is best effort, so don't let a failing service stop the message being handled
+				slog.Error("error processing attachment", "processor", p.Name(), "attachment", att, "error", err)
+				continue
+			}
+			if res == nil {
+				continue
+			}
+			if res.Rejected {
+				processed[i] = utils.Attachment(fmt.Sprintf("%s:%s", utils.UnavailableType, att.URL()))
+				break
+			}
+			if res.Text != "" {
+				texts = append(texts, res.Text)
+			}
+		}
+	}
+
+	return processed, strings.Join(texts, "\n"), nil
+}
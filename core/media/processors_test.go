@@ -0,0 +1,58 @@
+package media_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/core/media"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcess(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	atts := []utils.Attachment{
+		"image/jpeg:http://example.com/photo.jpg",
+		"audio/mp4:http://example.com/voice.m4a",
+		"video/mp4:http://example.com/clip.mp4",
+		"unavailable:http://example.com/gone.jpg",
+	}
+
+	// no config means attachments are left as is and no text is extracted
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	processed, text, err := media.Process(ctx, rt, oa, atts)
+	assert.NoError(t, err)
+	assert.Equal(t, atts, processed)
+	assert.Equal(t, "", text)
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"attachment_types": ["image", "audio/mp4"], "attachment_max_size": 1000, "attachment_transcription_llm": "e5d8900a-ef54-4d2a-8214-ff7d3e903502", "attachment_ocr_llm": "e5d8900a-ef54-4d2a-8214-ff7d3e903502"}' WHERE id = $1`, testdata.Org1.ID)
+
+	oa, err = models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]*httpx.MockResponse{
+		"http://example.com/photo.jpg": {httpx.NewMockResponse(200, nil, bytes.Repeat([]byte{'x'}, 2000))},
+		"http://example.com/voice.m4a": {httpx.NewMockResponse(200, nil, bytes.Repeat([]byte{'x'}, 500))},
+	}))
+
+	processed, text, err = media.Process(ctx, rt, oa, atts)
+	assert.NoError(t, err)
+	assert.Equal(t, []utils.Attachment{
+		"unavailable:http://example.com/photo.jpg", // too big
+		"audio/mp4:http://example.com/voice.m4a",
+		"unavailable:http://example.com/clip.mp4", // type not allowed
+		"unavailable:http://example.com/gone.jpg",
+	}, processed)
+	assert.Equal(t, "text from voice.m4a", text)
+}
@@ -0,0 +1,80 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/core/goflow"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// org config keys used for attachment validation
+const (
+	ConfigAttachmentMaxSize = "attachment_max_size" // in bytes
+	ConfigAttachmentTypes   = "attachment_types"    // list of allowed types, e.g. ["image", "audio/mp4"]
+)
+
+// rejects attachments whose media type isn't allowed or which are larger than allowed
+type validator struct{}
+
+func (v *validator) Name() string { return "validation" }
+
+func (v *validator) Process(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, att utils.Attachment) (*Result, error) {
+	if types := allowedTypes(oa); len(types) > 0 && !isAllowedType(att.ContentType(), types) {
+		return &Result{Rejected: true}, nil
+	}
+
+	if maxSize := oa.Org().ConfigInt(ConfigAttachmentMaxSize, 0); maxSize > 0 {
+		size, err := contentLength(ctx, rt, att.URL())
+		if err != nil {
+			return nil, err
+		}
+		if size > maxSize {
+			return &Result{Rejected: true}, nil
+		}
+	}
+
+	return &Result{}, nil
+}
+
+func allowedTypes(oa *models.OrgAssets) []string {
+	types := oa.Org().ConfigStrings(ConfigAttachmentTypes)
+	for i := range types {
+		types[i] = strings.ToLower(types[i])
+	}
+	return types
+}
+
+// checks a content type against allowed types, which can be a full media type or just the top level type
+func isAllowedType(contentType string, allowed []string) bool {
+	topLevel, _, _ := strings.Cut(contentType, "/")
+
+	for _, a := range allowed {
+		if a == contentType || a == topLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// makes a HEAD request to get the size of an attachment, returning -1 if it can't be determined
+func contentLength(ctx context.Context, rt *runtime.Runtime, url string) (int, error) {
+	client, _, access := goflow.HTTP(rt.Config)
+
+	req, err := httpx.NewRequest(ctx, http.MethodHead, url, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request for attachment: %w", err)
+	}
+
+	trace, err := httpx.DoTrace(client, req, nil, access, -1)
+	if err != nil {
+		return 0, fmt.Errorf("error requesting attachment: %w", err)
+	}
+
+	return int(trace.Response.ContentLength), nil
+}
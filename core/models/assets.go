@@ -44,6 +44,7 @@ const (
 	RefreshTopics      = Refresh(1 << 15)
 	RefreshTriggers    = Refresh(1 << 16)
 	RefreshUsers       = Refresh(1 << 17)
+	RefreshCannedResps = Refresh(1 << 18)
 )
 
 // OrgAssets is our top level cache of all things contained in an org. It is used to build
@@ -72,6 +73,7 @@ type OrgAssets struct {
 	campaigns             []*Campaign
 	campaignEventsByField map[FieldID][]*CampaignEvent
 	campaignEventsByID    map[CampaignEventID]*CampaignEvent
+	campaignEventsByEvent map[CampaignEventID][]*CampaignEvent
 	campaignsByGroup      map[GroupID][]*Campaign
 
 	fields       []assets.Field // excludes proxy fields
@@ -110,6 +112,9 @@ type OrgAssets struct {
 	users        []assets.User
 	usersByID    map[UserID]*User
 	usersByEmail map[string]*User
+
+	cannedResponses     []*CannedResponse
+	cannedResponsesByID map[CannedResponseID]*CannedResponse
 }
 
 var ErrNotFound = errors.New("not found")
@@ -318,11 +323,16 @@ func NewOrgAssets(ctx context.Context, rt *runtime.Runtime, orgID OrgID, prev *O
 		}
 		oa.campaignEventsByField = make(map[FieldID][]*CampaignEvent)
 		oa.campaignEventsByID = make(map[CampaignEventID]*CampaignEvent)
+		oa.campaignEventsByEvent = make(map[CampaignEventID][]*CampaignEvent)
 		oa.campaignsByGroup = make(map[GroupID][]*Campaign)
 		for _, c := range oa.campaigns {
 			oa.campaignsByGroup[c.GroupID()] = append(oa.campaignsByGroup[c.GroupID()], c)
 			for _, e := range c.Events() {
-				oa.campaignEventsByField[e.RelativeToID] = append(oa.campaignEventsByField[e.RelativeToID], e)
+				if e.IsRelativeToEvent() {
+					oa.campaignEventsByEvent[e.RelativeToEventID] = append(oa.campaignEventsByEvent[e.RelativeToEventID], e)
+				} else {
+					oa.campaignEventsByField[e.RelativeToID] = append(oa.campaignEventsByField[e.RelativeToID], e)
+				}
 				oa.campaignEventsByID[e.ID] = e
 			}
 		}
@@ -330,6 +340,7 @@ func NewOrgAssets(ctx context.Context, rt *runtime.Runtime, orgID OrgID, prev *O
 		oa.campaigns = prev.campaigns
 		oa.campaignEventsByField = prev.campaignEventsByField
 		oa.campaignEventsByID = prev.campaignEventsByID
+		oa.campaignEventsByEvent = prev.campaignEventsByEvent
 		oa.campaignsByGroup = prev.campaignsByGroup
 	}
 
@@ -421,6 +432,20 @@ func NewOrgAssets(ctx context.Context, rt *runtime.Runtime, orgID OrgID, prev *O
 		oa.usersByEmail = prev.usersByEmail
 	}
 
+	if prev == nil || refresh&RefreshCannedResps > 0 {
+		oa.cannedResponses, err = loadAssetType(ctx, db, orgID, "canned responses", loadCannedResponses)
+		if err != nil {
+			return nil, fmt.Errorf("error loading canned responses for org %d: %w", orgID, err)
+		}
+		oa.cannedResponsesByID = make(map[CannedResponseID]*CannedResponse, len(oa.cannedResponses))
+		for _, r := range oa.cannedResponses {
+			oa.cannedResponsesByID[r.ID()] = r
+		}
+	} else {
+		oa.cannedResponses = prev.cannedResponses
+		oa.cannedResponsesByID = prev.cannedResponsesByID
+	}
+
 	// intialize our session assets
 	oa.sessionAssets, err = engine.NewSessionAssets(oa.Env(), oa, goflow.MigrationConfig(rt.Config))
 	if err != nil {
@@ -626,6 +651,15 @@ func (a *OrgAssets) Campaigns() []*Campaign {
 	return a.campaigns
 }
 
+func (a *OrgAssets) CampaignByID(campaignID CampaignID) *Campaign {
+	for _, c := range a.campaigns {
+		if c.ID() == campaignID {
+			return c
+		}
+	}
+	return nil
+}
+
 func (a *OrgAssets) CampaignByGroupID(groupID GroupID) []*Campaign {
 	return a.campaignsByGroup[groupID]
 }
@@ -634,6 +668,10 @@ func (a *OrgAssets) CampaignEventsByFieldID(fieldID FieldID) []*CampaignEvent {
 	return a.campaignEventsByField[fieldID]
 }
 
+func (a *OrgAssets) CampaignEventsRelativeToEventID(eventID CampaignEventID) []*CampaignEvent {
+	return a.campaignEventsByEvent[eventID]
+}
+
 func (a *OrgAssets) CampaignEventByID(eventID CampaignEventID) *CampaignEvent {
 	return a.campaignEventsByID[eventID]
 }
@@ -666,6 +704,15 @@ func (a *OrgAssets) LLMByID(id LLMID) *LLM {
 	return a.llmsByID[id]
 }
 
+func (a *OrgAssets) LLMByUUID(uuid assets.LLMUUID) *LLM {
+	for _, l := range a.llmsByID {
+		if l.UUID() == uuid {
+			return l
+		}
+	}
+	return nil
+}
+
 func (a *OrgAssets) Triggers() []*Trigger {
 	return a.triggers
 }
@@ -739,6 +786,14 @@ func (a *OrgAssets) UserByEmail(email string) *User {
 	return a.usersByEmail[email]
 }
 
+func (a *OrgAssets) CannedResponses() []*CannedResponse {
+	return a.cannedResponses
+}
+
+func (a *OrgAssets) CannedResponseByID(id CannedResponseID) *CannedResponse {
+	return a.cannedResponsesByID[id]
+}
+
 func loadAssetType[A any](ctx context.Context, db *sql.DB, orgID OrgID, name string, f func(ctx context.Context, db *sql.DB, orgID OrgID) ([]A, error)) ([]A, error) {
 	start := time.Now()
 
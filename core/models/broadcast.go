@@ -347,12 +347,7 @@ func (b *Broadcast) createMessage(rt *runtime.Runtime, oa *OrgAssets, c *Contact
 
 	var expressionsContext *types.XObject
 	if b.Expressions {
-		expressionsContext = types.NewXObject(map[string]types.XValue{
-			"contact": flows.Context(oa.Env(), contact),
-			"fields":  flows.Context(oa.Env(), contact.Fields()),
-			"globals": flows.Context(oa.Env(), oa.SessionAssets().Globals()),
-			"urns":    flows.ContextFunc(oa.Env(), contact.URNs().MapContext),
-		})
+		expressionsContext = contactExpressionsContext(oa, contact)
 	}
 
 	// don't create a message if we have no content
@@ -370,3 +365,13 @@ func (b *Broadcast) createMessage(rt *runtime.Runtime, oa *OrgAssets, c *Contact
 
 	return msg, nil
 }
+
+// builds the context used to evaluate expressions in messages sent outside of a flow
+func contactExpressionsContext(oa *OrgAssets, contact *flows.Contact) *types.XObject {
+	return types.NewXObject(map[string]types.XValue{
+		"contact": flows.Context(oa.Env(), contact),
+		"fields":  flows.Context(oa.Env(), contact.Fields()),
+		"globals": flows.Context(oa.Env(), oa.SessionAssets().Globals()),
+		"urns":    flows.ContextFunc(oa.Env(), contact.URNs().MapContext),
+	})
+}
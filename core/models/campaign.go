@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/dbutil"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/flows"
@@ -31,6 +32,9 @@ const (
 	// LastSeenOnKey is key of last seen on system field
 	LastSeenOnKey = "last_seen_on"
 
+	// GroupJoinedOnKey is key of the system field for when a contact joined a campaign's group
+	GroupJoinedOnKey = "group_joined_on"
+
 	// NilDeliveryHour is our constant for not having a set delivery hour
 	NilDeliveryHour = -1
 
@@ -76,11 +80,12 @@ type CampaignEvent struct {
 	FireVersion int                 `json:"fire_version"`
 	StartMode   CampaignEventMode   `json:"start_mode"`
 
-	RelativeToID  FieldID           `json:"relative_to_id"`
-	RelativeToKey string            `json:"relative_to_key"`
-	Offset        int               `json:"offset"`
-	Unit          CampaignEventUnit `json:"unit"`
-	DeliveryHour  int               `json:"delivery_hour"`
+	RelativeToID      FieldID           `json:"relative_to_id"`
+	RelativeToKey     string            `json:"relative_to_key"`
+	RelativeToEventID CampaignEventID   `json:"relative_to_event_id"` // set if event is anchored on the firing of another event
+	Offset            int               `json:"offset"`
+	Unit              CampaignEventUnit `json:"unit"`
+	DeliveryHour      int               `json:"delivery_hour"`
 
 	FlowID       FlowID                      `json:"flow_id"`
 	Translations flows.BroadcastTranslations `json:"translations"`
@@ -99,10 +104,17 @@ func (e *CampaignEvent) QualifiesByGroup(contact *flows.Contact) bool {
 	return false
 }
 
+// IsRelativeToEvent returns whether this event is anchored on the firing of another event rather than a field
+func (e *CampaignEvent) IsRelativeToEvent() bool { return e.RelativeToEventID != 0 }
+
 // QualifiesByField returns whether the passed in contact qualifies for this event by group membership
 func (e *CampaignEvent) QualifiesByField(contact *flows.Contact) bool {
+	if e.IsRelativeToEvent() {
+		return true // contact may have a fire from the anchor event having fired
+	}
+
 	switch e.RelativeToKey {
-	case CreatedOnKey:
+	case CreatedOnKey, GroupJoinedOnKey:
 		return true
 	case LastSeenOnKey:
 		return contact.LastSeenOn() != nil
@@ -112,10 +124,11 @@ func (e *CampaignEvent) QualifiesByField(contact *flows.Contact) bool {
 	}
 }
 
-// ScheduleForContact calculates the next fire ( if any) for the passed in contact
+// ScheduleForContact calculates the next fire ( if any) for the passed in contact. For events anchored on the campaign's
+// group, this should only be called when the contact has just been added to that group.
 func (e *CampaignEvent) ScheduleForContact(tz *time.Location, now time.Time, contact *flows.Contact) (*time.Time, error) {
-	// we aren't part of the group, move on
-	if !e.QualifiesByGroup(contact) {
+	// we aren't part of the group or we're anchored on another event which is scheduled when that event fires, move on
+	if !e.QualifiesByGroup(contact) || e.IsRelativeToEvent() {
 		return nil, nil
 	}
 
@@ -124,6 +137,8 @@ func (e *CampaignEvent) ScheduleForContact(tz *time.Location, now time.Time, con
 	switch e.RelativeToKey {
 	case CreatedOnKey:
 		start = contact.CreatedOn()
+	case GroupJoinedOnKey:
+		start = now
 	case LastSeenOnKey:
 		value := contact.LastSeenOn()
 		if value == nil {
@@ -230,10 +245,10 @@ SELECT ROW_TO_JSON(r) FROM (SELECT
     c.name,
     c.group_id,
     (SELECT ARRAY_AGG(evs) FROM (
-        SELECT e.id, e.uuid, e.event_type, e.status, e.fire_version, e.start_mode, e.relative_to_id, f.key AS relative_to_key, e.offset, e.unit, e.delivery_hour, e.flow_id, e.translations, e.base_language
+        SELECT e.id, e.uuid, e.event_type, e.status, e.fire_version, e.start_mode, e.relative_to_id, f.key AS relative_to_key, e.relative_to_event_id, e.offset, e.unit, e.delivery_hour, e.flow_id, e.translations, e.base_language
           FROM campaigns_campaignevent e
-          JOIN contacts_contactfield f ON f.id = e.relative_to_id
-         WHERE e.campaign_id = c.id AND e.is_active = TRUE AND f.is_active = TRUE
+     LEFT JOIN contacts_contactfield f ON f.id = e.relative_to_id
+         WHERE e.campaign_id = c.id AND e.is_active = TRUE AND (e.relative_to_event_id IS NOT NULL OR f.is_active = TRUE)
       ORDER BY e.relative_to_id, e.offset
     ) evs) AS events
  FROM campaigns_campaign c
@@ -313,38 +328,111 @@ func ScheduleCampaignEvent(ctx context.Context, rt *runtime.Runtime, oa *OrgAsse
 		return fmt.Errorf("can't find campaign event with id %d", eventID)
 	}
 
-	field := oa.FieldByKey(ce.RelativeToKey)
-	if field == nil {
-		return fmt.Errorf("can't find field with key %s", ce.RelativeToKey)
+	// events anchored on other events only get fires when those events fire
+	if !ce.IsRelativeToEvent() {
+		field := oa.FieldByKey(ce.RelativeToKey)
+		if field == nil {
+			return fmt.Errorf("can't find field with key %s", ce.RelativeToKey)
+		}
+
+		fas, _, err := calculateCampaignEventFires(ctx, rt.DB, oa, ce, field, time.Now())
+		if err != nil {
+			return fmt.Errorf("unable to calculate fires for event %d: %w", ce.ID, err)
+		}
+
+		// add all our new event fires
+		if err := InsertContactFires(ctx, rt.DB, fas); err != nil {
+			return fmt.Errorf("error inserting new contact fires for event #%d: %w", ce.ID, err)
+		}
+	}
+
+	ce.Status = CampaignEventStatusReady
+	if _, err := rt.DB.ExecContext(ctx, `UPDATE campaigns_campaignevent SET status = 'R' WHERE id = $1`, ce.ID); err != nil {
+		return fmt.Errorf("error updating status for event #%d: %w", ce.ID, err)
 	}
 
-	eligible, err := campaignEventEligibleContacts(ctx, rt.DB, ce.campaign.GroupID(), field)
+	return nil
+}
+
+// CampaignEventPreview is a preview of the fires that an event would create if it were scheduled
+type CampaignEventPreview struct {
+	Fires   []time.Time // when each fire would happen
+	Skipped int         // number of eligible contacts whose fire would already be in the past
+}
+
+// PreviewCampaignEvent calculates the fires that an event on the given campaign with the given offset, unit and delivery
+// hour would create, without creating anything.
+func PreviewCampaignEvent(ctx context.Context, rt *runtime.Runtime, oa *OrgAssets, campaign *Campaign, relativeTo *Field, offset int, unit CampaignEventUnit, deliveryHour int) (*CampaignEventPreview, error) {
+	ce := &CampaignEvent{
+		RelativeToID:  relativeTo.ID(),
+		RelativeToKey: relativeTo.Key(),
+		Offset:        offset,
+		Unit:          unit,
+		DeliveryHour:  deliveryHour,
+		campaign:      campaign,
+	}
+
+	fas, skipped, err := calculateCampaignEventFires(ctx, rt.DB, oa, ce, relativeTo, dates.Now())
 	if err != nil {
-		return fmt.Errorf("unable to calculate eligible contacts for event %d: %w", ce.ID, err)
+		return nil, fmt.Errorf("unable to calculate fires for campaign %d: %w", campaign.ID(), err)
+	}
+
+	preview := &CampaignEventPreview{Fires: make([]time.Time, len(fas)), Skipped: skipped}
+	for i, fa := range fas {
+		preview.Fires[i] = fa.FireOn
+	}
+
+	return preview, nil
+}
+
+// calculates the fires for the given event for all its eligible contacts, also returning the number of eligible contacts
+// that were skipped because their fire would be in the past
+func calculateCampaignEventFires(ctx context.Context, db *sqlx.DB, oa *OrgAssets, ce *CampaignEvent, field *Field, now time.Time) ([]*ContactFire, int, error) {
+	eligible, err := campaignEventEligibleContacts(ctx, db, ce.campaign.GroupID(), field)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	fas := make([]*ContactFire, 0, len(eligible))
 	tz := oa.Env().Timezone()
+	skipped := 0
 
 	for _, el := range eligible {
 		start := *el.RelToValue
 
 		// calculate next fire for this contact if any
-		if scheduled := ce.ScheduleForTime(tz, time.Now(), start); scheduled != nil {
+		if scheduled := ce.ScheduleForTime(tz, now, start); scheduled != nil {
 			fas = append(fas, NewContactFireForCampaign(oa.OrgID(), el.ContactID, ce, *scheduled))
+		} else {
+			skipped++
 		}
 	}
 
-	// add all our new event fires
-	if err := InsertContactFires(ctx, rt.DB, fas); err != nil {
-		return fmt.Errorf("error inserting new contact fires for event #%d: %w", ce.ID, err)
+	return fas, skipped, nil
+}
+
+// AddCampaignEventsForEventFire creates fires for any events anchored on the given event for the given contacts which
+// have just had that event fire.
+func AddCampaignEventsForEventFire(ctx context.Context, db DBorTx, oa *OrgAssets, ce *CampaignEvent, contactIDs []ContactID, firedOn time.Time) error {
+	relEvents := oa.CampaignEventsRelativeToEventID(ce.ID)
+	if len(relEvents) == 0 {
+		return nil
 	}
 
-	ce.Status = CampaignEventStatusReady
-	if _, err := rt.DB.ExecContext(ctx, `UPDATE campaigns_campaignevent SET status = 'R' WHERE id = $1`, ce.ID); err != nil {
-		return fmt.Errorf("error updating status for event #%d: %w", ce.ID, err)
+	fas := make([]*ContactFire, 0, len(relEvents)*len(contactIDs))
+	tz := oa.Env().Timezone()
+
+	for _, re := range relEvents {
+		if scheduled := re.ScheduleForTime(tz, firedOn, firedOn); scheduled != nil {
+			for _, cid := range contactIDs {
+				fas = append(fas, NewContactFireForCampaign(oa.OrgID(), cid, re, *scheduled))
+			}
+		}
 	}
 
+	if err := InsertContactFires(ctx, db, fas); err != nil {
+		return fmt.Errorf("error inserting contact fires for events relative to event #%d: %w", ce.ID, err)
+	}
 	return nil
 }
 
@@ -376,6 +464,8 @@ func campaignEventEligibleContacts(ctx context.Context, db *sqlx.DB, groupID Gro
 	var params []any
 
 	switch field.Key() {
+	case GroupJoinedOnKey:
+		return nil, nil // we don't know when existing members joined the group
 	case CreatedOnKey:
 		query = sqlEligibleContactsForCreatedOn
 		params = []any{groupID}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+)
+
+type CannedResponseID int
+type CannedResponseUUID uuids.UUID
+
+// CannedResponse is a saved reply (a ticket shortcut) which agents can send to contacts with tickets
+type CannedResponse struct {
+	ID_           CannedResponseID         `json:"id"`
+	UUID_         CannedResponseUUID       `json:"uuid"`
+	Name_         string                   `json:"name"`
+	Text_         string                   `json:"text"`
+	Translations_ map[i18n.Language]string `json:"translations"`
+}
+
+// ID returns the ID for this canned response
+func (r *CannedResponse) ID() CannedResponseID { return r.ID_ }
+
+// UUID returns the UUID for this canned response
+func (r *CannedResponse) UUID() CannedResponseUUID { return r.UUID_ }
+
+// Name returns the name for this canned response
+func (r *CannedResponse) Name() string { return r.Name_ }
+
+// Text returns the text of this canned response in the org's default language
+func (r *CannedResponse) Text() string { return r.Text_ }
+
+// Content picks the translation of this canned response for the given contact, and returns it with the context
+// needed to evaluate any expressions it contains
+func (r *CannedResponse) Content(oa *OrgAssets, contact *flows.Contact) (*flows.MsgContent, i18n.Locale, *types.XObject) {
+	baseLanguage := oa.Env().DefaultLanguage()
+
+	translations := flows.BroadcastTranslations{baseLanguage: {Text: r.Text_}}
+	for lang, text := range r.Translations_ {
+		if lang != baseLanguage && text != "" {
+			translations[lang] = &flows.MsgContent{Text: text}
+		}
+	}
+
+	content, locale := translations.ForContact(oa.Env(), contact, baseLanguage)
+
+	return content, locale, contactExpressionsContext(oa, contact)
+}
+
+// loads the canned responses for the passed in org
+func loadCannedResponses(ctx context.Context, db *sql.DB, orgID OrgID) ([]*CannedResponse, error) {
+	rows, err := db.QueryContext(ctx, sqlSelectCannedResponsesByOrg, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying canned responses for org: %d: %w", orgID, err)
+	}
+
+	return ScanJSONRows(rows, func() *CannedResponse { return &CannedResponse{} })
+}
+
+const sqlSelectCannedResponsesByOrg = `
+SELECT ROW_TO_JSON(r) FROM (
+      SELECT id, uuid, name, text, COALESCE(translations, '{}') AS translations
+        FROM tickets_shortcut
+       WHERE org_id = $1 AND is_active = TRUE
+    ORDER BY name ASC
+) r;`
@@ -31,6 +31,8 @@ const (
 	ChannelConfigCallbackDomain     = "callback_domain"
 	ChannelConfigMaxConcurrentCalls = "max_concurrent_calls"
 	ChannelConfigFCMID              = "FCM_ID"
+	ChannelConfigDedupWindow        = "dedup_window"
+	ChannelConfigDedupBy            = "dedup_by"
 )
 
 // Channel is the mailroom struct that represents channels
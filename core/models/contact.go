@@ -1452,6 +1452,7 @@ func LockContacts(ctx context.Context, rt *runtime.Runtime, orgID OrgID, ids []C
 
 		// no error but we didn't get the lock
 		if lock == "" {
+			rt.Stats.RecordContactLockContention()
 			skipped = append(skipped, contactID)
 			continue
 		}
@@ -1461,9 +1462,13 @@ func LockContacts(ctx context.Context, rt *runtime.Runtime, orgID OrgID, ids []C
 		// if we error we want to release all locks on way out
 		defer func() {
 			if !success {
-				locker.Release(rt.RP, lock)
+				releaseContactLock(rt, orgID, contactID, lock)
 			}
 		}()
+
+		if err := recordContactLock(rt, orgID, contactID, lock); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	success = true
@@ -1473,17 +1478,31 @@ func LockContacts(ctx context.Context, rt *runtime.Runtime, orgID OrgID, ids []C
 // UnlockContacts unlocks the given contacts using the given lock values
 func UnlockContacts(rt *runtime.Runtime, orgID OrgID, locks map[ContactID]string) error {
 	for contactID, lock := range locks {
-		locker := getContactLocker(orgID, contactID)
-
-		err := locker.Release(rt.RP, lock)
-		if err != nil {
+		if err := releaseContactLock(rt, orgID, contactID, lock); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// releases the given lock on a contact and removes our record of it
+func releaseContactLock(rt *runtime.Runtime, orgID OrgID, contactID ContactID, lock string) error {
+	if err := getContactLocker(orgID, contactID).Release(rt.RP, lock); err != nil {
+		return err
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	return forgetContactLock(rc, orgID, contactID, lock)
+}
+
 // returns the locker for a particular contact
 func getContactLocker(orgID OrgID, contactID ContactID) *redisx.Locker {
-	return redisx.NewLocker(fmt.Sprintf("lock:c:%d:%d", orgID, contactID), time.Minute*5)
+	return redisx.NewLocker(contactLockKey(orgID, contactID), time.Minute*5)
+}
+
+// returns the redis key of the lock for a particular contact
+func contactLockKey(orgID OrgID, contactID ContactID) string {
+	return fmt.Sprintf("lock:c:%d:%d", orgID, contactID)
 }
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/null/v3"
+)
+
+// ContactBulkModifyID is the type for contact bulk modify IDs
+type ContactBulkModifyID int
+
+// ContactBulkModifyStatus is the status of a bulk modify
+type ContactBulkModifyStatus string
+
+// bulk modify status constants
+const (
+	ContactBulkModifyStatusPending    ContactBulkModifyStatus = "P"
+	ContactBulkModifyStatusProcessing ContactBulkModifyStatus = "O"
+	ContactBulkModifyStatusComplete   ContactBulkModifyStatus = "C"
+	ContactBulkModifyStatusFailed     ContactBulkModifyStatus = "F"
+)
+
+// ContactBulkModify is the applying of modifiers to all the contacts in a group or matching a query, done in batches
+type ContactBulkModify struct {
+	ID          ContactBulkModifyID     `db:"id"`
+	OrgID       OrgID                   `db:"org_id"`
+	Status      ContactBulkModifyStatus `db:"status"`
+	GroupID     GroupID                 `db:"group_id"`
+	Query       null.String             `db:"query"`
+	Modifiers   json.RawMessage         `db:"modifiers"`
+	CreatedByID UserID                  `db:"created_by_id"`
+	CreatedOn   time.Time               `db:"created_on"`
+	FinishedOn  *time.Time              `db:"finished_on"`
+
+	// progress written as batches are processed
+	NumContacts int `db:"num_contacts"`
+	NumModified int `db:"num_modified"`
+	NumSkipped  int `db:"num_skipped"`
+	NumErrored  int `db:"num_errored"`
+}
+
+// NewContactBulkModify creates a new bulk modify of the contacts in the given group or matching the given query
+func NewContactBulkModify(orgID OrgID, userID UserID, groupID GroupID, query string, mods []json.RawMessage) *ContactBulkModify {
+	modsJSON, _ := json.Marshal(mods)
+
+	return &ContactBulkModify{
+		OrgID:       orgID,
+		Status:      ContactBulkModifyStatusPending,
+		GroupID:     groupID,
+		Query:       null.String(query),
+		Modifiers:   modsJSON,
+		CreatedByID: userID,
+		CreatedOn:   dates.Now(),
+	}
+}
+
+// ModifiersJSON returns the modifiers as a list of JSON objects
+func (m *ContactBulkModify) ModifiersJSON() ([]json.RawMessage, error) {
+	var mods []json.RawMessage
+	if err := json.Unmarshal(m.Modifiers, &mods); err != nil {
+		return nil, fmt.Errorf("error unmarshaling modifiers: %w", err)
+	}
+	return mods, nil
+}
+
+const sqlInsertContactBulkModify = `
+INSERT INTO contacts_contactbulkmodify(org_id,  status,             group_id,  query,  modifiers, num_contacts, num_modified, num_skipped, num_errored,  created_by_id,  created_on)
+                               VALUES(:org_id, :status, NULLIF(:group_id, 0), :query, :modifiers,            0,            0,           0,           0, :created_by_id, :created_on)
+RETURNING id`
+
+// InsertContactBulkModify inserts the given bulk modify
+func InsertContactBulkModify(ctx context.Context, db DBorTx, m *ContactBulkModify) error {
+	return BulkQuery(ctx, "inserting contact bulk modify", db, sqlInsertContactBulkModify, []*ContactBulkModify{m})
+}
+
+const sqlSelectContactBulkModify = `
+SELECT id, org_id, status, COALESCE(group_id, 0) AS group_id, query, modifiers, num_contacts, num_modified, num_skipped, num_errored, created_by_id, created_on, finished_on
+  FROM contacts_contactbulkmodify
+ WHERE id = $1`
+
+// LoadContactBulkModify loads a bulk modify by ID
+func LoadContactBulkModify(ctx context.Context, db DBorTx, id ContactBulkModifyID) (*ContactBulkModify, error) {
+	m := &ContactBulkModify{}
+	if err := db.GetContext(ctx, m, sqlSelectContactBulkModify, id); err != nil {
+		return nil, fmt.Errorf("error loading contact bulk modify id=%d: %w", id, err)
+	}
+	return m, nil
+}
+
+// SetProcessing marks this bulk modify as processing with the given number of contacts split into the given number of
+// batches, whose completion is tracked in redis
+func (m *ContactBulkModify) SetProcessing(ctx context.Context, db DBorTx, rc redis.Conn, numContacts, numBatches int) error {
+	m.Status = ContactBulkModifyStatusProcessing
+	m.NumContacts = numContacts
+
+	if _, err := rc.Do("SET", m.batchesRemainingKey(), numBatches, "EX", 60*60*24*7); err != nil {
+		return fmt.Errorf("error setting remaining batches for bulk modify: %w", err)
+	}
+
+	_, err := db.ExecContext(ctx, `UPDATE contacts_contactbulkmodify SET status = $2, num_contacts = $3 WHERE id = $1`, m.ID, m.Status, m.NumContacts)
+	if err != nil {
+		return fmt.Errorf("error marking bulk modify as processing: %w", err)
+	}
+	return nil
+}
+
+// RecordBatch records the results of a processed batch, and marks this bulk modify as finished if that was the last
+// remaining batch. Returns whether this bulk modify is now finished.
+func (m *ContactBulkModify) RecordBatch(ctx context.Context, db DBorTx, rc redis.Conn, numModified, numSkipped, numErrored int) (bool, error) {
+	_, err := db.ExecContext(ctx,
+		`UPDATE contacts_contactbulkmodify SET num_modified = num_modified + $2, num_skipped = num_skipped + $3, num_errored = num_errored + $4 WHERE id = $1`,
+		m.ID, numModified, numSkipped, numErrored,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error recording bulk modify batch: %w", err)
+	}
+
+	m.NumModified += numModified
+	m.NumSkipped += numSkipped
+	m.NumErrored += numErrored
+
+	remaining, err := redis.Int(rc.Do("DECR", m.batchesRemainingKey()))
+	if err != nil {
+		return false, fmt.Errorf("error decrementing remaining batches for bulk modify: %w", err)
+	}
+	if remaining > 0 {
+		return false, nil
+	}
+
+	return true, m.SetFinished(ctx, db, ContactBulkModifyStatusComplete)
+}
+
+// SetFinished marks this bulk modify as finished with the given status, though if any contacts errored then a complete
+// bulk modify is considered failed
+func (m *ContactBulkModify) SetFinished(ctx context.Context, db DBorTx, status ContactBulkModifyStatus) error {
+	now := dates.Now()
+	m.FinishedOn = &now
+
+	err := db.GetContext(ctx, &m.Status,
+		`UPDATE contacts_contactbulkmodify SET status = CASE WHEN $2::varchar = 'C' AND num_errored > 0 THEN 'F' ELSE $2::varchar END, finished_on = $3 WHERE id = $1 RETURNING status`,
+		m.ID, status, m.FinishedOn,
+	)
+	if err != nil {
+		return fmt.Errorf("error marking bulk modify as finished: %w", err)
+	}
+	return nil
+}
+
+func (m *ContactBulkModify) batchesRemainingKey() string {
+	return fmt.Sprintf("contact_bulk_modify_batches_remaining:%d", m.ID)
+}
@@ -0,0 +1,252 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// ContactData is everything we know about a contact, for exporting to them on request
+type ContactData struct {
+	Contact *flows.Contact       `json:"contact"`
+	Msgs    []*ContactDataMsg    `json:"msgs"`
+	Runs    []*ContactDataRun    `json:"runs"`
+	Tickets []*ContactDataTicket `json:"tickets"`
+	Calls   []*ContactDataCall   `json:"calls"`
+}
+
+type ContactDataMsg struct {
+	UUID        flows.MsgUUID  `db:"uuid"        json:"uuid"`
+	Direction   MsgDirection   `db:"direction"   json:"direction"`
+	Text        string         `db:"text"        json:"text"`
+	Attachments pq.StringArray `db:"attachments" json:"attachments"`
+	Status      MsgStatus      `db:"status"      json:"status"`
+	CreatedOn   time.Time      `db:"created_on"  json:"created_on"`
+	SentOn      *time.Time     `db:"sent_on"     json:"sent_on"`
+}
+
+type ContactDataRun struct {
+	UUID      flows.RunUUID   `db:"uuid"       json:"uuid"`
+	FlowUUID  string          `db:"flow_uuid"  json:"flow_uuid"`
+	FlowName  string          `db:"flow_name"  json:"flow_name"`
+	Status    RunStatus       `db:"status"     json:"status"`
+	Results   json.RawMessage `db:"results"    json:"results"`
+	CreatedOn time.Time       `db:"created_on" json:"created_on"`
+	ExitedOn  *time.Time      `db:"exited_on"  json:"exited_on"`
+}
+
+type ContactDataTicket struct {
+	ID       TicketID                 `db:"id"        json:"-"`
+	UUID     flows.TicketUUID         `db:"uuid"      json:"uuid"`
+	Status   TicketStatus             `db:"status"    json:"status"`
+	Topic    string                   `db:"topic"     json:"topic"`
+	OpenedOn time.Time                `db:"opened_on" json:"opened_on"`
+	ClosedOn *time.Time               `db:"closed_on" json:"closed_on"`
+	Notes    []*ContactDataTicketNote `db:"-"         json:"notes"`
+}
+
+type ContactDataTicketNote struct {
+	TicketID  TicketID  `db:"ticket_id"  json:"-"`
+	Note      string    `db:"note"       json:"note"`
+	CreatedOn time.Time `db:"created_on" json:"created_on"`
+}
+
+type ContactDataCall struct {
+	ID        CallID        `db:"id"         json:"id"`
+	Direction CallDirection `db:"direction"  json:"direction"`
+	Status    CallStatus    `db:"status"     json:"status"`
+	Duration  int           `db:"duration"   json:"duration"`
+	CreatedOn time.Time     `db:"created_on" json:"created_on"`
+	EndedOn   *time.Time    `db:"ended_on"   json:"ended_on"`
+}
+
+const sqlSelectContactDataMsgs = `
+  SELECT uuid, direction, text, COALESCE(attachments, '{}') AS attachments, status, created_on, sent_on
+    FROM msgs_msg
+   WHERE contact_id = $1
+ORDER BY created_on, id`
+
+const sqlSelectContactDataRuns = `
+  SELECT r.uuid, f.uuid AS flow_uuid, f.name AS flow_name, r.status, COALESCE(r.results, '{}') AS results, r.created_on, r.exited_on
+    FROM flows_flowrun r
+    JOIN flows_flow f ON f.id = r.flow_id
+   WHERE r.contact_id = $1
+ORDER BY r.created_on, r.id`
+
+const sqlSelectContactDataTickets = `
+  SELECT t.id, t.uuid, t.status, tp.name AS topic, t.opened_on, t.closed_on
+    FROM tickets_ticket t
+    JOIN tickets_topic tp ON tp.id = t.topic_id
+   WHERE t.contact_id = $1
+ORDER BY t.opened_on, t.id`
+
+const sqlSelectContactDataTicketNotes = `
+  SELECT ticket_id, note, created_on
+    FROM tickets_ticketevent
+   WHERE contact_id = $1 AND event_type = 'N' AND note IS NOT NULL
+ORDER BY created_on, id`
+
+const sqlSelectContactDataCalls = `
+  SELECT id, direction, status, duration, created_on, ended_on
+    FROM ivr_call
+   WHERE contact_id = $1
+ORDER BY created_on, id`
+
+// LoadContactData loads everything we know about the given contact
+func LoadContactData(ctx context.Context, db *sqlx.DB, oa *OrgAssets, contact *Contact) (*ContactData, error) {
+	fc, err := contact.FlowContact(oa)
+	if err != nil {
+		return nil, fmt.Errorf("error creating flow contact: %w", err)
+	}
+
+	d := &ContactData{
+		Contact: fc,
+		Msgs:    []*ContactDataMsg{},
+		Runs:    []*ContactDataRun{},
+		Tickets: []*ContactDataTicket{},
+		Calls:   []*ContactDataCall{},
+	}
+
+	if err := db.SelectContext(ctx, &d.Msgs, sqlSelectContactDataMsgs, contact.ID()); err != nil {
+		return nil, fmt.Errorf("error loading contact messages: %w", err)
+	}
+	if err := db.SelectContext(ctx, &d.Runs, sqlSelectContactDataRuns, contact.ID()); err != nil {
+		return nil, fmt.Errorf("error loading contact runs: %w", err)
+	}
+	if err := db.SelectContext(ctx, &d.Tickets, sqlSelectContactDataTickets, contact.ID()); err != nil {
+		return nil, fmt.Errorf("error loading contact tickets: %w", err)
+	}
+	if err := db.SelectContext(ctx, &d.Calls, sqlSelectContactDataCalls, contact.ID()); err != nil {
+		return nil, fmt.Errorf("error loading contact calls: %w", err)
+	}
+
+	var notes []*ContactDataTicketNote
+	if err := db.SelectContext(ctx, &notes, sqlSelectContactDataTicketNotes, contact.ID()); err != nil {
+		return nil, fmt.Errorf("error loading contact ticket notes: %w", err)
+	}
+
+	ticketsByID := make(map[TicketID]*ContactDataTicket, len(d.Tickets))
+	for _, t := range d.Tickets {
+		t.Notes = []*ContactDataTicketNote{}
+		ticketsByID[t.ID] = t
+	}
+	for _, n := range notes {
+		if t := ticketsByID[n.TicketID]; t != nil {
+			t.Notes = append(t.Notes, n)
+		}
+	}
+
+	return d, nil
+}
+
+const sqlSelectContactAttachments = `
+SELECT DISTINCT unnest(attachments) FROM msgs_msg WHERE contact_id = ANY($1) AND attachments IS NOT NULL`
+
+// EraseContacts erases the personal data of the given contacts and releases them. Their attachments and session
+// outputs are deleted from storage before anything in the database is changed, so that if that fails, the database
+// still references everything that needs deleting and the erasure can be retried.
+func EraseContacts(ctx context.Context, rt *runtime.Runtime, oa *OrgAssets, contacts []*Contact) error {
+	ids := make([]ContactID, len(contacts))
+	for i, c := range contacts {
+		ids[i] = c.ID()
+	}
+
+	var attachments []utils.Attachment
+	if err := rt.DB.SelectContext(ctx, &attachments, sqlSelectContactAttachments, pq.Array(ids)); err != nil {
+		return fmt.Errorf("error loading message attachments: %w", err)
+	}
+
+	if _, err := DeleteAttachmentsFromStorage(ctx, rt, attachments); err != nil {
+		return fmt.Errorf("error deleting attachments: %w", err)
+	}
+
+	for _, c := range contacts {
+		if _, err := DeleteSessionOutputsFromStorage(ctx, rt, oa.OrgID(), c.UUID()); err != nil {
+			return fmt.Errorf("error deleting session outputs: %w", err)
+		}
+	}
+
+	tx, err := rt.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	if err := EraseContactData(ctx, tx, ids); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error erasing contact data: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// EraseContactData erases the personal data of the given contacts in the database. Message text, attachments and
+// metadata, run results, ticket notes and session outputs are redacted, URNs, calls and channel events are deleted, and
+// the contacts are released. Attachments and session outputs in storage should be deleted first.
+func EraseContactData(ctx context.Context, tx *sqlx.Tx, ids []ContactID) error {
+	stmts := []struct {
+		sql  string
+		desc string
+	}{
+		{`UPDATE msgs_msg SET text = '', attachments = NULL, metadata = NULL, contact_urn_id = NULL, modified_on = NOW() WHERE contact_id = ANY($1)`, "redacting messages"},
+		{`UPDATE flows_flowrun SET results = '{}', modified_on = NOW() WHERE contact_id = ANY($1)`, "redacting run results"},
+		{`UPDATE flows_flowsession SET output = NULL, output_url = NULL, call_id = NULL WHERE contact_id = ANY($1)`, "redacting session outputs"},
+		{`UPDATE tickets_ticketevent SET note = NULL WHERE contact_id = ANY($1) AND note IS NOT NULL`, "redacting ticket notes"},
+		{`DELETE FROM ivr_call WHERE contact_id = ANY($1)`, "deleting calls"},
+		{`DELETE FROM channels_channelevent WHERE contact_id = ANY($1)`, "deleting channel events"},
+		{`DELETE FROM contacts_contacturn WHERE contact_id = ANY($1)`, "deleting URNs"},
+		{`DELETE FROM contacts_contacthistory WHERE contact_id = ANY($1)`, "deleting change history"},
+		{`DELETE FROM contacts_contactduplicate WHERE contact_a_id = ANY($1) OR contact_b_id = ANY($1)`, "deleting duplicates"},
+		{`UPDATE contacts_contact SET name = NULL, language = NULL, fields = NULL WHERE id = ANY($1)`, "redacting contacts"},
+	}
+
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s.sql, pq.Array(ids)); err != nil {
+			return fmt.Errorf("error %s: %w", s.desc, err)
+		}
+	}
+
+	return ReleaseContacts(ctx, tx, NilUserID, ids)
+}
+
+// DeleteAttachmentsFromStorage deletes the given attachments from our attachments bucket, ignoring any which aren't
+// stored there, returning the number deleted
+func DeleteAttachmentsFromStorage(ctx context.Context, rt *runtime.Runtime, attachments []utils.Attachment) (int, error) {
+	prefix := rt.S3.ObjectURL(rt.Config.S3AttachmentsBucket, "")
+
+	keys := make([]types.ObjectIdentifier, 0, len(attachments))
+	for _, a := range attachments {
+		if key, ok := strings.CutPrefix(a.URL(), prefix); ok && key != "" {
+			keys = append(keys, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+	}
+
+	deleted := 0
+
+	// S3 limits how many objects can be deleted in a single request
+	for batch := range slices.Chunk(keys, 1000) {
+		del := &types.Delete{Objects: batch}
+
+		if _, err := rt.S3.Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{Bucket: aws.String(rt.Config.S3AttachmentsBucket), Delete: del}); err != nil {
+			return deleted, fmt.Errorf("error deleting attachments from storage: %w", err)
+		}
+		deleted += len(batch)
+	}
+
+	return deleted, nil
+}
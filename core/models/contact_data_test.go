@@ -0,0 +1,105 @@
+package models_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/aws/s3x"
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactData(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData | testsuite.ResetStorage)
+
+	oa := testdata.Org1.Load(rt)
+
+	// store an attachment so we can check it's deleted from storage when contact is erased
+	stored, err := oa.Org().StoreAttachment(ctx, rt, "668383ba-387c-49bc-b164-1213ac0ea7aa.jpg", "image/jpeg", io.NopCloser(strings.NewReader("JPEG")))
+	require.NoError(t, err)
+
+	secret := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "my secret", models.MsgStatusHandled)
+	testdata.InsertOutgoingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "tell me more", []utils.Attachment{stored, "image/jpeg:https://example.com/elsewhere.jpg"}, models.MsgStatusSent, false)
+	testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Bob, "hi", models.MsgStatusHandled)
+	sessionUUID := testdata.InsertFlowSession(rt, testdata.Cathy, models.FlowTypeMessaging, models.SessionStatusCompleted, testdata.Favorites, models.NilCallID)
+	testdata.InsertFlowRun(rt, testdata.Org1, sessionUUID, testdata.Cathy, testdata.Favorites, models.RunStatusCompleted, "")
+	ticket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), nil)
+	testdata.InsertCall(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy)
+
+	rt.DB.MustExec(`UPDATE msgs_msg SET metadata = '{"detected_language": {"language": "eng", "confidence": 0.9}}' WHERE id = $1`, secret.ID)
+	rt.DB.MustExec(`UPDATE flows_flowrun SET results = '{"name": {"value": "Cathy"}}' WHERE contact_id = $1`, testdata.Cathy.ID)
+	rt.DB.MustExec(`INSERT INTO tickets_ticketevent(org_id, contact_id, ticket_id, event_type, note, created_on, created_by_id) VALUES($1, $2, $3, 'N', 'lives in Kigali', NOW(), $4)`, testdata.Org1.ID, testdata.Cathy.ID, ticket.ID, testdata.Admin.ID)
+
+	cathy, err := models.LoadContact(ctx, rt.DB, oa, testdata.Cathy.ID)
+	require.NoError(t, err)
+
+	data, err := models.LoadContactData(ctx, rt.DB, oa, cathy)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Cathy", data.Contact.Name())
+	assert.Len(t, data.Contact.URNs(), 1)
+	if assert.Len(t, data.Msgs, 2) {
+		assert.Equal(t, "my secret", data.Msgs[0].Text)
+		assert.Equal(t, "tell me more", data.Msgs[1].Text)
+	}
+	if assert.Len(t, data.Runs, 1) {
+		assert.Equal(t, "Favorites", data.Runs[0].FlowName)
+		assert.JSONEq(t, `{"name": {"value": "Cathy"}}`, string(data.Runs[0].Results))
+	}
+	if assert.Len(t, data.Tickets, 1) {
+		assert.Equal(t, ticket.UUID, data.Tickets[0].UUID)
+		if assert.Len(t, data.Tickets[0].Notes, 1) {
+			assert.Equal(t, "lives in Kigali", data.Tickets[0].Notes[0].Note)
+		}
+	}
+	assert.Len(t, data.Calls, 1)
+
+	// if deleting from storage fails, nothing in the database is changed so that the erasure can be retried
+	goodS3 := rt.S3
+	rt.S3, err = s3x.NewService("bad", "credentials", rt.Config.AWSRegion, rt.Config.S3Endpoint, rt.Config.S3Minio)
+	require.NoError(t, err)
+
+	err = models.EraseContacts(ctx, rt, oa, []*models.Contact{cathy})
+	assert.ErrorContains(t, err, "error deleting attachments")
+
+	rt.S3 = goodS3
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE contact_id = $1 AND attachments IS NOT NULL`, testdata.Cathy.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contact WHERE id = $1 AND name = 'Cathy' AND is_active`, testdata.Cathy.ID).Returns(1)
+
+	_, _, err = rt.S3.GetObject(ctx, rt.Config.S3AttachmentsBucket, "attachments/1/6683/83ba/668383ba-387c-49bc-b164-1213ac0ea7aa.jpg")
+	assert.NoError(t, err)
+
+	// retrying succeeds
+	err = models.EraseContacts(ctx, rt, oa, []*models.Contact{cathy})
+	assert.NoError(t, err)
+
+	_, _, err = rt.S3.GetObject(ctx, rt.Config.S3AttachmentsBucket, "attachments/1/6683/83ba/668383ba-387c-49bc-b164-1213ac0ea7aa.jpg")
+	assert.Error(t, err)
+
+	// only attachments in our storage are deleted, and deleting them again is a noop
+	deleted, err := models.DeleteAttachmentsFromStorage(ctx, rt, []utils.Attachment{stored, "image/jpeg:https://example.com/elsewhere.jpg"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE contact_id = $1 AND text != ''`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE contact_id = $1 AND (attachments IS NOT NULL OR metadata IS NOT NULL)`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE contact_id = $1 AND text != ''`, testdata.Bob.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM flows_flowrun WHERE contact_id = $1 AND results != '{}'`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM flows_flowsession WHERE contact_id = $1 AND output IS NOT NULL`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE contact_id = $1 AND note IS NOT NULL`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM ivr_call WHERE contact_id = $1`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE contact_id = $1`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE identity = 'tel:+16055741111'`).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contact WHERE id = $1 AND name IS NULL AND fields IS NULL AND NOT is_active`, testdata.Cathy.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT status FROM tickets_ticket WHERE id = $1`, ticket.ID).Returns("C")
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nyaruka/gocommon/dates"
+)
+
+// ContactDuplicateReason is why a pair of contacts are thought to be duplicates
+type ContactDuplicateReason string
+
+const (
+	ContactDuplicateReasonPhone   ContactDuplicateReason = "phone"
+	ContactDuplicateReasonNameDOB ContactDuplicateReason = "name_dob"
+	ContactDuplicateReasonEmail   ContactDuplicateReason = "email"
+)
+
+// confidence scores for each reason
+var contactDuplicateConfidences = map[ContactDuplicateReason]float64{
+	ContactDuplicateReasonPhone:   0.9,
+	ContactDuplicateReasonNameDOB: 0.8,
+	ContactDuplicateReasonEmail:   0.7,
+}
+
+// values shared by more contacts than this are likely shared by different people, e.g. a family phone number
+const maxContactDuplicateGroupSize = 10
+
+// ContactDuplicate is a pair of contacts which are probably the same person, stored for review
+type ContactDuplicate struct {
+	OrgID      OrgID                  `db:"org_id"`
+	ContactAID ContactID              `db:"contact_a_id"`
+	ContactBID ContactID              `db:"contact_b_id"`
+	Reason     ContactDuplicateReason `db:"reason"`
+	Confidence float64                `db:"confidence"`
+	CreatedOn  time.Time              `db:"created_on"`
+}
+
+// matches tel and whatsapp URNs on their digits so that tel:+250788123123 and whatsapp:250788123123 are the same
+const sqlSelectDuplicatesByPhone = `
+  SELECT array_agg(DISTINCT u.contact_id ORDER BY u.contact_id) AS contact_ids
+    FROM contacts_contacturn u
+    JOIN contacts_contact c ON c.id = u.contact_id
+   WHERE u.org_id = $1 AND u.scheme IN ('tel', 'whatsapp') AND c.is_active
+GROUP BY regexp_replace(u.path, '[^0-9]', '', 'g')
+  HAVING count(DISTINCT u.contact_id) BETWEEN 2 AND $2`
+
+const sqlSelectDuplicatesByNameAndField = `
+  SELECT array_agg(id ORDER BY id) AS contact_ids
+    FROM contacts_contact
+   WHERE org_id = $1 AND is_active AND name IS NOT NULL AND name != '' AND fields ? $3
+GROUP BY lower(trim(name)), left(coalesce(fields->$3->>'datetime', fields->$3->>'text'), 10)
+  HAVING count(*) BETWEEN 2 AND $2`
+
+const sqlSelectDuplicatesByField = `
+  SELECT array_agg(id ORDER BY id) AS contact_ids
+    FROM contacts_contact
+   WHERE org_id = $1 AND is_active AND fields ? $3
+GROUP BY lower(trim(fields->$3->>'text'))
+  HAVING count(*) BETWEEN 2 AND $2`
+
+// FindDuplicateContactsByPhone finds contacts with the same phone number across tel and whatsapp URNs
+func FindDuplicateContactsByPhone(ctx context.Context, db Queryer, orgID OrgID) ([]*ContactDuplicate, error) {
+	return findContactDuplicates(ctx, db, orgID, ContactDuplicateReasonPhone, sqlSelectDuplicatesByPhone, orgID, maxContactDuplicateGroupSize)
+}
+
+// FindDuplicateContactsByNameAndDOB finds contacts with the same name and date of birth field value
+func FindDuplicateContactsByNameAndDOB(ctx context.Context, db Queryer, orgID OrgID, dobField *Field) ([]*ContactDuplicate, error) {
+	return findContactDuplicates(ctx, db, orgID, ContactDuplicateReasonNameDOB, sqlSelectDuplicatesByNameAndField, orgID, maxContactDuplicateGroupSize, dobField.UUID())
+}
+
+// FindDuplicateContactsByEmail finds contacts with the same email field value
+func FindDuplicateContactsByEmail(ctx context.Context, db Queryer, orgID OrgID, emailField *Field) ([]*ContactDuplicate, error) {
+	return findContactDuplicates(ctx, db, orgID, ContactDuplicateReasonEmail, sqlSelectDuplicatesByField, orgID, maxContactDuplicateGroupSize, emailField.UUID())
+}
+
+// runs a query which returns groups of matching contact ids, and converts each group into pairs of duplicates
+func findContactDuplicates(ctx context.Context, db Queryer, orgID OrgID, reason ContactDuplicateReason, query string, args ...any) ([]*ContactDuplicate, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying %s duplicates: %w", reason, err)
+	}
+	defer rows.Close()
+
+	now := dates.Now()
+	dupes := make([]*ContactDuplicate, 0, 10)
+
+	for rows.Next() {
+		var ids pq.Int64Array
+		if err := rows.Scan(&ids); err != nil {
+			return nil, fmt.Errorf("error scanning %s duplicates: %w", reason, err)
+		}
+
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				dupes = append(dupes, &ContactDuplicate{
+					OrgID:      orgID,
+					ContactAID: ContactID(ids[i]),
+					ContactBID: ContactID(ids[j]),
+					Reason:     reason,
+					Confidence: contactDuplicateConfidences[reason],
+					CreatedOn:  now,
+				})
+			}
+		}
+	}
+
+	return dupes, rows.Err()
+}
+
+const sqlInsertContactDuplicates = `
+INSERT INTO contacts_contactduplicate(org_id,  contact_a_id,  contact_b_id,  reason,  confidence,  created_on)
+                              VALUES(:org_id, :contact_a_id, :contact_b_id, :reason, :confidence, :created_on)
+ON CONFLICT (contact_a_id, contact_b_id, reason) DO NOTHING`
+
+// InsertContactDuplicates stores candidate duplicates, ignoring pairs which have already been found for the same reason
+func InsertContactDuplicates(ctx context.Context, db DBorTx, dupes []*ContactDuplicate) error {
+	return BulkQueryBatches(ctx, "inserting contact duplicates", db, sqlInsertContactDuplicates, 1000, dupes)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+)
+
+// ContactHistoryID is the type for contact history IDs
+type ContactHistoryID int64
+
+// NilContactHistoryID is our nil value for contact history IDs
+const NilContactHistoryID = ContactHistoryID(0)
+
+// ContactHistory is an append-only record of a change made to a contact, and who made it, either a user or a flow
+type ContactHistory struct {
+	ID          ContactHistoryID `db:"id"            json:"id"`
+	OrgID       OrgID            `db:"org_id"        json:"-"`
+	ContactID   ContactID        `db:"contact_id"    json:"-"`
+	EventType   string           `db:"event_type"    json:"type"`
+	Event       json.RawMessage  `db:"event"         json:"event"`
+	CreatedByID UserID           `db:"created_by_id" json:"created_by_id,omitempty"`
+	FlowID      FlowID           `db:"flow_id"       json:"flow_id,omitempty"`
+	CreatedOn   time.Time        `db:"created_on"    json:"created_on"`
+}
+
+// NewContactHistory creates a new contact history item from the given event
+func NewContactHistory(orgID OrgID, contactID ContactID, e flows.Event, userID UserID, flowID FlowID) *ContactHistory {
+	return &ContactHistory{
+		OrgID:       orgID,
+		ContactID:   contactID,
+		EventType:   e.Type(),
+		Event:       jsonx.MustMarshal(e),
+		CreatedByID: userID,
+		FlowID:      flowID,
+		CreatedOn:   e.CreatedOn(),
+	}
+}
+
+const sqlInsertContactHistory = `
+INSERT INTO contacts_contacthistory(org_id,  contact_id,  event_type,  event,  created_by_id,  flow_id,  created_on)
+                            VALUES(:org_id, :contact_id, :event_type, :event, :created_by_id, :flow_id, :created_on)
+RETURNING id`
+
+// InsertContactHistory inserts the given contact history items
+func InsertContactHistory(ctx context.Context, db DBorTx, history []*ContactHistory) error {
+	return BulkQuery(ctx, "inserting contact history", db, sqlInsertContactHistory, history)
+}
+
+const sqlSelectContactHistory = `
+  SELECT id, org_id, contact_id, event_type, event, created_by_id, flow_id, created_on
+    FROM contacts_contacthistory
+   WHERE org_id = $1 AND contact_id = $2 AND ($3 = 0 OR id < $3)
+ORDER BY id DESC
+   LIMIT $4`
+
+// LoadContactHistory loads a page of the change history of the given contact, newest first, from before the given
+// history item or from the start if that is nil
+func LoadContactHistory(ctx context.Context, db DBorTx, orgID OrgID, contactID ContactID, before ContactHistoryID, limit int) ([]*ContactHistory, error) {
+	history := make([]*ContactHistory, 0, limit)
+
+	if err := db.SelectContext(ctx, &history, sqlSelectContactHistory, orgID, contactID, before, limit); err != nil {
+		return nil, fmt.Errorf("error loading contact history: %w", err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// ContactLock is a lock currently held on a contact
+type ContactLock struct {
+	ContactID ContactID `json:"contact_id"`
+	Lock      string    `json:"lock"`
+	Holder    string    `json:"holder"`
+	GrabbedOn time.Time `json:"grabbed_on"`
+}
+
+// to be able to list the locks held on an org's contacts, we keep a hash per org of contact id to lock details
+func contactLocksKey(orgID OrgID) string {
+	return fmt.Sprintf("lock:c:%d:held", orgID)
+}
+
+// removes a contact from the held locks hash, but only if the entry there is for the given lock value
+var forgetContactLockScript = redis.NewScript(1, `
+local locksKey, contactID, lockValue = KEYS[1], ARGV[1], ARGV[2]
+
+local held = redis.call("HGET", locksKey, contactID)
+if held and cjson.decode(held)["lock"] == lockValue then
+	return redis.call("HDEL", locksKey, contactID)
+end
+return 0
+`)
+
+// records that the given lock on a contact is held by this instance
+func recordContactLock(rt *runtime.Runtime, orgID OrgID, contactID ContactID, lock string) error {
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	held := &ContactLock{ContactID: contactID, Lock: lock, Holder: rt.Config.InstanceID, GrabbedOn: dates.Now()}
+
+	if _, err := rc.Do("HSET", contactLocksKey(orgID), contactID, jsonx.MustMarshal(held)); err != nil {
+		return fmt.Errorf("error recording contact lock: %w", err)
+	}
+	return nil
+}
+
+// removes the record of the given lock on a contact
+func forgetContactLock(rc redis.Conn, orgID OrgID, contactID ContactID, lock string) error {
+	if _, err := forgetContactLockScript.Do(rc, contactLocksKey(orgID), contactID, lock); err != nil {
+		return fmt.Errorf("error forgetting contact lock: %w", err)
+	}
+	return nil
+}
+
+// LoadContactLocks loads the locks currently held on contacts in the given org, ordered by age. Records of locks
+// which have since expired, e.g. because their holder crashed, are removed.
+func LoadContactLocks(ctx context.Context, rt *runtime.Runtime, orgID OrgID) ([]*ContactLock, error) {
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	all, err := redis.StringMap(rc.Do("HGETALL", contactLocksKey(orgID)))
+	if err != nil {
+		return nil, fmt.Errorf("error loading contact locks: %w", err)
+	}
+
+	locks := make([]*ContactLock, 0, len(all))
+
+	for _, v := range all {
+		held := &ContactLock{}
+		if err := jsonx.Unmarshal([]byte(v), held); err != nil {
+			return nil, fmt.Errorf("error unmarshaling contact lock: %w", err)
+		}
+
+		current, err := redis.String(rc.Do("GET", contactLockKey(orgID, held.ContactID)))
+		if err != nil && err != redis.ErrNil {
+			return nil, fmt.Errorf("error checking contact lock: %w", err)
+		}
+
+		if current == held.Lock {
+			locks = append(locks, held)
+		} else if err := forgetContactLock(rc, orgID, held.ContactID, held.Lock); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(locks, func(i, j int) bool { return locks[i].GrabbedOn.Before(locks[j].GrabbedOn) })
+
+	return locks, nil
+}
+
+// ForceUnlockContact releases the lock on the given contact regardless of who holds it, returning whether there was
+// a lock to release. This should only be used for locks whose holders have failed to release them.
+func ForceUnlockContact(ctx context.Context, rt *runtime.Runtime, orgID OrgID, contactID ContactID) (bool, error) {
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	deleted, err := redis.Int(rc.Do("DEL", contactLockKey(orgID, contactID)))
+	if err != nil {
+		return false, fmt.Errorf("error releasing contact lock: %w", err)
+	}
+
+	if _, err := rc.Do("HDEL", contactLocksKey(orgID), contactID); err != nil {
+		return false, fmt.Errorf("error forgetting contact lock: %w", err)
+	}
+
+	return deleted > 0, nil
+}
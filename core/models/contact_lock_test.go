@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/nyaruka/redisx/assertredis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactLocks(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	defer testsuite.Reset(testsuite.ResetRedis)
+
+	rt.Stats.Extract()
+
+	locks, _, err := models.LockContacts(ctx, rt, testdata.Org1.ID, []models.ContactID{101, 102, 103}, time.Second)
+	require.NoError(t, err)
+
+	assertredis.HLen(t, rc, "lock:c:1:held", 3)
+
+	// trying to lock an already locked contact is recorded as contention
+	_, skipped, err := models.LockContacts(ctx, rt, testdata.Org1.ID, []models.ContactID{102}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ContactID{102}, skipped)
+	assert.Equal(t, 1, rt.Stats.Extract().ContactLockContentionCount)
+
+	held, err := models.LoadContactLocks(ctx, rt, testdata.Org1.ID)
+	require.NoError(t, err)
+	if assert.Len(t, held, 3) {
+		assert.Equal(t, locks[held[0].ContactID], held[0].Lock)
+		assert.Equal(t, rt.Config.InstanceID, held[0].Holder)
+	}
+
+	// if a lock expires without being released, it's no longer listed
+	rc.Do("DEL", "lock:c:1:103")
+
+	held, err = models.LoadContactLocks(ctx, rt, testdata.Org1.ID)
+	require.NoError(t, err)
+	assert.Len(t, held, 2)
+	assertredis.HLen(t, rc, "lock:c:1:held", 2)
+
+	// releasing a lock normally removes it
+	err = models.UnlockContacts(rt, testdata.Org1.ID, map[models.ContactID]string{101: locks[101]})
+	assert.NoError(t, err)
+	assertredis.HLen(t, rc, "lock:c:1:held", 1)
+
+	released, err := models.ForceUnlockContact(ctx, rt, testdata.Org1.ID, 102)
+	assert.NoError(t, err)
+	assert.True(t, released)
+
+	assertredis.NotExists(t, rc, "lock:c:1:102")
+	assertredis.NotExists(t, rc, "lock:c:1:held")
+
+	released, err = models.ForceUnlockContact(ctx, rt, testdata.Org1.ID, 102)
+	assert.NoError(t, err)
+	assert.False(t, released)
+
+	// the original holder releasing the lock later is a noop
+	err = models.UnlockContacts(rt, testdata.Org1.ID, map[models.ContactID]string{102: locks[102]})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+)
+
+// ContactMergeStrategy is how conflicting field values are resolved when merging contacts
+type ContactMergeStrategy string
+
+const (
+	// values on the primary contact are kept, and only empty fields take values from the secondaries
+	ContactMergePrimaryWins ContactMergeStrategy = "primary_wins"
+
+	// values are taken from the most recently modified contact which has a value for the field
+	ContactMergeNewestWins ContactMergeStrategy = "newest_wins"
+)
+
+// MergeContactFields returns the field values, by field key, which should be set on the primary contact when merging
+// the given secondary contacts into it. Note that we don't track when individual fields change, so the newest value
+// is the value from the most recently modified contact.
+func MergeContactFields(primary *Contact, secondaries []*Contact, strategy ContactMergeStrategy) map[string]*flows.Value {
+	// order secondaries by most recently modified first
+	others := slices.Clone(secondaries)
+	slices.SortStableFunc(others, func(a, b *Contact) int { return b.ModifiedOn().Compare(a.ModifiedOn()) })
+
+	changes := make(map[string]*flows.Value)
+
+	for _, other := range others {
+		newer := other.ModifiedOn().After(primary.ModifiedOn())
+
+		for key, value := range other.Fields() {
+			if value == nil || changes[key] != nil {
+				continue
+			}
+
+			current := primary.Fields()[key]
+			if current == nil || (strategy == ContactMergeNewestWins && newer) {
+				changes[key] = value
+			}
+		}
+	}
+
+	return changes
+}
+
+// MergeContactGroups returns the manual groups of the secondary contacts which the primary contact isn't in. Smart
+// groups aren't merged as they're recalculated from the merged contact.
+func MergeContactGroups(primary *Contact, secondaries []*Contact) []*Group {
+	groups := make([]*Group, 0, 5)
+
+	for _, other := range secondaries {
+		for _, g := range other.Groups() {
+			if g.Type() == GroupTypeManual && !slices.Contains(primary.Groups(), g) && !slices.Contains(groups, g) {
+				groups = append(groups, g)
+			}
+		}
+	}
+
+	return groups
+}
+
+// tables with a contact_id column whose rows are moved to the primary contact when merging
+var contactMergeTables = []string{"msgs_msg", "tickets_ticket", "tickets_ticketevent", "flows_flowrun", "flows_flowsession", "ivr_call"}
+
+const sqlSelectOpenTicketsForContact = `
+SELECT
+  id,
+  uuid,
+  org_id,
+  contact_id,
+  status,
+  topic_id,
+  assignee_id,
+  opened_on,
+  opened_by_id,
+  opened_in_id,
+  replied_on,
+  modified_on,
+  closed_on,
+  last_activity_on
+    FROM tickets_ticket
+   WHERE contact_id = $1 AND status = 'O'
+ORDER BY opened_on DESC, id DESC`
+
+const sqlUpdateContactTicketCounts = `
+UPDATE contacts_contact c
+   SET ticket_count = (SELECT count(*) FROM tickets_ticket t WHERE t.contact_id = c.id AND t.status = 'O')
+ WHERE c.id = ANY($1)`
+
+// the change history event recorded on the primary contact when other contacts are merged into it
+type contactMergedEvent struct {
+	Type      string                    `json:"type"`
+	CreatedOn time.Time                 `json:"created_on"`
+	Contacts  []*flows.ContactReference `json:"contacts"`
+}
+
+const contactMergedEventType = "contact_merged"
+
+// MergeContactHistory moves the history of the secondary contacts to the primary contact. Waiting sessions of the
+// secondaries are interrupted, any open tickets are merged into a single open ticket for the primary, and the merge is
+// recorded in the primary's change history. Returns the events of the merged tickets, which have already been inserted.
+// Releasing the secondaries is left to the caller.
+func MergeContactHistory(ctx context.Context, tx *sqlx.Tx, oa *OrgAssets, userID UserID, primary *Contact, secondaries []*Contact) (map[*Ticket]*TicketEvent, error) {
+	secondaryIDs := make([]ContactID, len(secondaries))
+	for i, c := range secondaries {
+		secondaryIDs[i] = c.ID()
+	}
+
+	if err := InterruptSessionsForContactsTx(ctx, tx, secondaryIDs); err != nil {
+		return nil, fmt.Errorf("error interrupting secondary contacts: %w", err)
+	}
+
+	for _, table := range contactMergeTables {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET contact_id = $1 WHERE contact_id = ANY($2)`, table), primary.ID(), pq.Array(secondaryIDs)); err != nil {
+			return nil, fmt.Errorf("error moving %s rows to primary contact: %w", table, err)
+		}
+	}
+
+	// a contact can only have one open ticket so any other open tickets are merged into the primary's
+	tickets, err := loadTickets(ctx, tx, sqlSelectOpenTicketsForContact, primary.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	ticketEvents := make(map[*Ticket]*TicketEvent)
+	if len(tickets) > 1 {
+		target := tickets[0]
+		for _, t := range tickets {
+			if primary.Ticket() != nil && t.UUID() == primary.Ticket().UUID() {
+				target = t
+			}
+		}
+
+		if ticketEvents, err = MergeTickets(ctx, tx, oa, userID, target, tickets); err != nil {
+			return nil, fmt.Errorf("error merging open tickets: %w", err)
+		}
+	}
+
+	// tickets have been moved so recount the open tickets of all the contacts involved
+	if _, err := tx.ExecContext(ctx, sqlUpdateContactTicketCounts, pq.Array(append([]ContactID{primary.ID()}, secondaryIDs...))); err != nil {
+		return nil, fmt.Errorf("error updating contact ticket counts: %w", err)
+	}
+
+	merged := &contactMergedEvent{Type: contactMergedEventType, CreatedOn: dates.Now(), Contacts: make([]*flows.ContactReference, len(secondaries))}
+	for i, c := range secondaries {
+		merged.Contacts[i] = flows.NewContactReference(c.UUID(), c.Name())
+	}
+
+	history := &ContactHistory{
+		OrgID:       oa.OrgID(),
+		ContactID:   primary.ID(),
+		EventType:   merged.Type,
+		Event:       jsonx.MustMarshal(merged),
+		CreatedByID: userID,
+		CreatedOn:   merged.CreatedOn,
+	}
+	if err := InsertContactHistory(ctx, tx, []*ContactHistory{history}); err != nil {
+		return nil, fmt.Errorf("error recording merge in contact history: %w", err)
+	}
+
+	if err := UpdateContactModifiedOn(ctx, tx, []ContactID{primary.ID()}); err != nil {
+		return nil, fmt.Errorf("error updating primary contact: %w", err)
+	}
+
+	return ticketEvents, nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/gocommon/dates"
+)
+
+// config keys (on orgs and channels) which configure inbound rate limiting of contacts
+const (
+	ConfigContactMsgRate       = "contact_msg_rate"        // messages per minute
+	ConfigContactMsgBurst      = "contact_msg_burst"       // how many messages can be sent in a burst
+	ConfigContactMsgBlockAfter = "contact_msg_block_after" // how many limited messages in an hour before contact is blocked
+)
+
+// InboundRateLimit is a token bucket limit on how fast a single contact can send us messages
+type InboundRateLimit struct {
+	Rate       int
+	Burst      int
+	BlockAfter int
+}
+
+// GetInboundRateLimit gets the inbound rate limit for contacts messaging on the given channel. Channel config takes
+// precedence over org config. Returns nil if there is no limit.
+func GetInboundRateLimit(oa *OrgAssets, ch *Channel) *InboundRateLimit {
+	limit := &InboundRateLimit{
+		Rate:       oa.Org().ConfigInt(ConfigContactMsgRate, 0),
+		Burst:      oa.Org().ConfigInt(ConfigContactMsgBurst, 0),
+		BlockAfter: oa.Org().ConfigInt(ConfigContactMsgBlockAfter, 0),
+	}
+	if ch != nil {
+		limit.Rate = ch.Config().GetInt(ConfigContactMsgRate, limit.Rate)
+		limit.Burst = ch.Config().GetInt(ConfigContactMsgBurst, limit.Burst)
+		limit.BlockAfter = ch.Config().GetInt(ConfigContactMsgBlockAfter, limit.BlockAfter)
+	}
+
+	if limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Rate
+	}
+	return limit
+}
+
+var inboundRateLimitScript = redis.NewScript(2, `
+local bucket_key, exceeded_key = KEYS[1], KEYS[2]
+local rate, burst, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+
+-- refill the bucket based on time elapsed since it was last used (now is in milliseconds, rate is per minute)
+local bucket = redis.call("HMGET", bucket_key, "tokens", "ts")
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 60000)
+
+local exceeded = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	exceeded = redis.call("INCR", exceeded_key)
+	redis.call("EXPIRE", exceeded_key, 3600)
+end
+
+redis.call("HSET", bucket_key, "tokens", tostring(tokens), "ts", now)
+redis.call("EXPIRE", bucket_key, math.ceil(burst * 60 / rate) + 60)
+
+return exceeded
+`)
+
+// CheckInboundRateLimit takes a token from the contact's bucket for the given channel. If the bucket is empty, the
+// returned count is the number of times the contact has exceeded the limit in the last hour, otherwise it's zero.
+func CheckInboundRateLimit(rc redis.Conn, limit *InboundRateLimit, ch *Channel, contactID ContactID) (int, error) {
+	bucketKey := fmt.Sprintf("contact_rate:%d:%d", ch.ID(), contactID)
+	exceededKey := fmt.Sprintf("contact_rate_exceeded:%d", contactID)
+
+	exceeded, err := redis.Int(inboundRateLimitScript.Do(rc, bucketKey, exceededKey, limit.Rate, limit.Burst, dates.Now().UnixMilli()))
+	if err != nil {
+		return 0, fmt.Errorf("error checking inbound rate limit: %w", err)
+	}
+	return exceeded, nil
+}
+
+// ShouldBlock returns whether a contact who has exceeded this limit the given number of times should be blocked
+func (l *InboundRateLimit) ShouldBlock(exceeded int) bool {
+	return l.BlockAfter > 0 && exceeded >= l.BlockAfter
+}
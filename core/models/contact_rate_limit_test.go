@@ -0,0 +1,63 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboundRateLimit(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	defer testsuite.Reset(testsuite.ResetData | testsuite.ResetRedis)
+	defer dates.SetNowFunc(time.Now)
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"contact_msg_rate": 6, "contact_msg_burst": 2, "contact_msg_block_after": 3}' WHERE id = $1`, testdata.Org1.ID)
+	rt.DB.MustExec(`UPDATE channels_channel SET config = config || '{"contact_msg_rate": 0}' WHERE id = $1`, testdata.VonageChannel.ID)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg|models.RefreshChannels)
+	require.NoError(t, err)
+
+	twilio := oa.ChannelByID(testdata.TwilioChannel.ID)
+
+	limit := models.GetInboundRateLimit(oa, twilio)
+	assert.Equal(t, &models.InboundRateLimit{Rate: 6, Burst: 2, BlockAfter: 3}, limit)
+	assert.Nil(t, models.GetInboundRateLimit(oa, oa.ChannelByID(testdata.VonageChannel.ID))) // disabled on this channel
+
+	start := time.Date(2024, 11, 15, 13, 0, 0, 0, time.UTC)
+	dates.SetNowFunc(dates.NewFixedNow(start))
+
+	assertExceeded := func(expected int) {
+		exceeded, err := models.CheckInboundRateLimit(rc, limit, twilio, testdata.Cathy.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, exceeded)
+	}
+
+	// burst of 2 allowed, then limited
+	assertExceeded(0)
+	assertExceeded(0)
+	assertExceeded(1)
+	assertExceeded(2)
+
+	// other contacts have their own bucket
+	exceeded, err := models.CheckInboundRateLimit(rc, limit, twilio, testdata.Bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, exceeded)
+
+	// after 10 seconds, one more token is available
+	dates.SetNowFunc(dates.NewFixedNow(start.Add(10 * time.Second)))
+
+	assertExceeded(0)
+	assertExceeded(3)
+
+	assert.False(t, limit.ShouldBlock(2))
+	assert.True(t, limit.ShouldBlock(3))
+}
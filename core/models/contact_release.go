@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nyaruka/gocommon/dates"
+)
+
+const sqlSelectOpenTicketsForContacts = `
+SELECT
+  id,
+  uuid,
+  org_id,
+  contact_id,
+  status,
+  topic_id,
+  assignee_id,
+  opened_on,
+  opened_by_id,
+  opened_in_id,
+  replied_on,
+  modified_on,
+  closed_on,
+  last_activity_on
+    FROM tickets_ticket
+   WHERE contact_id = ANY($1) AND status = 'O'
+ORDER BY id`
+
+// ReleaseContacts releases the given contacts. Their waiting sessions are interrupted, open tickets closed, and they
+// are removed from all groups, their URNs detached so they can be reused, their fires deleted and they are marked as
+// inactive. Callers should remove released contacts from the search index after the transaction is committed.
+func ReleaseContacts(ctx context.Context, tx *sqlx.Tx, userID UserID, ids []ContactID) error {
+	if err := InterruptSessionsForContactsTx(ctx, tx, ids); err != nil {
+		return fmt.Errorf("error interrupting released contacts: %w", err)
+	}
+
+	tickets, err := loadTickets(ctx, tx, sqlSelectOpenTicketsForContacts, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	if len(tickets) > 0 {
+		ticketIDs := make([]TicketID, len(tickets))
+		events := make([]*TicketEvent, len(tickets))
+		for i, t := range tickets {
+			ticketIDs[i] = t.ID()
+			events[i] = NewTicketClosedEvent(t, userID)
+		}
+
+		if _, err := tx.ExecContext(ctx, sqlCloseTickets, pq.Array(ticketIDs), dates.Now()); err != nil {
+			return fmt.Errorf("error closing tickets of released contacts: %w", err)
+		}
+		if err := InsertTicketEvents(ctx, tx, events); err != nil {
+			return fmt.Errorf("error inserting ticket events: %w", err)
+		}
+	}
+
+	stmts := []struct {
+		sql  string
+		desc string
+	}{
+		{`DELETE FROM contacts_contactgroup_contacts WHERE contact_id = ANY($1)`, "removing released contacts from groups"},
+		{`UPDATE contacts_contacturn SET contact_id = NULL WHERE contact_id = ANY($1)`, "detaching URNs of released contacts"},
+		{`DELETE FROM contacts_contactfire WHERE contact_id = ANY($1)`, "deleting fires of released contacts"},
+		{`UPDATE contacts_contact SET is_active = FALSE, ticket_count = 0, modified_on = NOW() WHERE id = ANY($1)`, "releasing contacts"},
+	}
+
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s.sql, pq.Array(ids)); err != nil {
+			return fmt.Errorf("error %s: %w", s.desc, err)
+		}
+	}
+
+	return nil
+}
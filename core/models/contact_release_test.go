@@ -0,0 +1,41 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseContacts(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	friends := testdata.InsertContactGroup(rt, testdata.Org1, "3bd6dab9-d0c8-4ea7-a44b-0f87e7c0c6a5", "Friends", "", testdata.Cathy, testdata.Bob)
+	testdata.InsertWaitingSession(rt, testdata.Org1, testdata.Cathy, models.FlowTypeMessaging, testdata.Favorites, models.NilCallID)
+	ticket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), nil)
+	testdata.InsertContactFire(rt, testdata.Org1, testdata.Cathy, models.ContactFireTypeCampaignEvent, "235:1", time.Now(), "")
+	rt.DB.MustExec(`UPDATE contacts_contact SET ticket_count = 1 WHERE id = $1`, testdata.Cathy.ID)
+
+	tx := rt.DB.MustBeginTx(ctx, nil)
+	require.NoError(t, models.ReleaseContacts(ctx, tx, testdata.Admin.ID, []models.ContactID{testdata.Cathy.ID}))
+	require.NoError(t, tx.Commit())
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contact WHERE id = $1 AND NOT is_active AND ticket_count = 0 AND current_session_uuid IS NULL`, testdata.Cathy.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM flows_flowsession WHERE contact_id = $1 AND status = 'I'`, testdata.Cathy.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT status FROM tickets_ticket WHERE id = $1`, ticket.ID).Returns("C")
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = $1 AND event_type = 'C' AND created_by_id = $2`, ticket.ID, testdata.Admin.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactgroup_contacts WHERE contact_id = $1`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE contact_id = $1`, testdata.Cathy.ID).Returns(0)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE identity = 'tel:+16055741111' AND contact_id IS NULL`).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactfire WHERE contact_id = $1`, testdata.Cathy.ID).Returns(0)
+
+	// other contacts are untouched
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contact WHERE id = $1 AND is_active`, testdata.Bob.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactgroup_contacts WHERE contact_id = $1 AND contactgroup_id = $2`, testdata.Bob.ID, friends.ID).Returns(1)
+}
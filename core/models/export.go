@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/null/v3"
+)
+
+// ExportID is the type for export IDs
+type ExportID int
+
+// NilExportID is our nil value for export IDs
+const NilExportID = ExportID(0)
+
+func (i *ExportID) Scan(value any) error         { return null.ScanInt(value, i) }
+func (i ExportID) Value() (driver.Value, error)  { return null.IntValue(i) }
+func (i *ExportID) UnmarshalJSON(b []byte) error { return null.UnmarshalInt(b, i) }
+func (i ExportID) MarshalJSON() ([]byte, error)  { return null.MarshalInt(i) }
+
+// ExportType is the type of an export
+type ExportType string
+
+// export type constants
+const (
+	ExportTypeContacts ExportType = "contact"
+)
+
+// ExportStatus is the status of an export
+type ExportStatus string
+
+// export status constants
+const (
+	ExportStatusPending    ExportStatus = "P"
+	ExportStatusProcessing ExportStatus = "O"
+	ExportStatusComplete   ExportStatus = "C"
+	ExportStatusFailed     ExportStatus = "F"
+)
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+// export format constants
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatXLSX   ExportFormat = "xlsx"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// Export is the writing of a file of org data to storage, which the user who requested it is notified of when finished
+type Export struct {
+	ID          ExportID        `db:"id"`
+	UUID        uuids.UUID      `db:"uuid"`
+	OrgID       OrgID           `db:"org_id"`
+	ExportType  ExportType      `db:"export_type"`
+	Status      ExportStatus    `db:"status"`
+	Config      json.RawMessage `db:"config"`
+	NumRecords  int             `db:"num_records"`
+	Path        null.String     `db:"path"`
+	CreatedByID UserID          `db:"created_by_id"`
+	CreatedOn   time.Time       `db:"created_on"`
+	ModifiedOn  time.Time       `db:"modified_on"`
+}
+
+// ContactExportConfig is the config of a contact export, i.e. which contacts and which of their properties to include
+type ContactExportConfig struct {
+	GroupID    GroupID      `json:"group_id"`
+	Query      string       `json:"query,omitempty"`
+	Format     ExportFormat `json:"format"`
+	Fields     []string     `json:"fields"`
+	URNSchemes []string     `json:"urn_schemes"`
+	GroupIDs   []GroupID    `json:"group_ids"`
+}
+
+// NewContactExport creates a new export of the contacts in the given group and optionally matching the given query
+func NewContactExport(orgID OrgID, userID UserID, config *ContactExportConfig) *Export {
+	configJSON, _ := json.Marshal(config)
+	now := dates.Now()
+
+	return &Export{
+		UUID:        uuids.NewV4(),
+		OrgID:       orgID,
+		ExportType:  ExportTypeContacts,
+		Status:      ExportStatusPending,
+		Config:      configJSON,
+		CreatedByID: userID,
+		CreatedOn:   now,
+		ModifiedOn:  now,
+	}
+}
+
+// ContactConfig returns the config of this export as a contact export config
+func (e *Export) ContactConfig() (*ContactExportConfig, error) {
+	c := &ContactExportConfig{}
+	if err := json.Unmarshal(e.Config, c); err != nil {
+		return nil, fmt.Errorf("error unmarshaling contact export config: %w", err)
+	}
+	return c, nil
+}
+
+const sqlInsertExport = `
+INSERT INTO orgs_export(uuid,  org_id,  export_type,  status,  config, num_records,  created_by_id,  created_on,  modified_on)
+                 VALUES(:uuid, :org_id, :export_type, :status, :config,           0, :created_by_id, :created_on, :modified_on)
+RETURNING id`
+
+// InsertExport inserts the given export
+func InsertExport(ctx context.Context, db DBorTx, e *Export) error {
+	return BulkQuery(ctx, "inserting export", db, sqlInsertExport, []*Export{e})
+}
+
+const sqlSelectExport = `
+SELECT id, uuid, org_id, export_type, status, config, num_records, path, created_by_id, created_on, modified_on
+  FROM orgs_export
+ WHERE id = $1`
+
+// LoadExport loads an export by ID
+func LoadExport(ctx context.Context, db DBorTx, id ExportID) (*Export, error) {
+	e := &Export{}
+	if err := db.GetContext(ctx, e, sqlSelectExport, id); err != nil {
+		return nil, fmt.Errorf("error loading export id=%d: %w", id, err)
+	}
+	return e, nil
+}
+
+// SetProcessing marks this export as processing
+func (e *Export) SetProcessing(ctx context.Context, db DBorTx) error {
+	e.Status = ExportStatusProcessing
+	e.ModifiedOn = dates.Now()
+
+	_, err := db.ExecContext(ctx, `UPDATE orgs_export SET status = $2, modified_on = $3 WHERE id = $1`, e.ID, e.Status, e.ModifiedOn)
+	if err != nil {
+		return fmt.Errorf("error marking export as processing: %w", err)
+	}
+	return nil
+}
+
+// SetComplete marks this export as complete with the given number of records written to the given storage path
+func (e *Export) SetComplete(ctx context.Context, db DBorTx, numRecords int, path string) error {
+	e.Status = ExportStatusComplete
+	e.NumRecords = numRecords
+	e.Path = null.String(path)
+	e.ModifiedOn = dates.Now()
+
+	_, err := db.ExecContext(ctx, `UPDATE orgs_export SET status = $2, num_records = $3, path = $4, modified_on = $5 WHERE id = $1`, e.ID, e.Status, e.NumRecords, e.Path, e.ModifiedOn)
+	if err != nil {
+		return fmt.Errorf("error marking export as complete: %w", err)
+	}
+	return nil
+}
+
+// SetFailed marks this export as failed
+func (e *Export) SetFailed(ctx context.Context, db DBorTx) error {
+	e.Status = ExportStatusFailed
+	e.ModifiedOn = dates.Now()
+
+	_, err := db.ExecContext(ctx, `UPDATE orgs_export SET status = $2, modified_on = $3 WHERE id = $1`, e.ID, e.Status, e.ModifiedOn)
+	if err != nil {
+		return fmt.Errorf("error marking export as failed: %w", err)
+	}
+	return nil
+}
@@ -3,9 +3,14 @@ package models
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
 
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/shopspring/decimal"
 )
 
 // FieldID is our type for the database field ID
@@ -19,6 +24,8 @@ type Field struct {
 	Name_  string           `json:"name"`
 	Type_  assets.FieldType `json:"field_type"`
 	Proxy_ bool             `json:"is_proxy"`
+
+	Validation_ *FieldValidation `json:"validation"`
 }
 
 // ID returns the ID of this field
@@ -39,6 +46,76 @@ func (f *Field) Type() assets.FieldType { return f.Type_ }
 // Proxy returns whether this is a proxy field, e.g. created_on
 func (f *Field) Proxy() bool { return f.Proxy_ }
 
+// Validation returns the validation rules for values of this field, if any
+func (f *Field) Validation() *FieldValidation { return f.Validation_ }
+
+// Validate checks the given value against this field's validation rules, returning an error describing the first rule
+// it breaks. A nil value means the field is being cleared.
+func (f *Field) Validate(value *flows.Value) error {
+	v := f.Validation_
+	if v == nil {
+		return nil
+	}
+
+	if value == nil || value.Text.Empty() {
+		if v.Required {
+			return fmt.Errorf("a value is required for contact field '%s'", f.Key_)
+		}
+		return nil
+	}
+
+	text := value.Text.Native()
+
+	// values of number and datetime fields with rules must be of that type, and values with a min or max must be numbers
+	if (f.Type_ == assets.FieldTypeNumber || v.Min != nil || v.Max != nil) && value.Number == nil {
+		return fmt.Errorf("'%s' is not a valid number value for contact field '%s'", text, f.Key_)
+	}
+	if f.Type_ == assets.FieldTypeDatetime && value.Datetime == nil {
+		return fmt.Errorf("'%s' is not a valid datetime value for contact field '%s'", text, f.Key_)
+	}
+
+	if v.regex != nil && !v.regex.MatchString(text) {
+		return fmt.Errorf("'%s' doesn't match the required pattern for contact field '%s'", text, f.Key_)
+	}
+	if v.Min != nil && value.Number.Native().LessThan(*v.Min) {
+		return fmt.Errorf("'%s' is less than the minimum of %s for contact field '%s'", text, v.Min, f.Key_)
+	}
+	if v.Max != nil && value.Number.Native().GreaterThan(*v.Max) {
+		return fmt.Errorf("'%s' is greater than the maximum of %s for contact field '%s'", text, v.Max, f.Key_)
+	}
+	if len(v.Allowed) > 0 && !slices.Contains(v.Allowed, text) {
+		return fmt.Errorf("'%s' is not an allowed value for contact field '%s'", text, f.Key_)
+	}
+
+	return nil
+}
+
+// FieldValidation is the optional validation rules for values of a field
+type FieldValidation struct {
+	Required bool             `json:"required,omitempty"`
+	Regex    string           `json:"regex,omitempty"`
+	Min      *decimal.Decimal `json:"min,omitempty"`
+	Max      *decimal.Decimal `json:"max,omitempty"`
+	Allowed  []string         `json:"allowed,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// UnmarshalJSON unmarshals validation rules, compiling the regex which must match the whole value. A regex which
+// doesn't compile is ignored rather than preventing the org's assets from loading.
+func (v *FieldValidation) UnmarshalJSON(d []byte) error {
+	type fieldValidation FieldValidation
+
+	if err := json.Unmarshal(d, (*fieldValidation)(v)); err != nil {
+		return err
+	}
+
+	if v.Regex != "" {
+		v.regex, _ = regexp.Compile(`^(?:` + v.Regex + `)$`)
+	}
+	return nil
+}
+
 // loadFields loads the assets for the passed in db
 func loadFields(ctx context.Context, db *sql.DB, orgID OrgID) ([]assets.Field, error) {
 	rows, err := db.QueryContext(ctx, sqlSelectFieldsByOrg, orgID)
@@ -51,7 +128,7 @@ func loadFields(ctx context.Context, db *sql.DB, orgID OrgID) ([]assets.Field, e
 
 const sqlSelectFieldsByOrg = `
 SELECT ROW_TO_JSON(f) FROM (
-      SELECT id, uuid, key, name, (CASE value_type WHEN 'T' THEN 'text' WHEN 'N' THEN 'number' WHEN 'D' THEN 'datetime' WHEN 'S' THEN 'state' WHEN 'I' THEN 'district' WHEN 'W' THEN 'ward' END) AS field_type, is_proxy
+      SELECT id, uuid, key, name, (CASE value_type WHEN 'T' THEN 'text' WHEN 'N' THEN 'number' WHEN 'D' THEN 'datetime' WHEN 'S' THEN 'state' WHEN 'I' THEN 'district' WHEN 'W' THEN 'ward' END) AS field_type, is_proxy, validation
         FROM contacts_contactfield 
        WHERE org_id = $1 AND is_active = TRUE
     ORDER BY key ASC
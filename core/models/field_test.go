@@ -2,8 +2,12 @@ package models_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/testsuite"
 	"github.com/nyaruka/mailroom/testsuite/testdata"
@@ -34,6 +38,7 @@ func TestFields(t *testing.T) {
 		{*testdata.AgeField, "age", "Age", assets.FieldTypeNumber},
 		{*testdata.CreatedOnField, "created_on", "Created On", assets.FieldTypeDatetime},
 		{*testdata.LastSeenOnField, "last_seen_on", "Last Seen On", assets.FieldTypeDatetime},
+		{*testdata.GroupJoinedOnField, "group_joined_on", "Group Joined On", assets.FieldTypeDatetime},
 	}
 	for _, tc := range expectedFields {
 		field := oa.FieldByUUID(tc.field.UUID)
@@ -48,3 +53,44 @@ func TestFields(t *testing.T) {
 		assert.Equal(t, tc.valueType, field.Type())
 	}
 }
+
+func TestFieldValidation(t *testing.T) {
+	newValue := func(text string, number *types.XNumber, datetime *types.XDateTime) *flows.Value {
+		return flows.NewValue(types.NewXText(text), datetime, number, "", "", "")
+	}
+	newField := func(j string) *models.Field {
+		f := &models.Field{}
+		jsonx.MustUnmarshal([]byte(j), f)
+		return f
+	}
+	now := types.NewXDateTime(time.Date(2025, 1, 2, 12, 30, 0, 0, time.UTC))
+
+	// fields without rules accept anything
+	field := newField(`{"key": "age", "field_type": "number", "validation": null}`)
+	assert.Nil(t, field.Validation())
+	assert.NoError(t, field.Validate(newValue("old", nil, nil)))
+
+	field = newField(`{"key": "age", "field_type": "number", "validation": {"required": true, "min": 18, "max": 120}}`)
+	assert.NoError(t, field.Validate(newValue("18", types.NewXNumberFromInt(18), nil)))
+	assert.EqualError(t, field.Validate(nil), "a value is required for contact field 'age'")
+	assert.EqualError(t, field.Validate(newValue("old", nil, nil)), "'old' is not a valid number value for contact field 'age'")
+	assert.EqualError(t, field.Validate(newValue("12", types.NewXNumberFromInt(12), nil)), "'12' is less than the minimum of 18 for contact field 'age'")
+	assert.EqualError(t, field.Validate(newValue("150", types.NewXNumberFromInt(150), nil)), "'150' is greater than the maximum of 120 for contact field 'age'")
+
+	field = newField(`{"key": "code", "field_type": "text", "validation": {"regex": "[A-Z]{3}\\d*"}}`)
+	assert.NoError(t, field.Validate(nil))
+	assert.NoError(t, field.Validate(newValue("ABC123", nil, nil)))
+	assert.EqualError(t, field.Validate(newValue("xABC123", nil, nil)), "'xABC123' doesn't match the required pattern for contact field 'code'")
+
+	field = newField(`{"key": "gender", "field_type": "text", "validation": {"allowed": ["F", "M"]}}`)
+	assert.NoError(t, field.Validate(newValue("F", nil, nil)))
+	assert.EqualError(t, field.Validate(newValue("f", nil, nil)), "'f' is not an allowed value for contact field 'gender'")
+
+	field = newField(`{"key": "joined", "field_type": "datetime", "validation": {"required": true}}`)
+	assert.NoError(t, field.Validate(newValue("2025-01-02 12:30", nil, now)))
+	assert.EqualError(t, field.Validate(newValue("soon", nil, nil)), "'soon' is not a valid datetime value for contact field 'joined'")
+
+	// an invalid regex is ignored
+	field = newField(`{"key": "code", "field_type": "text", "validation": {"regex": "[A-Z"}}`)
+	assert.NoError(t, field.Validate(newValue("123", nil, nil)))
+}
@@ -82,6 +82,15 @@ func (i *ContactImport) SetFinished(ctx context.Context, db DBorTx, status Conta
 	return nil
 }
 
+// SetValidation records the result of validating this import without performing it
+func (i *ContactImport) SetValidation(ctx context.Context, db DBorTx, validation any) error {
+	_, err := db.ExecContext(ctx, `UPDATE contacts_contactimport SET validation = $2 WHERE id = $1`, i.ID, jsonx.MustMarshal(validation))
+	if err != nil {
+		return fmt.Errorf("error recording import validation: %w", err)
+	}
+	return nil
+}
+
 // ContactImportBatch is a batch of contacts within a larger import
 type ContactImportBatch struct {
 	ID       ContactImportBatchID `db:"id"`
@@ -165,6 +174,21 @@ func LoadContactImportBatch(ctx context.Context, db DBorTx, id ContactImportBatc
 	return b, nil
 }
 
+var sqlLoadContactImportBatches = `
+  SELECT id, contact_import_id, status, specs, record_start, record_end
+    FROM contacts_contactimportbatch
+   WHERE contact_import_id = $1
+ORDER BY record_start`
+
+// LoadContactImportBatches loads all the batches of the given contact import in record order
+func LoadContactImportBatches(ctx context.Context, db DBorTx, importID ContactImportID) ([]*ContactImportBatch, error) {
+	batches := make([]*ContactImportBatch, 0, 10)
+	if err := db.SelectContext(ctx, &batches, sqlLoadContactImportBatches, importID); err != nil {
+		return nil, fmt.Errorf("error loading batches for contact import id=%d: %w", importID, err)
+	}
+	return batches, nil
+}
+
 // ContactSpec describes a contact to be updated or created
 type ContactSpec struct {
 	UUID     flows.ContactUUID   `json:"uuid"`
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+)
+
+const (
+	configLanguageDetection = "language_detection"
+
+	defaultLanguageDetectionMinConfidence = 0.8
+)
+
+// LanguageDetection is an org's configuration for detecting the language of incoming messages
+//
+//	{
+//	  "detector": "llm",
+//	  "llm_uuid": "1c06c884-39dd-4ce4-8a9a-bbb2c3c1b7f1",
+//	  "min_confidence": 0.9
+//	}
+type LanguageDetection struct {
+	Detector      string         `json:"detector"`
+	LLMUUID       assets.LLMUUID `json:"llm_uuid,omitempty"`
+	MinConfidence float64        `json:"min_confidence,omitempty"`
+}
+
+// LanguageDetection returns the language detection config for this org or nil if it doesn't have one
+func (o *Org) LanguageDetection() *LanguageDetection {
+	v, ok := o.o.Config[configLanguageDetection]
+	if !ok || v == nil {
+		return nil
+	}
+
+	config := &LanguageDetection{}
+	if err := json.Unmarshal(jsonx.MustMarshal(v), config); err != nil || config.Detector == "" {
+		return nil
+	}
+	if config.MinConfidence <= 0 {
+		config.MinConfidence = defaultLanguageDetectionMinConfidence
+	}
+	return config
+}
+
+// DetectedLanguage is the language detected in the text of a message
+type DetectedLanguage struct {
+	Language   i18n.Language `json:"language"`
+	Confidence float64       `json:"confidence"`
+}
+
+// UpdateMsgDetectedLanguage records the detected language of an incoming message in its metadata (which is stored as
+// text, so has to be cast to JSONB and back to merge into)
+func UpdateMsgDetectedLanguage(ctx context.Context, db DBorTx, msgID MsgID, detected *DetectedLanguage) error {
+	metadata := jsonx.MustMarshal(map[string]any{"detected_language": detected})
+
+	_, err := db.ExecContext(ctx, `UPDATE msgs_msg SET metadata = (COALESCE(metadata, '{}')::jsonb || $2::jsonb)::text WHERE id = $1`, msgID, string(metadata))
+	if err != nil {
+		return fmt.Errorf("error updating detected language of msg #%d: %w", msgID, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/utils"
+)
+
+const configModeration = "moderation"
+
+// ModerationPolicy is an org's configuration for moderating incoming messages before they're handled
+//
+//	{
+//	  "words": ["idiot", "hurt myself"],
+//	  "llm_uuid": "1c06c884-39dd-4ce4-8a9a-bbb2c3c1b7f1",
+//	  "label_uuid": "a2f7a2d4-0b4e-4d0b-94bd-1b8a36e8a1a8",
+//	  "flow_uuid": "5f1a7a3c-65b0-4b6a-9c2e-0c3a9d0a4e7e",
+//	  "topic_uuid": "0a0d7a58-1b4f-4c58-b9e6-7d0e5a1e3a33"
+//	}
+type ModerationPolicy struct {
+	Words     []string         `json:"words"`
+	LLMUUID   assets.LLMUUID   `json:"llm_uuid,omitempty"`
+	LabelUUID assets.LabelUUID `json:"label_uuid,omitempty"`
+	FlowUUID  assets.FlowUUID  `json:"flow_uuid,omitempty"`
+	TopicUUID assets.TopicUUID `json:"topic_uuid,omitempty"`
+}
+
+// ModerationPolicy returns the moderation policy for this org or nil if it doesn't have one
+func (o *Org) ModerationPolicy() *ModerationPolicy {
+	v, ok := o.o.Config[configModeration]
+	if !ok || v == nil {
+		return nil
+	}
+
+	policy := &ModerationPolicy{}
+	if err := json.Unmarshal(jsonx.MustMarshal(v), policy); err != nil {
+		return nil
+	}
+	return policy
+}
+
+// MatchWord returns the first word or phrase from the policy's word list found in the given text, ignoring case and
+// accents, or empty string if none are found
+func (p *ModerationPolicy) MatchWord(text string) string {
+	words := utils.TokenizeString(unaccent(text))
+
+	for _, phrase := range p.Words {
+		tokens := utils.TokenizeString(unaccent(phrase))
+		if len(tokens) > 0 && containsTokens(words, tokens) {
+			return phrase
+		}
+	}
+	return ""
+}
+
+// checks whether the given sequence of tokens appears in order and contiguously in words
+func containsTokens(words, tokens []string) bool {
+	for i := 0; i+len(tokens) <= len(words); i++ {
+		if strings.Join(words[i:i+len(tokens)], " ") == strings.Join(tokens, " ") {
+			return true
+		}
+	}
+	return false
+}
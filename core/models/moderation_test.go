@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationPolicy(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+	assert.Nil(t, oa.Org().ModerationPolicy())
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"moderation": {"words": ["idiot", "hurt myself"], "label_uuid": "ebc4dedc-91c4-4ed4-9dd6-daa05ea82698"}}' WHERE id = $1`, testdata.Org1.ID)
+
+	oa, err = models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	policy := oa.Org().ModerationPolicy()
+	assert.Equal(t, &models.ModerationPolicy{Words: []string{"idiot", "hurt myself"}, LabelUUID: testdata.ReportingLabel.UUID}, policy)
+
+	tcs := []struct {
+		text     string
+		expected string
+	}{
+		{"", ""},
+		{"hello there", ""},
+		{"you IDIOT!", "idiot"},
+		{"idiots", ""},
+		{"I want to hurt myself", "hurt myself"},
+		{"I want to hurt   Myself.", "hurt myself"},
+		{"myself hurt", ""},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.expected, policy.MatchWord(tc.text), "match mismatch for '%s'", tc.text)
+	}
+}
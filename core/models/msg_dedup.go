@@ -0,0 +1,72 @@
+package models
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/gocommon/urns"
+)
+
+// MsgDedupBy is how incoming messages on a channel are identified as duplicates
+type MsgDedupBy string
+
+const (
+	MsgDedupByExternalID = MsgDedupBy("external_id")
+	MsgDedupByContent    = MsgDedupBy("content")
+)
+
+// MsgDedupWindow returns the number of seconds within which a redelivered message on this channel is considered a
+// duplicate, or zero if deduplication is disabled
+func (c *Channel) MsgDedupWindow() int {
+	return c.Config().GetInt(ChannelConfigDedupWindow, 0)
+}
+
+// MsgDedupBy returns how redelivered messages are identified on this channel
+func (c *Channel) MsgDedupBy() MsgDedupBy {
+	return MsgDedupBy(c.Config().GetString(ChannelConfigDedupBy, string(MsgDedupByExternalID)))
+}
+
+var msgDedupScript = redis.NewScript(1, `
+local key, msg_id, window = KEYS[1], ARGV[1], tonumber(ARGV[2])
+
+local owner = redis.call("GET", key)
+if owner then
+	return owner
+end
+
+redis.call("SET", key, msg_id, "EX", window)
+return msg_id
+`)
+
+// IsDuplicateMsgIn checks whether an equivalent incoming message has already been received on the given channel within
+// its deduplication window, and if not, records this message so that later redeliveries are caught. The message which
+// was recorded first owns the dedup key, so retrying the task for that same message doesn't flag it as a duplicate.
+func IsDuplicateMsgIn(rc redis.Conn, ch *Channel, msgID MsgID, urn urns.URN, externalID, text string, attachments []string) (bool, error) {
+	window := ch.MsgDedupWindow()
+	if window <= 0 {
+		return false, nil
+	}
+
+	var id string
+	switch ch.MsgDedupBy() {
+	case MsgDedupByContent:
+		hash := sha1.Sum([]byte(strings.Join(append([]string{urn.Identity().String(), text}, attachments...), "\n")))
+		id = hex.EncodeToString(hash[:])
+	default:
+		if externalID == "" {
+			return false, nil // nothing to dedup on
+		}
+		id = externalID
+	}
+
+	key := fmt.Sprintf("msg_dedup:%s:%s", ch.UUID(), id)
+	owner, err := redis.String(msgDedupScript.Do(rc, key, fmt.Sprint(msgID), window))
+	if err != nil {
+		return false, fmt.Errorf("error recording incoming message for deduplication: %w", err)
+	}
+
+	return owner != fmt.Sprint(msgID), nil
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDuplicateMsgIn(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	rt.DB.MustExec(`UPDATE channels_channel SET config = config || '{"dedup_window": 60}' WHERE id = $1`, testdata.TwilioChannel.ID)
+	rt.DB.MustExec(`UPDATE channels_channel SET config = config || '{"dedup_window": 60, "dedup_by": "content"}' WHERE id = $1`, testdata.VonageChannel.ID)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshChannels)
+	require.NoError(t, err)
+
+	twilio := oa.ChannelByID(testdata.TwilioChannel.ID)
+	vonage := oa.ChannelByID(testdata.VonageChannel.ID)
+	facebook := oa.ChannelByID(testdata.FacebookChannel.ID)
+
+	assertDuplicate := func(ch *models.Channel, msgID models.MsgID, contact *testdata.Contact, extID, text string, expected bool) {
+		dupe, err := models.IsDuplicateMsgIn(rc, ch, msgID, contact.URN, extID, text, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, dupe, "duplicate mismatch for channel %s, ext id %s, text %s", ch.Name(), extID, text)
+	}
+
+	// deduplicating by external id
+	assertDuplicate(twilio, 1001, testdata.Cathy, "EX123", "hi", false)
+	assertDuplicate(twilio, 1001, testdata.Cathy, "EX123", "hi", false) // same message being retried
+	assertDuplicate(twilio, 1002, testdata.Cathy, "EX123", "hi", true)
+	assertDuplicate(twilio, 1003, testdata.Cathy, "EX234", "hi", false)
+	assertDuplicate(twilio, 1004, testdata.Cathy, "", "hi", false) // no external id
+	assertDuplicate(twilio, 1005, testdata.Cathy, "", "hi", false)
+
+	// deduplicating by content
+	assertDuplicate(vonage, 1006, testdata.Cathy, "", "hi", false)
+	assertDuplicate(vonage, 1006, testdata.Cathy, "", "hi", false)
+	assertDuplicate(vonage, 1007, testdata.Cathy, "EX456", "hi", true)
+	assertDuplicate(vonage, 1008, testdata.Bob, "", "hi", false) // different contact
+	assertDuplicate(vonage, 1009, testdata.Cathy, "", "hello", false)
+
+	// deduplication disabled
+	assertDuplicate(facebook, 1010, testdata.Cathy, "EX123", "hi", false)
+	assertDuplicate(facebook, 1011, testdata.Cathy, "EX123", "hi", false)
+}
@@ -20,6 +20,11 @@ const (
 	NotificationTypeIncidentStarted NotificationType = "incident:started"
 	NotificationTypeTicketsOpened   NotificationType = "tickets:opened"
 	NotificationTypeTicketsActivity NotificationType = "tickets:activity"
+
+	NotificationTypeTicketsResponseBreached   NotificationType = "tickets:response_breached"
+	NotificationTypeTicketsResolutionBreached NotificationType = "tickets:resolution_breached"
+
+	NotificationTypeContactRateLimited NotificationType = "contact:rate_limited"
 )
 
 type EmailStatus string
@@ -47,9 +52,25 @@ type Notification struct {
 	CreatedOn   time.Time        `db:"created_on"`
 
 	ContactImportID ContactImportID `db:"contact_import_id"`
+	ExportID        ExportID        `db:"export_id"`
 	IncidentID      IncidentID      `db:"incident_id"`
 }
 
+// NotifyExportFinished notifies the user who created an export that it has finished
+func NotifyExportFinished(ctx context.Context, db DBorTx, e *Export) error {
+	n := &Notification{
+		OrgID:       e.OrgID,
+		Type:        NotificationTypeExportFinished,
+		Scope:       fmt.Sprintf("%s:%d", e.ExportType, e.ID),
+		UserID:      e.CreatedByID,
+		Medium:      MediumUI,
+		EmailStatus: EmailStatusNone,
+		ExportID:    e.ID,
+	}
+
+	return insertNotifications(ctx, db, []*Notification{n})
+}
+
 // NotifyImportFinished notifies the user who created an import that it has finished
 func NotifyImportFinished(ctx context.Context, db DBorTx, imp *ContactImport) error {
 	n := &Notification{
@@ -147,9 +168,62 @@ func NotificationsFromTicketEvents(ctx context.Context, db DBorTx, oa *OrgAssets
 	return insertNotifications(ctx, db, notifications)
 }
 
+// NotifyTicketSLABreaches notifies ticket assignees and administrators of SLA breaches
+func NotifyTicketSLABreaches(ctx context.Context, db DBorTx, oa *OrgAssets, breaches []*TicketSLABreach) error {
+	admins := usersWithRoles(oa, []UserRole{UserRoleAdministrator})
+	notifications := make([]*Notification, 0, len(breaches)*(len(admins)+1))
+
+	for _, b := range breaches {
+		notifyType := NotificationTypeTicketsResponseBreached
+		if b.Type == TicketSLATypeResolution {
+			notifyType = NotificationTypeTicketsResolutionBreached
+		}
+
+		userIDs := make(map[UserID]bool, len(admins)+1)
+		if b.AssigneeID != NilUserID {
+			userIDs[b.AssigneeID] = true
+		}
+		for _, u := range admins {
+			userIDs[u.ID()] = true
+		}
+
+		for userID := range userIDs {
+			notifications = append(notifications, &Notification{
+				OrgID:       oa.OrgID(),
+				Type:        notifyType,
+				Scope:       string(b.TicketUUID),
+				UserID:      userID,
+				Medium:      MediumUI,
+				EmailStatus: EmailStatusNone,
+			})
+		}
+	}
+
+	return insertNotifications(ctx, db, notifications)
+}
+
+// NotifyContactRateLimited notifies administrators that a contact has been blocked for exceeding the inbound rate limit
+func NotifyContactRateLimited(ctx context.Context, db DBorTx, oa *OrgAssets, contact *Contact) error {
+	admins := usersWithRoles(oa, []UserRole{UserRoleAdministrator})
+	notifications := make([]*Notification, len(admins))
+
+	for i, admin := range admins {
+		notifications[i] = &Notification{
+			OrgID:       oa.OrgID(),
+			Type:        NotificationTypeContactRateLimited,
+			Scope:       string(contact.UUID()),
+			UserID:      admin.ID(),
+			Medium:      MediumUI,
+			EmailStatus: EmailStatusNone,
+		}
+	}
+
+	return insertNotifications(ctx, db, notifications)
+}
+
 const insertNotificationSQL = `
-INSERT INTO notifications_notification(org_id,  notification_type,  scope,  user_id,  medium, is_seen,  email_status, created_on,  contact_import_id,  incident_id) 
-                               VALUES(:org_id, :notification_type, :scope, :user_id, :medium,   FALSE, :email_status,      NOW(), :contact_import_id, :incident_id) 
+INSERT INTO notifications_notification(org_id,  notification_type,  scope,  user_id,  medium, is_seen,  email_status, created_on,  contact_import_id,  export_id,  incident_id) 
+                               VALUES(:org_id, :notification_type, :scope, :user_id, :medium,   FALSE, :email_status,      NOW(), :contact_import_id, :export_id, :incident_id) 
 							   ON CONFLICT DO NOTHING`
 
 func insertNotifications(ctx context.Context, db DBorTx, notifications []*Notification) error {
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+)
+
+// LineType is the type of line a phone number is on. Which types can be reported depends on the lookup service, and
+// services which can't classify a number report it as unknown.
+type LineType string
+
+// line type constants
+const (
+	LineTypeMobile   LineType = "mobile"
+	LineTypeLandline LineType = "landline"
+	LineTypeVoIP     LineType = "voip"
+	LineTypeUnknown  LineType = "unknown"
+)
+
+const configNumberLookup = "number_lookup"
+
+// LineTypeFieldKey is the key of the contact field which, if an org has one, is set to the line type of each contact's
+// looked up tel URN so that it can be used in contact queries, e.g. line_type = mobile. Note that queries which exclude
+// a line type, e.g. line_type != landline, only work with services that can report that type.
+const LineTypeFieldKey = "line_type"
+
+// NumberLookup is what a number lookup service knows about a phone number
+type NumberLookup struct {
+	Valid    bool         `json:"valid"`
+	Country  i18n.Country `json:"country,omitempty"`
+	Carrier  string       `json:"carrier,omitempty"`
+	LineType LineType     `json:"line_type"`
+}
+
+// NumberLookupService is a service which can look up the carrier, country and line type of a phone number
+type NumberLookupService interface {
+	LookupNumber(ctx context.Context, number string) (*NumberLookup, error)
+}
+
+var registeredNumberLookupServices = map[string]func(*Org, *http.Client, *httpx.RetryConfig) (NumberLookupService, error){}
+
+// RegisterNumberLookupService registers a number lookup service for the given type code
+func RegisterNumberLookupService(typ string, fn func(*Org, *http.Client, *httpx.RetryConfig) (NumberLookupService, error)) {
+	registeredNumberLookupServices[typ] = fn
+}
+
+// HasNumberLookup returns whether this org has a number lookup service configured
+func (o *Org) HasNumberLookup() bool {
+	return o.ConfigValue(configNumberLookup, "") != ""
+}
+
+// NumberLookupService returns the number lookup service for this org, or nil if one isn't configured
+func (o *Org) NumberLookupService(httpClient *http.Client, httpRetries *httpx.RetryConfig) (NumberLookupService, error) {
+	typ := o.ConfigValue(configNumberLookup, "")
+	if typ == "" {
+		return nil, nil
+	}
+
+	fn := registeredNumberLookupServices[typ]
+	if fn == nil {
+		return nil, fmt.Errorf("unknown number lookup type '%s' for org: %d", typ, o.ID())
+	}
+	return fn(o, httpClient, httpRetries)
+}
+
+// URNForLookup is a tel URN which hasn't been looked up yet
+type URNForLookup struct {
+	ID        URNID     `db:"id"`
+	ContactID ContactID `db:"contact_id"`
+	Path      string    `db:"path"`
+}
+
+const sqlSelectURNsForLookup = `
+  SELECT id, contact_id, path
+    FROM contacts_contacturn
+   WHERE org_id = $1 AND contact_id = ANY($2) AND scheme = 'tel' AND metadata IS NULL
+ORDER BY contact_id, priority DESC, id`
+
+// LoadURNsForLookup loads the tel URNs of the given contacts which haven't been looked up yet, highest priority first
+func LoadURNsForLookup(ctx context.Context, db DBorTx, orgID OrgID, contactIDs []ContactID) ([]*URNForLookup, error) {
+	urnz := make([]*URNForLookup, 0, len(contactIDs))
+
+	if err := db.SelectContext(ctx, &urnz, sqlSelectURNsForLookup, orgID, pq.Array(contactIDs)); err != nil {
+		return nil, fmt.Errorf("error loading URNs for lookup: %w", err)
+	}
+
+	return urnz, nil
+}
+
+// UpdateURNLookup records the result of looking up the given URN
+func UpdateURNLookup(ctx context.Context, db DBorTx, urnID URNID, lookup *NumberLookup) error {
+	if _, err := db.ExecContext(ctx, `UPDATE contacts_contacturn SET metadata = $2 WHERE id = $1`, urnID, jsonx.MustMarshal(lookup)); err != nil {
+		return fmt.Errorf("error updating URN lookup: %w", err)
+	}
+	return nil
+}
+
+// HasTelURN returns whether any of the given URNs are tel URNs
+func HasTelURN(urnz []urns.URN) bool {
+	for _, u := range urnz {
+		if u.Scheme() == urns.Phone.Prefix {
+			return true
+		}
+	}
+	return false
+}
@@ -139,6 +139,27 @@ func (o *Org) ConfigValue(key string, def string) string {
 	return def
 }
 
+// ConfigInt returns the int value for the passed in config (or default if not found or not a number)
+func (o *Org) ConfigInt(key string, def int) int {
+	v, ok := o.o.Config[key].(float64)
+	if ok {
+		return int(v)
+	}
+	return def
+}
+
+// ConfigStrings returns the string list value for the passed in config (or nil if not found or not a list)
+func (o *Org) ConfigStrings(key string) []string {
+	vs, _ := o.o.Config[key].([]any)
+	ss := make([]string, 0, len(vs))
+	for _, v := range vs {
+		if s, ok := v.(string); ok {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
 // EmailService returns the email service for this org
 func (o *Org) EmailService(ctx context.Context, rt *runtime.Runtime, retries *smtpx.RetryConfig) (flows.EmailService, error) {
 	// first look for custom SMTP on this org
@@ -216,7 +216,7 @@ func (s *Schedule) GetNextFire(now time.Time) (*time.Time, error) {
 
 	case RepeatPeriodDaily:
 		for !next.After(now) {
-			next = next.AddDate(0, 0, 1)
+			next = nextDay(next, hour, minute)
 		}
 		return &next, nil
 
@@ -233,7 +233,7 @@ func (s *Schedule) GetNextFire(now time.Time) (*time.Time, error) {
 
 		// until we are in the future, increment a day until we reach a day of week we send on
 		for !next.After(now) || !slices.Contains(sendDays, next.Weekday()) {
-			next = next.AddDate(0, 0, 1)
+			next = nextDay(next, hour, minute)
 		}
 
 		return &next, nil
@@ -275,6 +275,71 @@ func (s *Schedule) GetNextFire(now time.Time) (*time.Time, error) {
 	}
 }
 
+// returns the given hour and minute on the day after t. We don't use AddDate as that preserves the clock time of t
+// which may have been shifted because the hour and minute didn't exist on that day due to a DST change.
+func nextDay(t time.Time, hour, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, hour, minute, 0, 0, t.Location())
+}
+
+// ScheduleFire is a projected future fire of a schedule
+type ScheduleFire struct {
+	Time  time.Time `json:"time"`
+	Notes []string  `json:"notes,omitempty"`
+}
+
+// GetNextFires returns up to count fires of this schedule starting with its next fire, using the same calculation
+// as when schedules are actually fired, and noting anything about each fire that users might find surprising.
+func (s *Schedule) GetNextFires(count int) ([]*ScheduleFire, error) {
+	fires := make([]*ScheduleFire, 0, count)
+	if s.NextFire == nil || count <= 0 {
+		return fires, nil
+	}
+
+	tz, err := s.GetTimezone()
+	if err != nil {
+		return nil, fmt.Errorf("error loading timezone: %w", err)
+	}
+
+	next := s.NextFire
+	for len(fires) < count && next != nil {
+		t := next.In(tz)
+		fire := &ScheduleFire{Time: t}
+
+		if len(fires) > 0 {
+			prev := fires[len(fires)-1].Time
+			_, prevOffset := prev.Zone()
+			name, offset := t.Zone()
+			if offset != prevOffset {
+				fire.Notes = append(fire.Notes, fmt.Sprintf("UTC offset changes from %s to %s (%s)", formatUTCOffset(prevOffset), formatUTCOffset(offset), name))
+			}
+		}
+		if s.RepeatHourOfDay != nil && s.RepeatMinuteOfHour != nil && (t.Hour() != *s.RepeatHourOfDay || t.Minute() != *s.RepeatMinuteOfHour) {
+			fire.Notes = append(fire.Notes, fmt.Sprintf("%02d:%02d doesn't exist on this day because of a daylight saving change", *s.RepeatHourOfDay, *s.RepeatMinuteOfHour))
+		}
+		if s.RepeatPeriod == RepeatPeriodMonthly && s.RepeatDayOfMonth != nil && *s.RepeatDayOfMonth > t.Day() {
+			fire.Notes = append(fire.Notes, fmt.Sprintf("month has no day %d so fires on the last day of the month", *s.RepeatDayOfMonth))
+		}
+
+		fires = append(fires, fire)
+
+		if next, err = s.GetNextFire(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return fires, nil
+}
+
+// formats a UTC offset in seconds as +HH:MM
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, (offset%3600)/60)
+}
+
 // returns number of days in the month for the passed in date using crazy golang date magic
 func daysInMonth(t time.Time) int {
 	// day 0 of a month is previous day of previous month, months can be > 12 and roll years
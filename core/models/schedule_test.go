@@ -221,6 +221,16 @@ func TestGetNextFire(t *testing.T) {
 				time.Date(2019, 11, 4, 12, 30, 0, 0, la),
 			},
 		},
+		{
+			Label:    "daily repeat at time which doesn't exist on DST start",
+			Now:      time.Date(2019, 3, 9, 2, 30, 0, 0, la),
+			Timezone: "America/Los_Angeles",
+			Schedule: []byte(`{"repeat_period": "D", "repeat_hour_of_day": 2, "repeat_minute_of_hour": 30}`),
+			ExpectedNexts: []time.Time{
+				time.Date(2019, 3, 10, 1, 30, 0, 0, la),
+				time.Date(2019, 3, 11, 2, 30, 0, 0, la),
+			},
+		},
 		{
 			Label:         "weekly repeat missing days of week",
 			Now:           time.Date(2019, 8, 20, 13, 57, 0, 0, la),
@@ -388,3 +398,50 @@ func TestGetNextFire(t *testing.T) {
 		}
 	}
 }
+
+func TestGetNextFires(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	// one-off schedules fire once
+	sched := &models.Schedule{RepeatPeriod: models.RepeatPeriodNever, Timezone: "America/Los_Angeles"}
+	sched.NextFire = &[]time.Time{time.Date(2019, 8, 20, 12, 35, 0, 0, la)}[0]
+
+	fires, err := sched.GetNextFires(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ScheduleFire{{Time: time.Date(2019, 8, 20, 12, 35, 0, 0, la)}}, fires)
+
+	// monthly schedules on the 31st get clamped to the last day of shorter months
+	sched = &models.Schedule{}
+	jsonx.MustUnmarshal([]byte(`{"repeat_period": "M", "repeat_day_of_month": 31, "repeat_hour_of_day": 9, "repeat_minute_of_hour": 0, "next_fire": "2018-10-31T16:00:00Z", "timezone": "America/Los_Angeles"}`), sched)
+
+	fires, err = sched.GetNextFires(6)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ScheduleFire{
+		{Time: time.Date(2018, 10, 31, 9, 0, 0, 0, la)},
+		{Time: time.Date(2018, 11, 30, 9, 0, 0, 0, la), Notes: []string{"UTC offset changes from -07:00 to -08:00 (PST)", "month has no day 31 so fires on the last day of the month"}},
+		{Time: time.Date(2018, 12, 31, 9, 0, 0, 0, la)},
+		{Time: time.Date(2019, 1, 31, 9, 0, 0, 0, la)},
+		{Time: time.Date(2019, 2, 28, 9, 0, 0, 0, la), Notes: []string{"month has no day 31 so fires on the last day of the month"}},
+		{Time: time.Date(2019, 3, 31, 9, 0, 0, 0, la), Notes: []string{"UTC offset changes from -08:00 to -07:00 (PDT)"}},
+	}, fires)
+
+	// daily schedules at a time which doesn't exist on the day clocks go forward
+	sched = &models.Schedule{}
+	jsonx.MustUnmarshal([]byte(`{"repeat_period": "D", "repeat_hour_of_day": 2, "repeat_minute_of_hour": 30, "next_fire": "2019-03-09T10:30:00Z", "timezone": "America/Los_Angeles"}`), sched)
+
+	fires, err = sched.GetNextFires(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ScheduleFire{
+		{Time: time.Date(2019, 3, 9, 2, 30, 0, 0, la)},
+		{Time: time.Date(2019, 3, 10, 1, 30, 0, 0, la), Notes: []string{"02:30 doesn't exist on this day because of a daylight saving change"}},
+		{Time: time.Date(2019, 3, 11, 2, 30, 0, 0, la), Notes: []string{"UTC offset changes from -08:00 to -07:00 (PDT)"}},
+	}, fires)
+
+	// invalid schedules error
+	sched = &models.Schedule{}
+	jsonx.MustUnmarshal([]byte(`{"repeat_period": "W", "repeat_hour_of_day": 2, "repeat_minute_of_hour": 30, "next_fire": "2019-03-09T10:30:00Z", "timezone": "America/Los_Angeles"}`), sched)
+
+	_, err = sched.GetNextFires(2)
+	assert.EqualError(t, err, "repeats weekly but has no repeat_days_of_week")
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/gomodule/redigo/redis"
 	"github.com/jmoiron/sqlx"
@@ -97,15 +99,16 @@ func (s *Session) StoragePath(orgID OrgID) string {
 
 	// example output: orgs/1/c/20a5/20a5534c-b2ad-4f18-973a-f1aa3b4e6c74/20060102T150405.123Z_session_8a7fc501-177b-4567-a0aa-81c48e6de1c5_51df83ac21d3cf136d8341f0b11cb1a7.json"
 	return path.Join(
-		"orgs",
-		fmt.Sprintf("%d", orgID),
-		"c",
-		string(s.ContactUUID()[:4]),
-		string(s.ContactUUID()),
+		contactStoragePath(orgID, s.ContactUUID()),
 		fmt.Sprintf("%s_session_%s_%s.json", ts, s.UUID(), s.OutputMD5()),
 	)
 }
 
+// returns the path under which all of a contact's session outputs are stored
+func contactStoragePath(orgID OrgID, contactUUID flows.ContactUUID) string {
+	return path.Join("orgs", fmt.Sprintf("%d", orgID), "c", string(contactUUID[:4]), string(contactUUID))
+}
+
 // ContactUUID returns the UUID of our contact
 func (s *Session) ContactUUID() flows.ContactUUID {
 	return s.contact.UUID()
@@ -612,6 +615,41 @@ func GetWaitingSessionForContact(ctx context.Context, rt *runtime.Runtime, oa *O
 	return session, nil
 }
 
+// DeleteSessionOutputsFromStorage deletes all session outputs for the given contact from our storage (S3)
+func DeleteSessionOutputsFromStorage(ctx context.Context, rt *runtime.Runtime, orgID OrgID, contactUUID flows.ContactUUID) (int, error) {
+	request := &s3.ListObjectsV2Input{
+		Bucket: aws.String(rt.Config.S3SessionsBucket),
+		Prefix: aws.String(contactStoragePath(orgID, contactUUID) + "/"),
+	}
+	deleted := 0
+
+	for {
+		response, err := rt.S3.Client.ListObjectsV2(ctx, request)
+		if err != nil {
+			return deleted, fmt.Errorf("error listing sessions in storage: %w", err)
+		}
+
+		if len(response.Contents) > 0 {
+			del := &types.Delete{}
+			for _, obj := range response.Contents {
+				del.Objects = append(del.Objects, types.ObjectIdentifier{Key: obj.Key})
+			}
+
+			if _, err := rt.S3.Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{Bucket: request.Bucket, Delete: del}); err != nil {
+				return deleted, fmt.Errorf("error deleting sessions from storage: %w", err)
+			}
+			deleted += len(del.Objects)
+		}
+
+		if !aws.ToBool(response.IsTruncated) {
+			break
+		}
+		request.ContinuationToken = response.NextContinuationToken
+	}
+
+	return deleted, nil
+}
+
 // WriteSessionsToStorage writes the outputs of the passed in sessions to our storage (S3), updating the
 // output_url for each on success. Failure of any will cause all to fail.
 func WriteSessionOutputsToStorage(ctx context.Context, rt *runtime.Runtime, orgID OrgID, sessions []*Session) error {
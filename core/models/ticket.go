@@ -155,7 +155,7 @@ func LoadTickets(ctx context.Context, db *sqlx.DB, ids []TicketID) ([]*Ticket, e
 	return loadTickets(ctx, db, sqlSelectTicketsByID, pq.Array(ids))
 }
 
-func loadTickets(ctx context.Context, db *sqlx.DB, query string, params ...any) ([]*Ticket, error) {
+func loadTickets(ctx context.Context, db sqlx.QueryerContext, query string, params ...any) ([]*Ticket, error) {
 	rows, err := db.QueryxContext(ctx, query, params...)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, fmt.Errorf("error loading tickets: %w", err)
@@ -500,6 +500,10 @@ func ReopenTickets(ctx context.Context, rt *runtime.Runtime, oa *OrgAssets, user
 		return nil, fmt.Errorf("error inserting ticket events: %w", err)
 	}
 
+	if err := autoAssignReopenedTickets(ctx, rt, oa, eventsByTicket); err != nil {
+		return nil, fmt.Errorf("error auto-assigning reopened tickets: %w", err)
+	}
+
 	if err := recalcGroupsForTicketChanges(ctx, rt.DB, oa, contactIDs); err != nil {
 		return nil, fmt.Errorf("error recalculting groups: %w", err)
 	}
@@ -507,6 +511,18 @@ func ReopenTickets(ctx context.Context, rt *runtime.Runtime, oa *OrgAssets, user
 	return eventsByTicket, nil
 }
 
+// applies topic assignment policies to reopened tickets which are unassigned
+func autoAssignReopenedTickets(ctx context.Context, rt *runtime.Runtime, oa *OrgAssets, reopened map[*Ticket]*TicketEvent) error {
+	unassigned := make([]*Ticket, 0, len(reopened))
+	for t := range reopened {
+		if t.AssigneeID() == NilUserID {
+			unassigned = append(unassigned, t)
+		}
+	}
+
+	return AutoReassignTickets(ctx, rt, oa, unassigned)
+}
+
 // because groups can be based on "tickets" need to recalculate after closing/reopening tickets
 func recalcGroupsForTicketChanges(ctx context.Context, db DBorTx, oa *OrgAssets, contactIDs map[ContactID]bool) error {
 	ids := make([]ContactID, 0, len(contactIDs))
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lib/pq"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// TicketAssignmentMode is how a topic automatically assigns tickets to agents
+type TicketAssignmentMode string
+
+const (
+	// TicketAssignmentRoundRobin assigns tickets to each agent in turn
+	TicketAssignmentRoundRobin = TicketAssignmentMode("round_robin")
+
+	// TicketAssignmentLeastOpen assigns tickets to the agent with the fewest open tickets
+	TicketAssignmentLeastOpen = TicketAssignmentMode("least_open")
+)
+
+// TicketAssignmentPolicy is a topic's policy for automatically assigning new and reopened tickets
+type TicketAssignmentPolicy struct {
+	Mode          TicketAssignmentMode `json:"mode"`
+	TeamID        TeamID               `json:"team_id"`
+	AvailableOnly bool                 `json:"available_only"`
+}
+
+type assignableAgent struct {
+	UserID      UserID `db:"user_id"`
+	OpenTickets int    `db:"open_tickets"`
+}
+
+const sqlSelectAssignableAgents = `
+    SELECT m.user_id, (SELECT COUNT(*) FROM tickets_ticket t WHERE t.org_id = m.org_id AND t.assignee_id = m.user_id AND t.status = 'O') AS open_tickets
+      FROM orgs_orgmembership m
+INNER JOIN users_user u ON u.id = m.user_id
+     WHERE m.org_id = $1 AND u.is_active = TRUE AND m.role_code = ANY($2) AND ($3::int IS NULL OR m.team_id = $3) AND (NOT $4 OR m.is_available = TRUE)
+  ORDER BY m.user_id`
+
+// AutoAssignTickets assigns unassigned tickets whose topics have an assignment policy, returning the tickets that were
+// assigned. Tickets are only modified in memory so this should be called before they are inserted or updated.
+func AutoAssignTickets(ctx context.Context, rt *runtime.Runtime, db DBorTx, oa *OrgAssets, tickets []*Ticket) ([]*Ticket, error) {
+	byTopic := make(map[*Topic][]*Ticket)
+	for _, t := range tickets {
+		if t.AssigneeID() == NilUserID {
+			topic := oa.TopicByID(t.TopicID())
+			if topic != nil && topic.Assignment() != nil {
+				byTopic[topic] = append(byTopic[topic], t)
+			}
+		}
+	}
+
+	if len(byTopic) == 0 {
+		return nil, nil
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	assigned := make([]*Ticket, 0, len(tickets))
+
+	for topic, topicTickets := range byTopic {
+		policy := topic.Assignment()
+
+		agents := make([]*assignableAgent, 0, 10)
+		roles := pq.Array([]UserRole{UserRoleAdministrator, UserRoleEditor, UserRoleAgent})
+		if err := db.SelectContext(ctx, &agents, sqlSelectAssignableAgents, oa.OrgID(), roles, policy.TeamID, policy.AvailableOnly); err != nil {
+			return nil, fmt.Errorf("error loading assignable agents for topic #%d: %w", topic.ID(), err)
+		}
+
+		// no one to assign to so leave them unassigned
+		if len(agents) == 0 {
+			continue
+		}
+
+		for _, t := range topicTickets {
+			var assignee *assignableAgent
+
+			if policy.Mode == TicketAssignmentLeastOpen {
+				assignee = agents[0]
+				for _, a := range agents[1:] {
+					if a.OpenTickets < assignee.OpenTickets {
+						assignee = a
+					}
+				}
+			} else {
+				var err error
+				if assignee, err = nextRoundRobinAgent(rc, topic, agents); err != nil {
+					return nil, fmt.Errorf("error getting next round robin agent for topic #%d: %w", topic.ID(), err)
+				}
+			}
+
+			assignee.OpenTickets++
+			t.t.AssigneeID = assignee.UserID
+			assigned = append(assigned, t)
+		}
+	}
+
+	return assigned, nil
+}
+
+// AutoReassignTickets reassigns existing tickets according to their topic assignment policies, recording
+// assignments as regular ticket assigned events by no user
+func AutoReassignTickets(ctx context.Context, rt *runtime.Runtime, oa *OrgAssets, tickets []*Ticket) error {
+	prevAssignees := make(map[*Ticket]UserID, len(tickets))
+	for _, t := range tickets {
+		prevAssignees[t] = t.AssigneeID()
+		t.t.AssigneeID = NilUserID
+	}
+
+	if _, err := AutoAssignTickets(ctx, rt, rt.DB, oa, tickets); err != nil {
+		return err
+	}
+
+	// tickets have only been assigned in memory so restore previous assignees and assign properly
+	byAssignee := make(map[UserID][]*Ticket)
+	for t, prev := range prevAssignees {
+		assigneeID := t.AssigneeID()
+		t.t.AssigneeID = prev
+
+		if assigneeID != NilUserID && assigneeID != prev {
+			byAssignee[assigneeID] = append(byAssignee[assigneeID], t)
+		}
+	}
+
+	for assigneeID, ts := range byAssignee {
+		if _, err := TicketsAssign(ctx, rt.DB, oa, NilUserID, ts, assigneeID); err != nil {
+			return fmt.Errorf("error assigning tickets: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// gets the next agent to assign a ticket for the given topic to, by atomically incrementing a per-topic counter so
+// that concurrent assignments don't get the same agent
+func nextRoundRobinAgent(rc redis.Conn, topic *Topic, agents []*assignableAgent) (*assignableAgent, error) {
+	key := fmt.Sprintf("ticket_assignment:%d:next", topic.ID())
+
+	count, err := redis.Int(rc.Do("INCR", key))
+	if err != nil {
+		return nil, err
+	}
+
+	return agents[(count-1)%len(agents)], nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StaleTicket is an open ticket which has been inactive longer than its auto-close policy allows
+type StaleTicket struct {
+	TicketID TicketID `db:"ticket_id"`
+	OrgID    OrgID    `db:"org_id"`
+	Message  string   `db:"message"`
+}
+
+// auto-close policies are read from the auto_close key of the topic config or the ticket_auto_close key of the org
+// config, and have inactive_days and an optional message. Topic policies take precedence over org policies.
+const sqlSelectStaleTickets = `
+    SELECT t.id AS ticket_id, t.org_id, COALESCE(p.policy->>'message', '') AS message
+      FROM tickets_ticket t
+INNER JOIN tickets_topic tp ON tp.id = t.topic_id
+INNER JOIN orgs_org o ON o.id = t.org_id
+CROSS JOIN LATERAL (SELECT COALESCE(tp.config->'auto_close', o.config->'ticket_auto_close') AS policy) p
+     WHERE t.status = 'O' AND o.is_active = TRUE AND (p.policy->>'inactive_days')::int > 0 AND
+           t.last_activity_on < $1 - make_interval(days => (p.policy->>'inactive_days')::int)
+  ORDER BY t.org_id, t.id
+     LIMIT $2`
+
+// LoadStaleTickets loads up to limit open tickets which should be auto-closed
+func LoadStaleTickets(ctx context.Context, db DBorTx, now time.Time, limit int) ([]*StaleTicket, error) {
+	stale := make([]*StaleTicket, 0, 10)
+	if err := db.SelectContext(ctx, &stale, sqlSelectStaleTickets, now, limit); err != nil {
+		return nil, fmt.Errorf("error loading stale tickets: %w", err)
+	}
+	return stale, nil
+}
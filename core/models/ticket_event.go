@@ -19,6 +19,8 @@ const (
 	TicketEventTypeTopicChanged TicketEventType = "T"
 	TicketEventTypeClosed       TicketEventType = "C"
 	TicketEventTypeReopened     TicketEventType = "R"
+	TicketEventTypeMerged       TicketEventType = "M"
+	TicketEventTypeSplit        TicketEventType = "S"
 )
 
 type TicketEvent struct {
@@ -60,6 +62,14 @@ func NewTicketReopenedEvent(t *Ticket, userID UserID) *TicketEvent {
 	return newTicketEvent(t, userID, TicketEventTypeReopened, "", NilTopicID, NilUserID)
 }
 
+func NewTicketMergedEvent(t *Ticket, userID UserID) *TicketEvent {
+	return newTicketEvent(t, userID, TicketEventTypeMerged, "", NilTopicID, NilUserID)
+}
+
+func NewTicketSplitEvent(t *Ticket, userID UserID) *TicketEvent {
+	return newTicketEvent(t, userID, TicketEventTypeSplit, "", NilTopicID, NilUserID)
+}
+
 func newTicketEvent(t *Ticket, userID UserID, eventType TicketEventType, note string, topicID TopicID, assigneeID UserID) *TicketEvent {
 	event := &TicketEvent{}
 	e := &event.e
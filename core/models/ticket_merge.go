@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/flows"
+)
+
+const sqlMoveTicketEvents = `
+UPDATE tickets_ticketevent
+   SET ticket_id = $2
+ WHERE ticket_id = ANY($1)`
+
+const sqlMoveTicketMsgs = `
+UPDATE msgs_msg
+   SET ticket_id = $2, modified_on = NOW()
+ WHERE ticket_id = ANY($1)`
+
+const sqlCloseMergedTickets = `
+UPDATE tickets_ticket
+   SET status = 'C', modified_on = $2, closed_on = COALESCE(closed_on, $2), last_activity_on = $2
+ WHERE id = ANY($1)`
+
+// MergeTickets folds the given tickets into the target ticket. The events and messages of the merged tickets are moved
+// to the target and the merged tickets are closed with a merged event. All tickets must belong to the same contact.
+func MergeTickets(ctx context.Context, tx *sqlx.Tx, oa *OrgAssets, userID UserID, target *Ticket, tickets []*Ticket) (map[*Ticket]*TicketEvent, error) {
+	ids := make([]TicketID, 0, len(tickets))
+	events := make([]*TicketEvent, 0, len(tickets))
+	eventsByTicket := make(map[*Ticket]*TicketEvent, len(tickets))
+	now := dates.Now()
+
+	for _, ticket := range tickets {
+		if ticket.ContactID() != target.ContactID() {
+			return nil, fmt.Errorf("can't merge ticket %d of contact %d into ticket of contact %d", ticket.ID(), ticket.ContactID(), target.ContactID())
+		}
+		if ticket.ID() == target.ID() {
+			continue
+		}
+
+		ids = append(ids, ticket.ID())
+		t := &ticket.t
+		t.Status = TicketStatusClosed
+		t.ModifiedOn = now
+		if t.ClosedOn == nil {
+			t.ClosedOn = &now
+		}
+		t.LastActivityOn = now
+
+		e := NewTicketMergedEvent(ticket, userID)
+		events = append(events, e)
+		eventsByTicket[ticket] = e
+	}
+
+	if len(ids) == 0 {
+		return eventsByTicket, nil
+	}
+
+	// move existing history (including notes) and messages before recording the merged events on the old tickets
+	if _, err := tx.ExecContext(ctx, sqlMoveTicketEvents, pq.Array(ids), target.ID()); err != nil {
+		return nil, fmt.Errorf("error moving ticket events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, sqlMoveTicketMsgs, pq.Array(ids), target.ID()); err != nil {
+		return nil, fmt.Errorf("error moving ticket messages: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, sqlCloseMergedTickets, pq.Array(ids), now); err != nil {
+		return nil, fmt.Errorf("error closing merged tickets: %w", err)
+	}
+
+	if err := InsertTicketEvents(ctx, tx, events); err != nil {
+		return nil, fmt.Errorf("error inserting ticket events: %w", err)
+	}
+
+	if err := UpdateTicketLastActivity(ctx, tx, []*Ticket{target}); err != nil {
+		return nil, fmt.Errorf("error updating ticket activity: %w", err)
+	}
+
+	if err := recalcGroupsForTicketChanges(ctx, tx, oa, map[ContactID]bool{target.ContactID(): true}); err != nil {
+		return nil, fmt.Errorf("error recalculting groups: %w", err)
+	}
+
+	return eventsByTicket, nil
+}
+
+const sqlMoveSplitMsgs = `
+UPDATE msgs_msg
+   SET ticket_id = $3, modified_on = NOW()
+ WHERE ticket_id = $1 AND id = ANY($2)`
+
+// SplitTicket moves the given messages of a ticket into a new open ticket for the same contact and topic, returning
+// the new ticket. The original ticket gets a split event and the new ticket an opened event.
+func SplitTicket(ctx context.Context, tx *sqlx.Tx, oa *OrgAssets, userID UserID, ticket *Ticket, msgIDs []MsgID) (*Ticket, error) {
+	split := NewTicket(flows.NewTicketUUID(), ticket.OrgID(), userID, NilFlowID, ticket.ContactID(), ticket.TopicID(), ticket.AssigneeID())
+
+	if err := InsertTickets(ctx, tx, oa, []*Ticket{split}); err != nil {
+		return nil, fmt.Errorf("error inserting split ticket: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, sqlMoveSplitMsgs, ticket.ID(), pq.Array(msgIDs), split.ID())
+	if err != nil {
+		return nil, fmt.Errorf("error moving ticket messages: %w", err)
+	}
+	if moved, _ := res.RowsAffected(); moved != int64(len(msgIDs)) {
+		return nil, fmt.Errorf("only %d of %d messages belong to ticket %d: %w", moved, len(msgIDs), ticket.ID(), ErrNotFound)
+	}
+
+	events := []*TicketEvent{
+		NewTicketSplitEvent(ticket, userID),
+		NewTicketOpenedEvent(split, userID, split.AssigneeID(), ""),
+	}
+	if err := InsertTicketEvents(ctx, tx, events); err != nil {
+		return nil, fmt.Errorf("error inserting ticket events: %w", err)
+	}
+
+	if err := UpdateTicketLastActivity(ctx, tx, []*Ticket{ticket}); err != nil {
+		return nil, fmt.Errorf("error updating ticket activity: %w", err)
+	}
+
+	return split, nil
+}
@@ -0,0 +1,97 @@
+package models_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTickets(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	oa, err := models.GetOrgAssets(ctx, rt, testdata.Org1.ID)
+	require.NoError(t, err)
+
+	ticket1 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), testdata.Agent)
+	ticket2 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.SalesTopic, time.Now(), nil)
+	ticket3 := testdata.InsertClosedTicket(rt, testdata.Org1, testdata.Cathy, testdata.SupportTopic, nil)
+	bobTicket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.DefaultTopic, time.Now(), nil)
+
+	msg1 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "hi", models.MsgStatusHandled)
+	msg2 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "hello", models.MsgStatusHandled)
+	rt.DB.MustExec(`UPDATE msgs_msg SET ticket_id = $2 WHERE id = $1`, msg1.ID, ticket2.ID)
+	rt.DB.MustExec(`UPDATE msgs_msg SET ticket_id = $2 WHERE id = $1`, msg2.ID, ticket3.ID)
+
+	_, err = models.TicketsAddNote(ctx, rt.DB, oa, testdata.Admin.ID, []*models.Ticket{ticket2.Load(rt)}, "urgent")
+	require.NoError(t, err)
+
+	// can't merge tickets of different contacts
+	tx := rt.DB.MustBeginTx(ctx, nil)
+	_, err = models.MergeTickets(ctx, tx, oa, testdata.Admin.ID, ticket1.Load(rt), []*models.Ticket{bobTicket.Load(rt)})
+	assert.EqualError(t, err, fmt.Sprintf("can't merge ticket %d of contact 10001 into ticket of contact 10000", bobTicket.ID))
+	require.NoError(t, tx.Rollback())
+
+	modelTicket2, modelTicket3 := ticket2.Load(rt), ticket3.Load(rt)
+
+	tx = rt.DB.MustBeginTx(ctx, nil)
+	evts, err := models.MergeTickets(ctx, tx, oa, testdata.Admin.ID, ticket1.Load(rt), []*models.Ticket{modelTicket2, modelTicket3})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.Equal(t, 2, len(evts))
+	assert.Equal(t, models.TicketEventTypeMerged, evts[modelTicket2].EventType())
+	assert.Equal(t, models.TicketStatusClosed, modelTicket2.Status())
+
+	// merged tickets are closed and their notes and messages now belong to the target ticket
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticket WHERE id = ANY($1) AND status = 'C'`, pq.Array([]models.TicketID{ticket2.ID, ticket3.ID})).Returns(2)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticket WHERE id = $1 AND status = 'O'`, ticket1.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = $1 AND event_type = 'N' AND note = 'urgent'`, ticket1.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = ANY($1) AND event_type = 'M'`, pq.Array([]models.TicketID{ticket2.ID, ticket3.ID})).Returns(2)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE ticket_id = $1`, ticket1.ID).Returns(2)
+}
+
+func TestSplitTicket(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	oa, err := models.GetOrgAssets(ctx, rt, testdata.Org1.ID)
+	require.NoError(t, err)
+
+	ticket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.SalesTopic, time.Now(), testdata.Agent)
+
+	msg1 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "hi", models.MsgStatusHandled)
+	msg2 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "hello", models.MsgStatusHandled)
+	msg3 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "other", models.MsgStatusHandled)
+	rt.DB.MustExec(`UPDATE msgs_msg SET ticket_id = $2 WHERE id = ANY($1)`, pq.Array([]models.MsgID{msg1.ID, msg2.ID}), ticket.ID)
+
+	// can't split off messages which don't belong to the ticket
+	tx := rt.DB.MustBeginTx(ctx, nil)
+	_, err = models.SplitTicket(ctx, tx, oa, testdata.Admin.ID, ticket.Load(rt), []models.MsgID{msg2.ID, msg3.ID})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, tx.Rollback())
+
+	tx = rt.DB.MustBeginTx(ctx, nil)
+	split, err := models.SplitTicket(ctx, tx, oa, testdata.Admin.ID, ticket.Load(rt), []models.MsgID{msg2.ID})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.NotEqual(t, ticket.ID, split.ID())
+	assert.Equal(t, testdata.SalesTopic.ID, split.TopicID())
+	assert.Equal(t, testdata.Agent.ID, split.AssigneeID())
+
+	assertdb.Query(t, rt.DB, `SELECT ticket_id FROM msgs_msg WHERE id = $1`, msg1.ID).Returns(int64(ticket.ID))
+	assertdb.Query(t, rt.DB, `SELECT ticket_id FROM msgs_msg WHERE id = $1`, msg2.ID).Returns(int64(split.ID()))
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = $1 AND event_type = 'S'`, ticket.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = $1 AND event_type = 'O'`, split.ID()).Returns(1)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+// TicketSLAPolicy is a topic's service level agreement for its tickets, with times in minutes
+type TicketSLAPolicy struct {
+	FirstResponse int  `json:"first_response"`
+	Resolution    int  `json:"resolution"`
+	Reassign      bool `json:"reassign"`
+}
+
+// TicketSLAType is the type of SLA that a ticket can breach
+type TicketSLAType string
+
+const (
+	TicketSLATypeFirstResponse = TicketSLAType("first_response")
+	TicketSLATypeResolution    = TicketSLAType("resolution")
+)
+
+// TicketSLABreach is an open ticket which has breached its topic's SLA
+type TicketSLABreach struct {
+	TicketID   TicketID         `db:"ticket_id"`
+	TicketUUID flows.TicketUUID `db:"ticket_uuid"`
+	OrgID      OrgID            `db:"org_id"`
+	ContactID  ContactID        `db:"contact_id"`
+	TopicID    TopicID          `db:"topic_id"`
+	AssigneeID UserID           `db:"assignee_id"`
+	Type       TicketSLAType    `db:"sla_type"`
+	BreachedOn time.Time        `db:"breached_on"`
+}
+
+// only looks at open tickets of topics with SLAs, using the opened_on range of each topic which can have breached in
+// the window
+const sqlSelectTicketSLABreaches = `
+WITH slas AS (
+    SELECT id, (config->'sla'->>'first_response')::int AS first_response, (config->'sla'->>'resolution')::int AS resolution
+      FROM tickets_topic
+     WHERE is_active = TRUE AND config ? 'sla'
+)
+SELECT * FROM (
+    SELECT t.id AS ticket_id, t.uuid AS ticket_uuid, t.org_id, t.contact_id, t.topic_id, t.assignee_id, 'first_response' AS sla_type,
+           t.opened_on + make_interval(mins => s.first_response) AS breached_on
+      FROM slas s
+INNER JOIN tickets_ticket t ON t.topic_id = s.id AND t.status = 'O'
+     WHERE s.first_response > 0 AND t.replied_on IS NULL
+       AND t.opened_on <= $1::timestamptz - make_interval(mins => s.first_response) AND t.opened_on > $2::timestamptz - make_interval(mins => s.first_response)
+     UNION ALL
+    SELECT t.id AS ticket_id, t.uuid AS ticket_uuid, t.org_id, t.contact_id, t.topic_id, t.assignee_id, 'resolution' AS sla_type,
+           t.opened_on + make_interval(mins => s.resolution) AS breached_on
+      FROM slas s
+INNER JOIN tickets_ticket t ON t.topic_id = s.id AND t.status = 'O'
+     WHERE s.resolution > 0
+       AND t.opened_on <= $1::timestamptz - make_interval(mins => s.resolution) AND t.opened_on > $2::timestamptz - make_interval(mins => s.resolution)
+) b
+ORDER BY b.breached_on, b.ticket_id`
+
+// LoadTicketSLABreaches loads open tickets whose SLAs were breached between since and now. Callers are responsible
+// for ignoring breaches they have already handled.
+func LoadTicketSLABreaches(ctx context.Context, db DBorTx, now, since time.Time) ([]*TicketSLABreach, error) {
+	breaches := make([]*TicketSLABreach, 0, 10)
+	if err := db.SelectContext(ctx, &breaches, sqlSelectTicketSLABreaches, now, since); err != nil {
+		return nil, fmt.Errorf("error loading ticket SLA breaches: %w", err)
+	}
+	return breaches, nil
+}
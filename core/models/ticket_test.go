@@ -1,11 +1,13 @@
 package models_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/testsuite"
@@ -355,3 +357,78 @@ func TestTicketRecordReply(t *testing.T) {
 		openYmd + "/ticketresptime:count":    1,
 	})
 }
+
+func TestAutoAssignTickets(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData | testsuite.ResetRedis)
+
+	rt.DB.MustExec(`UPDATE tickets_topic SET config = '{"assignment": {"mode": "round_robin"}}' WHERE id = $1`, testdata.SalesTopic.ID)
+	rt.DB.MustExec(`UPDATE tickets_topic SET config = '{"assignment": {"mode": "least_open", "available_only": true}}' WHERE id = $1`, testdata.SupportTopic.ID)
+	rt.DB.MustExec(`UPDATE orgs_orgmembership SET is_available = (user_id = $2) WHERE org_id = $1`, testdata.Org1.ID, testdata.Agent.ID)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshTopics)
+	require.NoError(t, err)
+
+	newTicket := func(contact *testdata.Contact, topic *testdata.Topic, assignee models.UserID) *models.Ticket {
+		return models.NewTicket(flows.TicketUUID(uuids.NewV4()), testdata.Org1.ID, models.NilUserID, models.NilFlowID, contact.ID, topic.ID, assignee)
+	}
+
+	ticket1 := newTicket(testdata.Cathy, testdata.DefaultTopic, models.NilUserID) // topic has no policy
+	ticket2 := newTicket(testdata.Bob, testdata.SalesTopic, models.NilUserID)
+	ticket3 := newTicket(testdata.George, testdata.SalesTopic, models.NilUserID)
+	ticket4 := newTicket(testdata.Alexandria, testdata.SalesTopic, testdata.Editor.ID) // already assigned
+	ticket5 := newTicket(testdata.Cathy, testdata.SupportTopic, models.NilUserID)
+
+	assigned, err := models.AutoAssignTickets(ctx, rt, rt.DB, oa, []*models.Ticket{ticket1, ticket2, ticket3, ticket4, ticket5})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Ticket{ticket2, ticket3, ticket5}, assigned)
+
+	assert.Equal(t, models.NilUserID, ticket1.AssigneeID())
+	assert.Equal(t, testdata.Admin.ID, ticket2.AssigneeID()) // round robin starts with first agent
+	assert.Equal(t, testdata.Editor.ID, ticket3.AssigneeID())
+	assert.Equal(t, testdata.Editor.ID, ticket4.AssigneeID())
+	assert.Equal(t, testdata.Agent.ID, ticket5.AssigneeID()) // only available agent
+
+	// round robin continues from where it left off
+	ticket6 := newTicket(testdata.Bob, testdata.SalesTopic, models.NilUserID)
+	_, err = models.AutoAssignTickets(ctx, rt, rt.DB, oa, []*models.Ticket{ticket6})
+	assert.NoError(t, err)
+	assert.Equal(t, testdata.Agent.ID, ticket6.AssigneeID())
+
+	// and wraps around to the first agent
+	wrapped := newTicket(testdata.George, testdata.SalesTopic, models.NilUserID)
+	_, err = models.AutoAssignTickets(ctx, rt, rt.DB, oa, []*models.Ticket{wrapped})
+	assert.NoError(t, err)
+	assert.Equal(t, testdata.Admin.ID, wrapped.AssigneeID())
+
+	// concurrent assignments are spread evenly across agents
+	concurrent := make([]*models.Ticket, 30)
+	wg := &sync.WaitGroup{}
+	for i := range concurrent {
+		concurrent[i] = newTicket(testdata.Bob, testdata.SalesTopic, models.NilUserID)
+		wg.Add(1)
+		go func(tk *models.Ticket) {
+			defer wg.Done()
+			_, err := models.AutoAssignTickets(ctx, rt, rt.DB, oa, []*models.Ticket{tk})
+			assert.NoError(t, err)
+		}(concurrent[i])
+	}
+	wg.Wait()
+
+	counts := make(map[models.UserID]int)
+	for _, tk := range concurrent {
+		counts[tk.AssigneeID()]++
+	}
+	assert.Equal(t, map[models.UserID]int{testdata.Admin.ID: 10, testdata.Editor.ID: 10, testdata.Agent.ID: 10}, counts)
+
+	// reopening an unassigned ticket assigns it
+	ticket7 := testdata.InsertClosedTicket(rt, testdata.Org1, testdata.Bob, testdata.SupportTopic, nil)
+	modelTicket7 := ticket7.Load(rt)
+
+	_, err = models.ReopenTickets(ctx, rt, oa, testdata.Admin.ID, []*models.Ticket{modelTicket7})
+	assert.NoError(t, err)
+
+	assertdb.Query(t, rt.DB, `SELECT assignee_id FROM tickets_ticket WHERE id = $1`, ticket7.ID).Columns(map[string]any{"assignee_id": int64(testdata.Agent.ID)})
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM tickets_ticketevent WHERE ticket_id = $1 AND event_type = 'A' AND created_by_id IS NULL`, ticket7.ID).Returns(1)
+}
@@ -20,6 +20,9 @@ type Topic struct {
 	OrgID_     OrgID            `json:"org_id"`
 	Name_      string           `json:"name"`
 	IsDefault_ bool             `json:"is_default"`
+
+	Assignment_ *TicketAssignmentPolicy `json:"assignment"`
+	SLA_        *TicketSLAPolicy        `json:"sla"`
 }
 
 // ID returns the ID
@@ -34,9 +37,15 @@ func (t *Topic) Name() string { return t.Name_ }
 // Type returns the type
 func (t *Topic) IsDefault() bool { return t.IsDefault_ }
 
+// Assignment returns the policy for automatically assigning tickets if there is one
+func (t *Topic) Assignment() *TicketAssignmentPolicy { return t.Assignment_ }
+
+// SLA returns the service level agreement for tickets if there is one
+func (t *Topic) SLA() *TicketSLAPolicy { return t.SLA_ }
+
 const sqlSelectTopicsByOrg = `
 SELECT ROW_TO_JSON(r) FROM (
-      SELECT t.id as id, t.uuid as uuid, t.org_id as org_id, t.name as name, t.is_default as is_default
+      SELECT t.id as id, t.uuid as uuid, t.org_id as org_id, t.name as name, t.is_default as is_default, t.config->'assignment' as assignment, t.config->'sla' as sla
         FROM tickets_topic t
        WHERE t.org_id = $1 AND t.is_active = TRUE
     ORDER BY t.is_default DESC, t.created_on ASC
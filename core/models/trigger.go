@@ -5,15 +5,24 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/lib/pq"
 	"github.com/nyaruka/gocommon/dbutil"
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/utils"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 // TriggerType is the type of a trigger
@@ -27,22 +36,35 @@ type TriggerID int
 
 // trigger type constants
 const (
-	CatchallTriggerType        = TriggerType("C")
-	KeywordTriggerType         = TriggerType("K")
-	MissedCallTriggerType      = TriggerType("M")
-	NewConversationTriggerType = TriggerType("N")
-	ReferralTriggerType        = TriggerType("R")
-	IncomingCallTriggerType    = TriggerType("V")
-	ScheduleTriggerType        = TriggerType("S")
-	TicketClosedTriggerType    = TriggerType("T")
-	OptInTriggerType           = TriggerType("I")
-	OptOutTriggerType          = TriggerType("O")
+	CatchallTriggerType          = TriggerType("C")
+	KeywordTriggerType           = TriggerType("K")
+	MissedCallTriggerType        = TriggerType("M")
+	NewConversationTriggerType   = TriggerType("N")
+	ReferralTriggerType          = TriggerType("R")
+	IncomingCallTriggerType      = TriggerType("V")
+	ScheduleTriggerType          = TriggerType("S")
+	TicketClosedTriggerType      = TriggerType("T")
+	TicketSLABreachedTriggerType = TriggerType("B")
+	OptInTriggerType             = TriggerType("I")
+	OptOutTriggerType            = TriggerType("O")
 )
 
 // match type constants
 const (
 	MatchFirst MatchType = "F"
 	MatchOnly  MatchType = "O"
+	MatchRegex MatchType = "R"
+	MatchFuzzy MatchType = "Z"
+)
+
+const (
+	// org config key for the maximum edit distance of fuzzy keyword matches
+	configKeywordFuzzyDistance = "keyword_fuzzy_distance"
+
+	defaultKeywordFuzzyDistance = 1
+
+	// keywords shorter than this are only matched exactly (ignoring accents) by fuzzy triggers
+	minFuzzyKeywordLength = 4
 )
 
 // NilTriggerID is the nil value for trigger IDs
@@ -63,6 +85,9 @@ type Trigger struct {
 		ExcludeGroupIDs []GroupID      `json:"exclude_group_ids"`
 		ContactIDs      []ContactID    `json:"contact_ids,omitempty"`
 	}
+
+	compileOnce sync.Once
+	patterns    []*regexp.Regexp
 }
 
 // ID returns the id of this trigger
@@ -78,10 +103,28 @@ func (t *Trigger) IncludeGroupIDs() []GroupID { return t.t.IncludeGroupIDs }
 func (t *Trigger) ExcludeGroupIDs() []GroupID { return t.t.ExcludeGroupIDs }
 func (t *Trigger) ContactIDs() []ContactID    { return t.t.ContactIDs }
 func (t *Trigger) KeywordMatchType() triggers.KeywordMatchType {
-	if t.t.MatchType == MatchFirst {
-		return triggers.KeywordMatchTypeFirstWord
+	if t.t.MatchType == MatchOnly {
+		return triggers.KeywordMatchTypeOnlyWord
 	}
-	return triggers.KeywordMatchTypeOnlyWord
+	return triggers.KeywordMatchTypeFirstWord
+}
+
+// Patterns returns the compiled regular expressions of a regex keyword trigger, invalid patterns being ignored
+func (t *Trigger) Patterns() []*regexp.Regexp {
+	t.compileOnce.Do(func() {
+		if t.t.MatchType != MatchRegex {
+			return
+		}
+		for _, k := range t.t.Keywords {
+			re, err := regexp.Compile(`(?i)` + k)
+			if err != nil {
+				slog.Error("invalid regex on keyword trigger", "trigger_id", t.t.ID, "pattern", k, "error", err)
+				continue
+			}
+			t.patterns = append(t.patterns, re)
+		}
+	})
+	return t.patterns
 }
 
 func (t *Trigger) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, &t.t) }
@@ -116,8 +159,40 @@ func loadTriggers(ctx context.Context, db *sql.DB, orgID OrgID) ([]*Trigger, err
 	return triggers, nil
 }
 
-// FindMatchingMsgTrigger finds the best match trigger for an incoming message from the given contact
-func FindMatchingMsgTrigger(oa *OrgAssets, channel *Channel, contact *flows.Contact, text string) (*Trigger, string) {
+// FindMatchingMsgTrigger finds the best match trigger for an incoming message from the given contact, returning
+// the keyword that matched and, for regex triggers, the values of any named capture groups
+func FindMatchingMsgTrigger(oa *OrgAssets, channel *Channel, contact *flows.Contact, text string) (*Trigger, string, map[string]string) {
+	matcher := newKeywordMatcher(oa, text)
+
+	// for each candidate trigger, the keyword that matched and any captured params
+	candidateKeywords := make(map[*Trigger]string, 10)
+	candidateParams := make(map[*Trigger]map[string]string)
+
+	candidates := findTriggerCandidates(oa, KeywordTriggerType, func(t *Trigger) bool {
+		keyword, params, matched := matcher(t)
+		if matched {
+			candidateKeywords[t] = keyword
+			candidateParams[t] = params
+		}
+		return matched
+	})
+
+	sortKeywordCandidates(candidates)
+
+	// if we have a matching keyword trigger return that, otherwise we move on to catchall triggers..
+	byKeyword := findBestTriggerMatch(candidates, channel, contact)
+	if byKeyword != nil {
+		return byKeyword, candidateKeywords[byKeyword], candidateParams[byKeyword]
+	}
+
+	candidates = findTriggerCandidates(oa, CatchallTriggerType, nil)
+
+	return findBestTriggerMatch(candidates, channel, contact), "", nil
+}
+
+// creates a function which matches keyword triggers against the given message text, returning the matched keyword
+// and any captured params
+func newKeywordMatcher(oa *OrgAssets, text string) func(*Trigger) (string, map[string]string, bool) {
 	// determine our message keyword
 	words := utils.TokenizeString(text)
 	keyword := ""
@@ -128,29 +203,142 @@ func FindMatchingMsgTrigger(oa *OrgAssets, channel *Channel, contact *flows.Cont
 		only = len(words) == 1
 	}
 
-	// for each candidate trigger, the keyword that matched
-	candidateKeywords := make(map[*Trigger]string, 10)
+	fuzzyDistance := oa.Org().ConfigInt(configKeywordFuzzyDistance, defaultKeywordFuzzyDistance)
 
-	candidates := findTriggerCandidates(oa, KeywordTriggerType, func(t *Trigger) bool {
-		for _, k := range t.Keywords() {
-			m := envs.CollateEquals(oa.Env(), k, keyword) && (t.MatchType() == MatchFirst || (t.MatchType() == MatchOnly && only))
-			if m {
-				candidateKeywords[t] = k
-				return true
+	return func(t *Trigger) (string, map[string]string, bool) {
+		switch t.MatchType() {
+		case MatchFirst, MatchOnly:
+			for _, k := range t.Keywords() {
+				if envs.CollateEquals(oa.Env(), k, keyword) && (t.MatchType() == MatchFirst || only) {
+					return k, nil, true
+				}
+			}
+		case MatchRegex:
+			for _, re := range t.Patterns() {
+				if matched, params := matchKeywordRegex(re, text); matched != "" {
+					return matched, params, true
+				}
+			}
+		case MatchFuzzy:
+			for _, k := range t.Keywords() {
+				if matchKeywordFuzzy(k, keyword, fuzzyDistance) {
+					return k, nil, true
+				}
 			}
 		}
-		return false
+		return "", nil, false
+	}
+}
+
+// when triggers are equally specific, exact keyword matches win over regex matches which win over fuzzy matches
+func sortKeywordCandidates(candidates []*Trigger) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return keywordMatchPrecedence(candidates[i].MatchType()) > keywordMatchPrecedence(candidates[j].MatchType())
 	})
+}
 
-	// if we have a matching keyword trigger return that, otherwise we move on to catchall triggers..
-	byKeyword := findBestTriggerMatch(candidates, channel, contact)
-	if byKeyword != nil {
-		return byKeyword, candidateKeywords[byKeyword]
+// NewMsgTrigger builds an engine trigger for a message which matched the given trigger
+func NewMsgTrigger(oa *OrgAssets, flow *Flow, contact *flows.Contact, msg *flows.MsgIn, t *Trigger, keyword string, params map[string]string) (flows.Trigger, error) {
+	tb := triggers.NewBuilder(oa.Env(), flow.Reference(), contact).Msg(msg)
+	if keyword != "" {
+		tb = tb.WithMatch(&triggers.KeywordMatch{Type: t.KeywordMatchType(), Keyword: keyword})
 	}
 
-	candidates = findTriggerCandidates(oa, CatchallTriggerType, nil)
+	trigger := tb.Build()
+	if len(params) == 0 {
+		return trigger, nil
+	}
 
-	return findBestTriggerMatch(candidates, channel, contact), ""
+	// msg triggers can't be built with params so add them to the serialized trigger and read it back
+	props := make(map[string]types.XValue, len(params))
+	for k, v := range params {
+		props[k] = types.NewXText(v)
+	}
+
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(jsonx.MustMarshal(trigger), &envelope); err != nil {
+		return nil, fmt.Errorf("error unmarshaling msg trigger: %w", err)
+	}
+	envelope["params"] = jsonx.MustMarshal(types.NewXObject(props))
+
+	return triggers.ReadTrigger(oa.SessionAssets(), jsonx.MustMarshal(envelope), nil)
+}
+
+// matches the given regex against the message text, returning the matched text and any named capture groups
+func matchKeywordRegex(re *regexp.Regexp, text string) (string, map[string]string) {
+	match := re.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil || match[0] == "" {
+		return "", nil
+	}
+
+	var params map[string]string
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = match[i]
+		}
+	}
+	return match[0], params
+}
+
+// checks whether the given word is within the given edit distance of the keyword, ignoring case and accents
+func matchKeywordFuzzy(keyword, word string, distance int) bool {
+	if word == "" {
+		return false
+	}
+
+	k, w := []rune(unaccent(keyword)), []rune(unaccent(word))
+
+	if len(k) < minFuzzyKeywordLength {
+		distance = 0
+	}
+
+	return levenshtein(k, w) <= distance
+}
+
+// normalizes a string for fuzzy matching by lowercasing it and stripping diacritics
+func unaccent(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	r, _, err := transform.String(t, s)
+	if err != nil {
+		r = s
+	}
+	return strings.ToLower(r)
+}
+
+// calculates the Levenshtein edit distance between two strings
+func levenshtein(s, t []rune) int {
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(t)]
+}
+
+func keywordMatchPrecedence(m MatchType) int {
+	switch m {
+	case MatchFirst, MatchOnly:
+		return 2
+	case MatchRegex:
+		return 1
+	}
+	return 0
 }
 
 // FindMatchingIncomingCallTrigger finds the best match trigger for incoming calls
@@ -215,6 +403,135 @@ func FindMatchingTicketClosedTrigger(oa *OrgAssets, contact *flows.Contact) *Tri
 	return findBestTriggerMatch(candidates, nil, contact)
 }
 
+// FindMatchingTicketSLABreachedTrigger finds the best match trigger for ticket SLA breaches
+func FindMatchingTicketSLABreachedTrigger(oa *OrgAssets, contact *flows.Contact) *Trigger {
+	candidates := findTriggerCandidates(oa, TicketSLABreachedTriggerType, nil)
+
+	return findBestTriggerMatch(candidates, nil, contact)
+}
+
+// TriggerRejection is the reason a trigger wasn't selected
+type TriggerRejection string
+
+// trigger rejection constants
+const (
+	TriggerRejectionKeyword        = TriggerRejection("keyword")         // keyword didn't match the message
+	TriggerRejectionReferrer       = TriggerRejection("referrer")        // referrer ID didn't match the event
+	TriggerRejectionChannel        = TriggerRejection("channel")         // trigger is for another channel
+	TriggerRejectionIncludeGroups  = TriggerRejection("include_groups")  // contact isn't in any of the included groups
+	TriggerRejectionExcludeGroups  = TriggerRejection("exclude_groups")  // contact is in one of the excluded groups
+	TriggerRejectionOutscored      = TriggerRejection("outscored")       // another trigger matched more specifically
+	TriggerRejectionKeywordMatched = TriggerRejection("keyword_matched") // catchall not considered because a keyword trigger matched
+)
+
+// TriggerCandidate is a trigger that was considered during trigger selection
+type TriggerCandidate struct {
+	Trigger   *Trigger
+	Keyword   string
+	Score     int
+	Rejection TriggerRejection
+}
+
+// ExplainMsgTrigger explains trigger selection for an incoming message, returning every keyword and catchall trigger
+// that was considered, and the selected trigger if there is one
+func ExplainMsgTrigger(oa *OrgAssets, channel *Channel, contact *flows.Contact, text string) ([]*TriggerCandidate, *Trigger) {
+	matcher := newKeywordMatcher(oa, text)
+	keywords := make(map[*Trigger]string, 10)
+	unmatched := make([]*TriggerCandidate, 0, 10)
+
+	candidates := findTriggerCandidates(oa, KeywordTriggerType, func(t *Trigger) bool {
+		keyword, _, matched := matcher(t)
+		if matched {
+			keywords[t] = keyword
+		} else {
+			unmatched = append(unmatched, &TriggerCandidate{Trigger: t, Rejection: TriggerRejectionKeyword})
+		}
+		return matched
+	})
+
+	sortKeywordCandidates(candidates)
+
+	explained, winner := explainTriggerMatches(candidates, channel, contact)
+	for _, c := range explained {
+		c.Keyword = keywords[c.Trigger]
+	}
+	explained = append(explained, unmatched...)
+
+	catchalls := findTriggerCandidates(oa, CatchallTriggerType, nil)
+	if winner != nil {
+		for _, t := range catchalls {
+			explained = append(explained, &TriggerCandidate{Trigger: t, Rejection: TriggerRejectionKeywordMatched})
+		}
+		return explained, winner
+	}
+
+	byCatchall, winner := explainTriggerMatches(catchalls, channel, contact)
+
+	return append(explained, byCatchall...), winner
+}
+
+// ExplainEventTrigger explains trigger selection for a channel event or ticket closing, returning every trigger of the
+// given type that was considered, and the selected trigger if there is one
+func ExplainEventTrigger(oa *OrgAssets, type_ TriggerType, channel *Channel, contact *flows.Contact, referrerID string) ([]*TriggerCandidate, *Trigger) {
+	switch type_ {
+	case IncomingCallTriggerType:
+		return explainTriggerMatches(findTriggerCandidates(oa, type_, nil), channel, contact)
+	case TicketClosedTriggerType, TicketSLABreachedTriggerType:
+		return explainTriggerMatches(findTriggerCandidates(oa, type_, nil), nil, contact)
+	case ReferralTriggerType:
+		unmatched := make([]*TriggerCandidate, 0, 10)
+		candidates := findTriggerCandidates(oa, type_, func(t *Trigger) bool {
+			matched := strings.EqualFold(t.ReferrerID(), referrerID)
+			if !matched && t.ReferrerID() != "" {
+				unmatched = append(unmatched, &TriggerCandidate{Trigger: t, Rejection: TriggerRejectionReferrer})
+			}
+			return matched
+		})
+
+		explained, winner := explainTriggerMatches(candidates, channel, nil)
+		explained = append(explained, unmatched...)
+
+		// triggers without a referrer ID are only considered if none with a matching referrer ID were found
+		fallbacks := findTriggerCandidates(oa, type_, func(t *Trigger) bool { return t.ReferrerID() == "" && referrerID != "" })
+		if winner != nil {
+			for _, t := range fallbacks {
+				explained = append(explained, &TriggerCandidate{Trigger: t, Rejection: TriggerRejectionOutscored})
+			}
+			return explained, winner
+		}
+
+		byFallback, winner := explainTriggerMatches(fallbacks, channel, nil)
+		return append(explained, byFallback...), winner
+	}
+
+	// other channel event triggers don't consider the contact
+	return explainTriggerMatches(findTriggerCandidates(oa, type_, nil), channel, nil)
+}
+
+// explains the ranking of the given candidates, returning them with the winner first, and then the winner
+func explainTriggerMatches(candidates []*Trigger, channel *Channel, contact *flows.Contact) ([]*TriggerCandidate, *Trigger) {
+	matches, rejections := rankTriggerMatches(candidates, channel, contact)
+	explained := make([]*TriggerCandidate, 0, len(candidates))
+
+	for i, m := range matches {
+		c := &TriggerCandidate{Trigger: m.trigger, Score: m.score}
+		if i > 0 {
+			c.Rejection = TriggerRejectionOutscored
+		}
+		explained = append(explained, c)
+	}
+	for _, t := range candidates {
+		if r, rejected := rejections[t]; rejected {
+			explained = append(explained, &TriggerCandidate{Trigger: t, Rejection: r})
+		}
+	}
+
+	if len(matches) == 0 {
+		return explained, nil
+	}
+	return explained, matches[0].trigger
+}
+
 // finds trigger candidates based on type and optional filter
 func findTriggerCandidates(oa *OrgAssets, type_ TriggerType, filter func(*Trigger) bool) []*Trigger {
 	candidates := make([]*Trigger, 0, 10)
@@ -248,7 +565,18 @@ const triggerScoreByInclusion = 2
 const triggerScoreByExclusion = 1
 
 func findBestTriggerMatch(candidates []*Trigger, channel *Channel, contact *flows.Contact) *Trigger {
+	matches, _ := rankTriggerMatches(candidates, channel, contact)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	return matches[0].trigger
+}
+
+// ranks the given candidates in descending order of score, returning the reasons why any were rejected
+func rankTriggerMatches(candidates []*Trigger, channel *Channel, contact *flows.Contact) ([]*triggerMatch, map[*Trigger]TriggerRejection) {
 	matches := make([]*triggerMatch, 0, len(candidates))
+	rejections := make(map[*Trigger]TriggerRejection)
 
 	var groupIDs map[GroupID]bool
 
@@ -261,31 +589,30 @@ func findBestTriggerMatch(candidates []*Trigger, channel *Channel, contact *flow
 	}
 
 	for _, t := range candidates {
-		matched, score := triggerMatchQualifiers(t, channel, groupIDs)
-		if matched {
+		score, rejection := triggerMatchQualifiers(t, channel, groupIDs)
+		if rejection == "" {
 			matches = append(matches, &triggerMatch{t, score})
+		} else {
+			rejections[t] = rejection
 		}
 	}
 
-	if len(matches) == 0 {
-		return nil
-	}
-
 	// sort the matches to get them in descending order of score
 	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
 
-	return matches[0].trigger
+	return matches, rejections
 }
 
-// matches against the qualifiers (inclusion groups, exclusion groups, channel) on this trigger and returns a score
-func triggerMatchQualifiers(t *Trigger, channel *Channel, contactGroups map[GroupID]bool) (bool, int) {
+// matches against the qualifiers (inclusion groups, exclusion groups, channel) on this trigger and returns a score,
+// or the reason it doesn't match
+func triggerMatchQualifiers(t *Trigger, channel *Channel, contactGroups map[GroupID]bool) (int, TriggerRejection) {
 	score := 0
 
 	if channel != nil && t.ChannelID() != NilChannelID {
 		if t.ChannelID() == channel.ID() {
 			score += triggerScoreByChannel
 		} else {
-			return false, 0
+			return 0, TriggerRejectionChannel
 		}
 	}
 
@@ -300,7 +627,7 @@ func triggerMatchQualifiers(t *Trigger, channel *Channel, contactGroups map[Grou
 			}
 		}
 		if !inGroup {
-			return false, 0
+			return 0, TriggerRejectionIncludeGroups
 		}
 	}
 
@@ -308,13 +635,13 @@ func triggerMatchQualifiers(t *Trigger, channel *Channel, contactGroups map[Grou
 		// if contact is in none of the groups to exclude that's a match by exclusion
 		for _, g := range t.ExcludeGroupIDs() {
 			if contactGroups[g] {
-				return false, 0
+				return 0, TriggerRejectionExcludeGroups
 			}
 		}
 		score += triggerScoreByExclusion
 	}
 
-	return true, score
+	return score, ""
 }
 
 const sqlSelectTriggersByOrg = `
@@ -6,6 +6,8 @@ import (
 
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/testsuite"
@@ -151,6 +153,12 @@ func TestFindMatchingMsgTrigger(t *testing.T) {
 	doctorsAndNotTestersID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.SingleMessage, []string{"resist"}, models.MatchOnly, []*testdata.Group{testdata.DoctorsGroup}, []*testdata.Group{testdata.TestersGroup}, nil)
 	doctorsCatchallID := testdata.InsertCatchallTrigger(rt, testdata.Org1, testdata.SingleMessage, []*testdata.Group{testdata.DoctorsGroup}, nil, nil)
 	othersAllID := testdata.InsertCatchallTrigger(rt, testdata.Org1, testdata.SingleMessage, nil, nil, nil)
+	codeID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{`^code (?P<code>\d+)`}, models.MatchRegex, nil, nil, nil)
+	codeTwilioOnlyID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.PickANumber, []string{`^code`}, models.MatchRegex, nil, nil, testdata.TwilioChannel)
+	registerID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{"register", "añadir"}, models.MatchFuzzy, nil, nil, nil)
+	regexJoinID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.PickANumber, []string{`^jo+in`}, models.MatchRegex, nil, nil, nil)
+	surveyID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.PickANumber, []string{"survey"}, models.MatchFuzzy, nil, nil, nil)
+	signupID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.PickANumber, []string{`^(subscribe|sign ?up)( me)?$`}, models.MatchRegex, nil, nil, nil)
 
 	// trigger for other org
 	testdata.InsertCatchallTrigger(rt, testdata.Org2, testdata.Org2Favorites, nil, nil, nil)
@@ -174,38 +182,93 @@ func TestFindMatchingMsgTrigger(t *testing.T) {
 		contact           *flows.Contact
 		expectedTriggerID models.TriggerID
 		expectedKeyword   string
+		expectedParams    map[string]string
 	}{
-		{" join ", nil, cathy, joinID, "join"},
-		{"JOIN", nil, cathy, joinID, "join"},
-		{"JOIN", twilioChannel, cathy, joinTwilioOnlyID, "join"},
-		{"JOIN", facebookChannel, cathy, joinID, "join"},
-		{"join this", nil, cathy, joinID, "join"},
-		{"resist", nil, george, resistID, "resist"},
-		{"resist", twilioChannel, george, resistTwilioOnlyID, "resist"},
-		{"resist", nil, bob, doctorsID, "resist"},
-		{"resist", twilioChannel, cathy, resistTwilioOnlyID, "resist"},
-		{"resist", nil, cathy, doctorsAndNotTestersID, "resist"},
-		{"resist this", nil, cathy, doctorsCatchallID, ""},
-		{" 👍 ", nil, george, emojiID, "👍"},
-		{"👍🏾", nil, george, emojiID, "👍"}, // is 👍 + 🏾
-		{"😀👍", nil, george, othersAllID, ""},
-		{"other", nil, cathy, doctorsCatchallID, ""},
-		{"other", nil, george, othersAllID, ""},
-		{"", nil, george, othersAllID, ""},
-		{"start", twilioChannel, cathy, startTwilioOnlyID, "start"},
-		{"start", facebookChannel, cathy, doctorsCatchallID, ""},
-		{"start", twilioChannel, george, startTwilioOnlyID, "start"},
-		{"start", facebookChannel, george, othersAllID, ""},
+		{" join ", nil, cathy, joinID, "join", nil},
+		{"JOIN", nil, cathy, joinID, "join", nil},
+		{"JOIN", twilioChannel, cathy, joinTwilioOnlyID, "join", nil},
+		{"JOIN", facebookChannel, cathy, joinID, "join", nil},
+		{"join this", nil, cathy, joinID, "join", nil},
+		{"resist", nil, george, resistID, "resist", nil},
+		{"resist", twilioChannel, george, resistTwilioOnlyID, "resist", nil},
+		{"resist", nil, bob, doctorsID, "resist", nil},
+		{"resist", twilioChannel, cathy, resistTwilioOnlyID, "resist", nil},
+		{"resist", nil, cathy, doctorsAndNotTestersID, "resist", nil},
+		{"resist this", nil, cathy, doctorsCatchallID, "", nil},
+		{" 👍 ", nil, george, emojiID, "👍", nil},
+		{"👍🏾", nil, george, emojiID, "👍", nil}, // is 👍 + 🏾
+		{"😀👍", nil, george, othersAllID, "", nil},
+		{"other", nil, cathy, doctorsCatchallID, "", nil},
+		{"other", nil, george, othersAllID, "", nil},
+		{"", nil, george, othersAllID, "", nil},
+		{"start", twilioChannel, cathy, startTwilioOnlyID, "start", nil},
+		{"start", facebookChannel, cathy, doctorsCatchallID, "", nil},
+		{"start", twilioChannel, george, startTwilioOnlyID, "start", nil},
+		{"start", facebookChannel, george, othersAllID, "", nil},
+		{"CODE 1234", facebookChannel, george, codeID, "CODE 1234", map[string]string{"code": "1234"}},
+		{"code 1234 please", facebookChannel, george, codeID, "code 1234", map[string]string{"code": "1234"}},
+		{"code 1234", twilioChannel, george, codeTwilioOnlyID, "code", nil},
+		{"code", facebookChannel, george, othersAllID, "", nil},
+		{"registr", nil, george, registerID, "register", nil},
+		{"REGISTER me", nil, george, registerID, "register", nil},
+		{"regstir", nil, george, othersAllID, "", nil},
+		{"anadir", nil, george, registerID, "añadir", nil},
+		{"joooin", nil, george, regexJoinID, "joooin", nil},
+		{"join", nil, george, joinID, "join", nil},
+		{"survy", facebookChannel, george, surveyID, "survey", nil},
+		{"SÜRVEYS", twilioChannel, george, surveyID, "survey", nil},
+		{"Sign up", nil, george, signupID, "Sign up", nil},
+		{"subscribe me", nil, george, signupID, "subscribe me", nil},
 	}
 
 	for _, tc := range tcs {
-		trigger, keyword := models.FindMatchingMsgTrigger(oa, tc.channel, tc.contact, tc.text)
+		trigger, keyword, params := models.FindMatchingMsgTrigger(oa, tc.channel, tc.contact, tc.text)
 
 		assertTrigger(t, tc.expectedTriggerID, trigger, "trigger mismatch for %s sending '%s'", tc.contact.Name(), tc.text)
 		assert.Equal(t, tc.expectedKeyword, keyword, "keyword mismatch for %s sending '%s'", tc.contact.Name(), tc.text)
+		assert.Equal(t, tc.expectedParams, params, "params mismatch for %s sending '%s'", tc.contact.Name(), tc.text)
 	}
 }
 
+func TestNewMsgTrigger(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	joinID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{`^join (?P<code>\w+)`}, models.MatchRegex, nil, nil, nil)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshTriggers)
+	require.NoError(t, err)
+
+	_, george, _ := testdata.George.Load(rt, oa)
+	flow, err := oa.FlowByID(testdata.Favorites.ID)
+	require.NoError(t, err)
+
+	evaluate := func(trigger flows.Trigger, template string) string {
+		xctx := types.NewXObject(map[string]types.XValue{"trigger": flows.Context(oa.Env(), trigger)})
+		result, _, err := excellent.NewEvaluator().Template(oa.Env(), xctx, template, nil)
+		require.NoError(t, err)
+		return result
+	}
+
+	msg := flows.NewMsgIn(flows.NewMsgUUID(), testdata.George.URN, nil, "JOIN abc123", nil, "")
+	trigger, keyword, params := models.FindMatchingMsgTrigger(oa, nil, george, msg.Text())
+	assertTrigger(t, joinID, trigger)
+	assert.Equal(t, map[string]string{"code": "abc123"}, params)
+
+	// named capture groups are passed to the flow as trigger params, alongside the msg and keyword
+	flowTrigger, err := models.NewMsgTrigger(oa, flow, george, msg, trigger, keyword, params)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", evaluate(flowTrigger, "@trigger.params.code"))
+	assert.Equal(t, "JOIN abc123", evaluate(flowTrigger, "@trigger.keyword"))
+	assert.Equal(t, "msg", evaluate(flowTrigger, "@trigger.type"))
+
+	// without params the trigger still has empty params
+	flowTrigger, err = models.NewMsgTrigger(oa, flow, george, msg, trigger, keyword, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", evaluate(flowTrigger, "@trigger.params.code"))
+}
+
 func TestFindMatchingIncomingCallTrigger(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
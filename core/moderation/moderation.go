@@ -0,0 +1,94 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/mailroom/core/ai/prompts"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// Checker is a moderation check run against the text of incoming messages. It returns a non-empty reason if the
+// text should be flagged.
+type Checker func(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, policy *models.ModerationPolicy, text string) (string, error)
+
+type registeredChecker struct {
+	name string
+	fn   Checker
+}
+
+var checkers []registeredChecker
+
+func init() {
+	RegisterChecker("words", checkWords)
+	RegisterChecker("llm", checkLLM)
+}
+
+// RegisterChecker registers a moderation checker. Checkers are run in the order they're registered.
+func RegisterChecker(name string, fn Checker) {
+	checkers = append(checkers, registeredChecker{name, fn})
+}
+
+// Moderate runs the org's moderation checks against the given text, returning the org's policy and the reason the
+// text was flagged. Returns a nil policy if org has no moderation policy, and an empty reason if text wasn't flagged.
+func Moderate(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, text string) (*models.ModerationPolicy, string, error) {
+	policy := oa.Org().ModerationPolicy()
+	if policy == nil || strings.TrimSpace(text) == "" {
+		return policy, "", nil
+	}
+
+	for _, c := range checkers {
+		reason, err := c.fn(ctx, rt, oa, policy, text)
+		if err != nil {
+			return nil, "", fmt.Errorf("error running %s moderation check: %w", c.name, err)
+		}
+		if reason != "" {
+			return policy, reason, nil
+		}
+	}
+
+	return policy, "", nil
+}
+
+// flags text which contains any of the policy's words or phrases
+func checkWords(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, policy *models.ModerationPolicy, text string) (string, error) {
+	if word := policy.MatchWord(text); word != "" {
+		return fmt.Sprintf("matched word '%s'", word), nil
+	}
+	return "", nil
+}
+
+// flags text which the policy's LLM classifies as harmful
+func checkLLM(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, policy *models.ModerationPolicy, text string) (string, error) {
+	if policy.LLMUUID == "" {
+		return "", nil
+	}
+
+	llm := oa.LLMByUUID(policy.LLMUUID)
+	if llm == nil {
+		return "", nil // LLM has been deleted
+	}
+
+	llmSvc, err := llm.AsService(http.DefaultClient)
+	if err != nil {
+		return "", fmt.Errorf("error creating LLM service: %w", err)
+	}
+
+	start := time.Now()
+
+	resp, err := llmSvc.Response(ctx, prompts.Render("moderate", nil), text, 10)
+	if err != nil {
+		return "", fmt.Errorf("error calling LLM service: %w", err)
+	}
+
+	llm.RecordCall(rt, time.Since(start), resp.TokensUsed)
+
+	if strings.TrimSpace(resp.Output) == "FLAGGED" {
+		return fmt.Sprintf("flagged by LLM '%s'", llm.Name()), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,54 @@
+package moderation_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/moderation"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerate(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	// no policy, nothing flagged
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	policy, reason, err := moderation.Moderate(ctx, rt, oa, "you idiot")
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+	assert.Equal(t, "", reason)
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"moderation": {"words": ["idiot"], "llm_uuid": "e5d8900a-ef54-4d2a-8214-ff7d3e903502"}}' WHERE id = $1`, testdata.Org1.ID)
+
+	oa, err = models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	tcs := []struct {
+		text           string
+		expectedReason string
+		expectedError  string
+	}{
+		{"hello", "", ""},
+		{"you idiot", "matched word 'idiot'", ""},
+		{`\return FLAGGED`, "flagged by LLM 'Test'", ""},
+		{`\return OK`, "", ""},
+		{`\error boom`, "", "error running llm moderation check: error calling LLM service: boom"},
+	}
+
+	for _, tc := range tcs {
+		_, reason, err := moderation.Moderate(ctx, rt, oa, tc.text)
+		if tc.expectedError != "" {
+			assert.EqualError(t, err, tc.expectedError, "error mismatch for '%s'", tc.text)
+		} else {
+			assert.NoError(t, err, "unexpected error for '%s'", tc.text)
+			assert.Equal(t, tc.expectedReason, reason, "reason mismatch for '%s'", tc.text)
+		}
+	}
+}
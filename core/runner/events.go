@@ -135,10 +135,22 @@ func (s *Scene) AddEvents(ctx context.Context, rt *runtime.Runtime, oa *models.O
 
 // ApplyEvents takes a set of contacts and events, handles the events and applies any hooks, and commits everything
 func ApplyEvents(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, userID models.UserID, contactEvents map[*flows.Contact][]flows.Event) error {
+	return applyEvents(ctx, rt, oa, userID, contactEvents, nil)
+}
+
+// ApplyMsgEvents is like ApplyEvents but for events which relate to the given incoming message, e.g. labels being added
+func ApplyMsgEvents(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, contact *flows.Contact, msg *models.MsgInRef, events []flows.Event) error {
+	return applyEvents(ctx, rt, oa, models.NilUserID, map[*flows.Contact][]flows.Event{contact: events}, func(s *Scene) { s.IncomingMsg = msg })
+}
+
+func applyEvents(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, userID models.UserID, contactEvents map[*flows.Contact][]flows.Event, sceneInit func(*Scene)) error {
 	// create scenes for each contact
 	scenes := make([]*Scene, 0, len(contactEvents))
 	for contact := range contactEvents {
 		scene := NewSceneForContact(contact, userID)
+		if sceneInit != nil {
+			sceneInit(scene)
+		}
 		scenes = append(scenes, scene)
 	}
 
@@ -22,9 +22,18 @@ func handleContactFieldChanged(ctx context.Context, rt *runtime.Runtime, oa *mod
 
 	slog.Debug("contact field changed", "contact", scene.ContactUUID(), "session", scene.SessionUUID(), "field", event.Field.Key, "value", event.Value)
 
+	// changes which break the field's validation rules aren't saved and are recorded in the contact's history as warnings
+	if field := oa.FieldByKey(event.Field.Key); field != nil {
+		if err := field.Validate(event.Value); err != nil {
+			scene.AttachPreCommitHook(hooks.InsertContactHistory, events.NewWarning(err.Error()))
+			return nil
+		}
+	}
+
 	scene.AttachPreCommitHook(hooks.UpdateContactFields, event)
 	scene.AttachPreCommitHook(hooks.UpdateCampaignEvents, event)
 	scene.AttachPreCommitHook(hooks.UpdateContactModifiedOn, event)
+	scene.AttachPreCommitHook(hooks.InsertContactHistory, event)
 
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/actions"
+	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/runner/handlers"
 	"github.com/nyaruka/mailroom/testsuite"
 	"github.com/nyaruka/mailroom/testsuite/testdata"
@@ -92,3 +93,63 @@ func TestContactFieldChanged(t *testing.T) {
 
 	handlers.RunTestCases(t, ctx, rt, tcs)
 }
+
+func TestContactFieldChangedWithValidation(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	gender := assets.NewFieldReference("gender", "Gender")
+	age := assets.NewFieldReference("age", "Age")
+
+	rt.DB.MustExec(`UPDATE contacts_contactfield SET validation = '{"min": 18, "max": 120}' WHERE id = $1`, testdata.AgeField.ID)
+	rt.DB.MustExec(`UPDATE contacts_contactfield SET validation = '{"required": true, "allowed": ["Female", "Male"]}' WHERE id = $1`, testdata.GenderField.ID)
+	models.FlushCache()
+
+	tcs := []handlers.TestCase{
+		{
+			Actions: handlers.ContactActionMap{
+				testdata.Cathy: []flows.Action{
+					actions.NewSetContactField(handlers.NewActionUUID(), gender, "Female"),
+					actions.NewSetContactField(handlers.NewActionUUID(), age, "12"),
+				},
+				testdata.George: []flows.Action{
+					actions.NewSetContactField(handlers.NewActionUUID(), gender, "Boy"),
+					actions.NewSetContactField(handlers.NewActionUUID(), age, "40"),
+				},
+				testdata.Bob: []flows.Action{
+					actions.NewSetContactField(handlers.NewActionUUID(), gender, ""),
+				},
+			},
+			SQLAssertions: []handlers.SQLAssertion{
+				{
+					SQL:   `select count(*) from contacts_contact where id = $1 AND fields->$2 = '{"text":"Female"}'::jsonb AND fields->$3->>'text' IS DISTINCT FROM '12'`,
+					Args:  []any{testdata.Cathy.ID, testdata.GenderField.UUID, testdata.AgeField.UUID},
+					Count: 1,
+				},
+				{
+					SQL:   `select count(*) from contacts_contact where id = $1 AND fields->$2->>'text' IS DISTINCT FROM 'Boy' AND fields->$3 = '{"text":"40", "number": 40}'::jsonb`,
+					Args:  []any{testdata.George.ID, testdata.GenderField.UUID, testdata.AgeField.UUID},
+					Count: 1,
+				},
+				{
+					SQL:   `select count(*) from contacts_contacthistory where event_type = 'warning' AND event->>'text' = $1`,
+					Args:  []any{"'12' is less than the minimum of 18 for contact field 'age'"},
+					Count: 1,
+				},
+				{
+					SQL:   `select count(*) from contacts_contacthistory where event_type = 'warning' AND event->>'text' = $1`,
+					Args:  []any{"'Boy' is not an allowed value for contact field 'gender'"},
+					Count: 1,
+				},
+				{
+					SQL:   `select count(*) from contacts_contacthistory where contact_id = $1 AND event_type = 'warning' AND event->>'text' = $2`,
+					Args:  []any{testdata.Bob.ID, "a value is required for contact field 'gender'"},
+					Count: 1,
+				},
+			},
+		},
+	}
+
+	handlers.RunTestCases(t, ctx, rt, tcs)
+}
@@ -62,5 +62,7 @@ func handleContactGroupsChanged(ctx context.Context, rt *runtime.Runtime, oa *mo
 		scene.AttachPreCommitHook(hooks.UpdateContactModifiedOn, event)
 	}
 
+	scene.AttachPreCommitHook(hooks.InsertContactHistory, event)
+
 	return nil
 }
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/nyaruka/goflow/assets"
@@ -60,3 +61,34 @@ func TestContactGroupsChanged(t *testing.T) {
 
 	handlers.RunTestCases(t, ctx, rt, tcs)
 }
+
+func TestContactGroupsChangedWithGroupJoinedEvents(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	// add a campaign on testers with an event 2 days after joining the group
+	campaign := testdata.InsertCampaign(rt, testdata.Org1, "Onboarding", testdata.TestersGroup)
+	event := testdata.InsertCampaignFlowEvent(rt, campaign, testdata.Favorites, testdata.GroupJoinedOnField, 2, "D")
+
+	testers := assets.NewGroupReference(testdata.TestersGroup.UUID, "Testers")
+
+	tcs := []handlers.TestCase{
+		{
+			Actions: handlers.ContactActionMap{
+				testdata.Cathy: []flows.Action{
+					actions.NewAddContactGroups(handlers.NewActionUUID(), []*assets.GroupReference{testers}),
+				},
+			},
+			SQLAssertions: []handlers.SQLAssertion{
+				{
+					SQL:   "select count(*) from contacts_contactfire where contact_id = $1 and scope = $2 and fire_on > NOW() + interval '1 day'",
+					Args:  []any{testdata.Cathy.ID, fmt.Sprintf("%d:1", event.ID)},
+					Count: 1,
+				},
+			},
+		},
+	}
+
+	handlers.RunTestCases(t, ctx, rt, tcs)
+}
@@ -57,6 +57,15 @@ func TestContactNameChanged(t *testing.T) {
 					Args:  []any{testdata.Alexandria.ID},
 					Count: 1,
 				},
+				{
+					SQL:   "select count(*) from contacts_contacthistory where contact_id = $1 and event_type = 'contact_name_changed' and flow_id IS NOT NULL and created_by_id IS NULL",
+					Args:  []any{testdata.Cathy.ID},
+					Count: 2,
+				},
+				{
+					SQL:   "select count(*) from contacts_contacthistory where event_type = 'contact_name_changed'",
+					Count: 5,
+				},
 			},
 		},
 	}
@@ -37,6 +37,11 @@ func handleContactURNsChanged(ctx context.Context, rt *runtime.Runtime, oa *mode
 
 	scene.AttachPreCommitHook(hooks.UpdateContactURNs, change)
 	scene.AttachPreCommitHook(hooks.UpdateContactModifiedOn, event)
+	scene.AttachPreCommitHook(hooks.InsertContactHistory, event)
+
+	if oa.Org().HasNumberLookup() && models.HasTelURN(event.URNs) {
+		scene.AttachPostCommitHook(hooks.LookupURNs, event)
+	}
 
 	return nil
 }
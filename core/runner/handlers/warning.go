@@ -8,6 +8,7 @@ import (
 	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/core/runner/hooks"
 	"github.com/nyaruka/mailroom/runtime"
 )
 
@@ -26,6 +27,12 @@ func init() {
 func handleWarning(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, scene *runner.Scene, e flows.Event) error {
 	event := e.(*events.WarningEvent)
 
+	// warnings outside of flows are from changes to contacts being rejected so record them in the contact's history
+	if scene.Session() == nil {
+		scene.AttachPreCommitHook(hooks.InsertContactHistory, event)
+		return nil
+	}
+
 	flow, _ := scene.LocateEvent(e)
 	logMsg := warningsLogs[event.Text]
 	if logMsg != "" {
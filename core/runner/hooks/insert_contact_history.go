@@ -0,0 +1,43 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// InsertContactHistory is our hook for recording changes to contacts in their change history
+var InsertContactHistory runner.PreCommitHook = &insertContactHistory{}
+
+type insertContactHistory struct{}
+
+func (h *insertContactHistory) Order() int { return 1 }
+
+func (h *insertContactHistory) Execute(ctx context.Context, rt *runtime.Runtime, tx *sqlx.Tx, oa *models.OrgAssets, scenes map[*runner.Scene][]any) error {
+	history := make([]*models.ContactHistory, 0, len(scenes))
+	for s, es := range scenes {
+		for _, e := range es {
+			event := e.(flows.Event)
+
+			// changes made in a flow are attributed to that flow, otherwise to the user if there is one
+			flowID := models.NilFlowID
+			if s.Session() != nil && event.StepUUID() != "" {
+				flow, _ := s.LocateEvent(event)
+				flowID = flow.ID()
+			}
+
+			history = append(history, models.NewContactHistory(oa.OrgID(), s.ContactID(), event, s.UserID(), flowID))
+		}
+	}
+
+	if err := models.InsertContactHistory(ctx, tx, history); err != nil {
+		return fmt.Errorf("error inserting contact history: %w", err)
+	}
+
+	return nil
+}
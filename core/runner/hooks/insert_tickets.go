@@ -35,6 +35,11 @@ func (h *insertTickets) Execute(ctx context.Context, rt *runtime.Runtime, tx *sq
 		}
 	}
 
+	// apply any topic assignment policies to unassigned tickets
+	if _, err := models.AutoAssignTickets(ctx, rt, tx, oa, tickets); err != nil {
+		return fmt.Errorf("error auto-assigning tickets: %w", err)
+	}
+
 	// insert the tickets
 	if err := models.InsertTickets(ctx, tx, oa, tickets); err != nil {
 		return fmt.Errorf("error inserting tickets: %w", err)
@@ -0,0 +1,29 @@
+package hooks
+
+import (
+	"context"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// LookupURNs is our hook for looking up the tel URNs of contacts whose URNs have changed
+var LookupURNs runner.PostCommitHook = &lookupURNs{}
+
+type lookupURNs struct{}
+
+func (h *lookupURNs) Order() int { return 1 }
+
+func (h *lookupURNs) Execute(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, scenes map[*runner.Scene][]any) error {
+	contactIDs := make([]models.ContactID, 0, len(scenes))
+	for s := range scenes {
+		contactIDs = append(contactIDs, s.ContactID())
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	return lookups.QueueURNLookups(rc, oa, contactIDs)
+}
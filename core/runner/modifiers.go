@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/modifiers"
 	"github.com/nyaruka/mailroom/core/goflow"
 	"github.com/nyaruka/mailroom/core/models"
@@ -22,10 +24,20 @@ func ApplyModifiers(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAsse
 
 	eventsByContact := make(map[*flows.Contact][]flows.Event, len(modifiersByContact))
 
+	validateFields := hasFieldValidation(oa)
+
 	// apply the modifiers to get the events for each contact
 	for contact, mods := range modifiersByContact {
 		events := make([]flows.Event, 0)
 		for _, mod := range mods {
+			// field changes which break validation rules are replaced by warnings
+			if validateFields && mod.Type() == modifiers.TypeField {
+				if warning := validateFieldModifier(eng, env, oa, contact, mod); warning != nil {
+					events = append(events, warning)
+					continue
+				}
+			}
+
 			modifiers.Apply(eng, env, oa.SessionAssets(), contact, mod, func(e flows.Event) { events = append(events, e) })
 		}
 		eventsByContact[contact] = events
@@ -37,3 +49,32 @@ func ApplyModifiers(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAsse
 
 	return eventsByContact, nil
 }
+
+// returns whether any of the org's fields have validation rules
+func hasFieldValidation(oa *models.OrgAssets) bool {
+	fields, _ := oa.Fields()
+	for _, f := range fields {
+		if f.(*models.Field).Validation() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// field modifiers don't expose their field, so to validate one we apply it to a copy of the contact and check the
+// resulting change, returning a warning event if it's invalid
+func validateFieldModifier(eng flows.Engine, env envs.Environment, oa *models.OrgAssets, contact *flows.Contact, mod flows.Modifier) flows.Event {
+	var warning flows.Event
+
+	mod.Apply(eng, env, oa.SessionAssets(), contact.Clone(), func(e flows.Event) {
+		if changed, ok := e.(*events.ContactFieldChangedEvent); ok {
+			if field := oa.FieldByKey(changed.Field.Key); field != nil {
+				if err := field.Validate(changed.Value); err != nil {
+					warning = events.NewWarning(err.Error())
+				}
+			}
+		}
+	})
+
+	return warning
+}
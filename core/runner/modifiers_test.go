@@ -0,0 +1,56 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyModifiersWithFieldValidation(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	rt.DB.MustExec(`UPDATE contacts_contactfield SET validation = '{"min": 18}' WHERE id = $1`, testdata.AgeField.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET fields = '{}' WHERE id = $1`, testdata.Cathy.ID)
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshFields)
+	require.NoError(t, err)
+
+	_, cathy, _ := testdata.Cathy.Load(rt, oa)
+	_, bob, _ := testdata.Bob.Load(rt, oa)
+
+	age := oa.SessionAssets().Fields().Get("age")
+
+	eventsByContact, err := runner.ApplyModifiers(ctx, rt, oa, testdata.Admin.ID, map[*flows.Contact][]flows.Modifier{
+		cathy: {modifiers.NewField(age, "12"), modifiers.NewName("Catherine")},
+		bob:   {modifiers.NewField(age, "40")},
+	})
+	require.NoError(t, err)
+
+	// Cathy's invalid age is replaced by a warning but her name is still changed
+	if assert.Len(t, eventsByContact[cathy], 2) {
+		assert.Equal(t, events.TypeWarning, eventsByContact[cathy][0].Type())
+		assert.Equal(t, "'12' is less than the minimum of 18 for contact field 'age'", eventsByContact[cathy][0].(*events.WarningEvent).Text)
+		assert.Equal(t, events.TypeContactNameChanged, eventsByContact[cathy][1].Type())
+	}
+	assert.Nil(t, cathy.Fields().Get(age))
+
+	if assert.Len(t, eventsByContact[bob], 1) {
+		assert.Equal(t, events.TypeContactFieldChanged, eventsByContact[bob][0].Type())
+	}
+
+	assertdb.Query(t, rt.DB, `SELECT name, fields->$2 IS NULL AS no_age FROM contacts_contact WHERE id = $1`, testdata.Cathy.ID, testdata.AgeField.UUID).
+		Columns(map[string]any{"name": "Catherine", "no_age": true})
+	assertdb.Query(t, rt.DB, `SELECT fields->$2->>'text' FROM contacts_contact WHERE id = $1`, testdata.Bob.ID, testdata.AgeField.UUID).Returns("40")
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacthistory WHERE contact_id = $1 AND event_type = 'warning'`, testdata.Cathy.ID).Returns(1)
+}
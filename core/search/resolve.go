@@ -19,14 +19,15 @@ type Recipients struct {
 	ExcludeGroupIDs []models.GroupID
 }
 
-// ResolveRecipients resolves a set of contacts, groups, urns etc into a set of unique contacts
-func ResolveRecipients(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, userID models.UserID, flow *models.Flow, recipients *Recipients, limit int) ([]models.ContactID, error) {
+// ResolveRecipients resolves a set of contacts, groups, urns etc into a set of unique contacts. Also returns the ids of
+// any contacts which were created for URNs, whether or not they are included, so that callers can initialize them.
+func ResolveRecipients(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, userID models.UserID, flow *models.Flow, recipients *Recipients, limit int) ([]models.ContactID, []models.ContactID, error) {
 	idsSeen := make(map[models.ContactID]bool)
 
 	// start by loading the explicitly listed contacts
 	includeContacts, err := models.LoadContacts(ctx, rt.DB, oa, recipients.ContactIDs)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for _, c := range includeContacts {
 		idsSeen[c.ID()] = true
@@ -39,7 +40,7 @@ func ResolveRecipients(ctx context.Context, rt *runtime.Runtime, oa *models.OrgA
 	if len(recipients.URNs) > 0 {
 		fetchedByURN, createdByURN, err := models.GetOrCreateContactsFromURNs(ctx, rt.DB, oa, userID, recipients.URNs)
 		if err != nil {
-			return nil, fmt.Errorf("error getting contact ids from urns: %w", err)
+			return nil, nil, fmt.Errorf("error getting contact ids from urns: %w", err)
 		}
 		for _, c := range fetchedByURN {
 			if !idsSeen[c.ID()] {
@@ -67,6 +68,11 @@ func ResolveRecipients(ctx context.Context, rt *runtime.Runtime, oa *models.OrgA
 		}
 	}
 
+	createdIDs := make([]models.ContactID, 0, len(createdContacts))
+	for _, c := range createdContacts {
+		createdIDs = append(createdIDs, c.ID())
+	}
+
 	var matches []models.ContactID
 
 	// if we're only including individual contacts and there are no exclusions, we can just return those contacts
@@ -75,10 +81,8 @@ func ResolveRecipients(ctx context.Context, rt *runtime.Runtime, oa *models.OrgA
 		for _, c := range includeContacts {
 			matches = append(matches, c.ID())
 		}
-		for _, c := range createdContacts {
-			matches = append(matches, c.ID())
-		}
-		return matches, nil
+		matches = append(matches, createdIDs...)
+		return matches, createdIDs, nil
 	}
 
 	if len(includeContacts) > 0 || len(includeGroups) > 0 || recipients.Query != "" {
@@ -90,22 +94,20 @@ func ResolveRecipients(ctx context.Context, rt *runtime.Runtime, oa *models.OrgA
 
 		query, err := BuildRecipientsQuery(oa, flow, includeGroups, includeContactUUIDs, recipients.Query, recipients.Exclusions, excludeGroups)
 		if err != nil {
-			return nil, fmt.Errorf("error building query: %w", err)
+			return nil, nil, fmt.Errorf("error building query: %w", err)
 		}
 
 		matches, err = GetContactIDsForQuery(ctx, rt, oa, nil, models.ContactStatusActive, query, limit)
 		if err != nil {
-			return nil, fmt.Errorf("error performing contact search: %w", err)
+			return nil, nil, fmt.Errorf("error performing contact search: %w", err)
 		}
 	}
 
 	// only add created contacts if not excluding contacts based on last seen - other exclusions can't apply to a newly
 	// created contact
 	if recipients.Exclusions.NotSeenSinceDays == 0 {
-		for _, c := range createdContacts {
-			matches = append(matches, c.ID())
-		}
+		matches = append(matches, createdIDs...)
 	}
 
-	return matches, nil
+	return matches, createdIDs, nil
 }
@@ -24,10 +24,11 @@ func TestResolveRecipients(t *testing.T) {
 	require.NoError(t, err)
 
 	tcs := []struct {
-		flow        *testdata.Flow
-		recipients  *search.Recipients
-		limit       int
-		expectedIDs []models.ContactID
+		flow               *testdata.Flow
+		recipients         *search.Recipients
+		limit              int
+		expectedIDs        []models.ContactID
+		expectedCreatedIDs []models.ContactID
 	}{
 		{ // 0 nobody
 			recipients:  &search.Recipients{},
@@ -70,16 +71,18 @@ func TestResolveRecipients(t *testing.T) {
 				URNs:       []urns.URN{"tel:+1234000001", "tel:+1234000002"},
 				Exclusions: models.Exclusions{InAFlow: true},
 			},
-			limit:       -1,
-			expectedIDs: []models.ContactID{testdata.Bob.ID, 30000, 30001},
+			limit:              -1,
+			expectedIDs:        []models.ContactID{testdata.Bob.ID, 30000, 30001},
+			expectedCreatedIDs: []models.ContactID{30000, 30001},
 		},
 		{ // 6 new contacts not included if excluding based on last seen
 			recipients: &search.Recipients{
 				URNs:       []urns.URN{"tel:+1234000003"},
 				Exclusions: models.Exclusions{NotSeenSinceDays: 10},
 			},
-			limit:       -1,
-			expectedIDs: []models.ContactID{},
+			limit:              -1,
+			expectedIDs:        []models.ContactID{},
+			expectedCreatedIDs: []models.ContactID{30002},
 		},
 		{ // 7 new contacts is now an existing contact that can be searched
 			recipients: &search.Recipients{
@@ -98,8 +101,9 @@ func TestResolveRecipients(t *testing.T) {
 			flow = tc.flow.Load(rt, oa)
 		}
 
-		actualIDs, err := search.ResolveRecipients(ctx, rt, oa, testdata.Admin.ID, flow, tc.recipients, tc.limit)
+		actualIDs, createdIDs, err := search.ResolveRecipients(ctx, rt, oa, testdata.Admin.ID, flow, tc.recipients, tc.limit)
 		assert.NoError(t, err)
 		assert.ElementsMatch(t, tc.expectedIDs, actualIDs, "contact ids mismatch in %d", i)
+		assert.ElementsMatch(t, tc.expectedCreatedIDs, createdIDs, "created contact ids mismatch in %d", i)
 	}
 }
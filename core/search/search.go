@@ -161,8 +161,56 @@ func GetContactIDsForQueryPage(ctx context.Context, rt *runtime.Runtime, oa *mod
 
 // GetContactIDsForQuery returns up to limit the contact ids that match the given query, sorted by id. Limit of -1 means return all.
 func GetContactIDsForQuery(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, group *models.Group, status models.ContactStatus, query string, limit int) ([]models.ContactID, error) {
-	env := oa.Env()
-	index := rt.Config.ElasticContactsIndex
+	eq, err := buildContactIDsQuery(rt, oa, group, status, query)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]models.ContactID, 0, 100)
+
+	// if limit provided that can be done with single search, do that
+	if limit >= 0 && limit <= 10_000 {
+		src := map[string]any{
+			"_source":          false,
+			"query":            eq,
+			"sort":             []any{elastic.SortBy("id", true)},
+			"from":             0,
+			"size":             limit,
+			"track_total_hits": false,
+		}
+
+		results, err := rt.ES.Search().Index(rt.Config.ElasticContactsIndex).Routing(oa.OrgID().String()).Raw(bytes.NewReader(jsonx.MustMarshal(src))).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error searching ES index: %w", err)
+		}
+		return appendIDsFromHits(ids, results.Hits.Hits), nil
+	}
+
+	// for larger limits we need to iterate through multiple search requests
+	err = iterateContactIDs(ctx, rt, oa, eq, 10_000, func(batch []models.ContactID) error {
+		ids = append(ids, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetContactIDsForQueryBatches calls the given function with each batch of the ids of contacts that match the given
+// query, sorted by id. This allows callers to process very large result sets without holding all ids in memory.
+func GetContactIDsForQueryBatches(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, group *models.Group, status models.ContactStatus, query string, batchSize int, fn func([]models.ContactID) error) error {
+	eq, err := buildContactIDsQuery(rt, oa, group, status, query)
+	if err != nil {
+		return err
+	}
+
+	return iterateContactIDs(ctx, rt, oa, eq, batchSize, fn)
+}
+
+// builds the elastic query for selecting the ids of contacts matching the given query
+func buildContactIDsQuery(rt *runtime.Runtime, oa *models.OrgAssets, group *models.Group, status models.ContactStatus, query string) (elastic.Query, error) {
 	var parsed *contactql.ContactQuery
 	var err error
 
@@ -172,7 +220,7 @@ func GetContactIDsForQuery(ctx context.Context, rt *runtime.Runtime, oa *models.
 
 	// turn into elastic query
 	if query != "" {
-		parsed, err = contactql.ParseQuery(env, query, oa.SessionAssets())
+		parsed, err = contactql.ParseQuery(oa.Env(), query, oa.SessionAssets())
 		if err != nil {
 			return nil, fmt.Errorf("error parsing query: %s: %w", query, err)
 		}
@@ -184,64 +232,51 @@ func GetContactIDsForQuery(ctx context.Context, rt *runtime.Runtime, oa *models.
 		group = nil
 	}
 
-	eq := BuildElasticQuery(oa, group, status, nil, parsed)
-	sort := elastic.SortBy("id", true)
-	ids := make([]models.ContactID, 0, 100)
-
-	// if limit provided that can be done with single search, do that
-	if limit >= 0 && limit <= 10_000 {
-		src := map[string]any{
-			"_source":          false,
-			"query":            eq,
-			"sort":             []any{sort},
-			"from":             0,
-			"size":             limit,
-			"track_total_hits": false,
-		}
-
-		results, err := rt.ES.Search().Index(index).Routing(oa.OrgID().String()).Raw(bytes.NewReader(jsonx.MustMarshal(src))).Do(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("error searching ES index: %w", err)
-		}
-		return appendIDsFromHits(ids, results.Hits.Hits), nil
-	}
+	return BuildElasticQuery(oa, group, status, nil, parsed), nil
+}
 
-	// for larger limits we need to take a point in time and iterate through multiple search requests using search_after
-	pit, err := rt.ES.OpenPointInTime(index).Routing(oa.OrgID().String()).KeepAlive("1m").Do(ctx)
+// takes a point in time and iterates through the matching contacts using search_after, calling the given function with
+// each page of ids
+func iterateContactIDs(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, eq elastic.Query, pageSize int, fn func([]models.ContactID) error) error {
+	pit, err := rt.ES.OpenPointInTime(rt.Config.ElasticContactsIndex).Routing(oa.OrgID().String()).KeepAlive("1m").Do(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating ES point-in-time: %w", err)
+		return fmt.Errorf("error creating ES point-in-time: %w", err)
 	}
 
 	src := map[string]any{
 		"_source":          false,
 		"query":            eq,
-		"sort":             []any{sort},
+		"sort":             []any{elastic.SortBy("id", true)},
 		"pit":              map[string]any{"id": pit.Id, "keep_alive": "1m"},
-		"size":             10_000,
+		"size":             pageSize,
 		"track_total_hits": false,
 	}
 
 	for {
 		results, err := rt.ES.Search().Raw(bytes.NewReader(jsonx.MustMarshal(src))).Do(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("error searching ES index: %w", err)
+			rt.ES.ClosePointInTime().Id(pit.Id).Do(ctx)
+			return fmt.Errorf("error searching ES index: %w", err)
 		}
 
 		if len(results.Hits.Hits) == 0 {
 			break
 		}
 
-		ids = appendIDsFromHits(ids, results.Hits.Hits)
+		if err := fn(appendIDsFromHits(make([]models.ContactID, 0, len(results.Hits.Hits)), results.Hits.Hits)); err != nil {
+			rt.ES.ClosePointInTime().Id(pit.Id).Do(ctx)
+			return err
+		}
 
 		lastHit := results.Hits.Hits[len(results.Hits.Hits)-1]
 		src["search_after"] = lastHit.Sort
 	}
 
 	if _, err := rt.ES.ClosePointInTime().Id(pit.Id).Do(ctx); err != nil {
-		return nil, fmt.Errorf("error closing ES point-in-time: %w", err)
+		return fmt.Errorf("error closing ES point-in-time: %w", err)
 	}
 
-	return ids, nil
+	return nil
 }
 
 // utility to convert search hits to contact IDs and append them to the given slice
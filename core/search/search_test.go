@@ -1,6 +1,7 @@
 package search_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -221,4 +222,28 @@ func TestGetContactIDsForQuery(t *testing.T) {
 			assert.ElementsMatch(t, tc.expectedContacts, ids, "%d: ids mismatch", i)
 		}
 	}
+
+	// large result sets can be fetched in batches
+	batchSizes := []int{}
+	batchedIDs := []models.ContactID{}
+	err = search.GetContactIDsForQueryBatches(ctx, rt, oa, nil, models.ContactStatusActive, "name has cylon", 5000, func(ids []models.ContactID) error {
+		batchSizes = append(batchSizes, len(ids))
+		batchedIDs = append(batchedIDs, ids...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5000, 5000, 3}, batchSizes)
+	assert.Equal(t, cylonIDs, batchedIDs)
+
+	// and an error from the callback stops the iteration
+	numBatches := 0
+	err = search.GetContactIDsForQueryBatches(ctx, rt, oa, nil, models.ContactStatusActive, "name has cylon", 5000, func(ids []models.ContactID) error {
+		numBatches++
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 1, numBatches)
+
+	err = search.GetContactIDsForQueryBatches(ctx, rt, oa, nil, models.ContactStatusActive, "goats > 2", 5000, func(ids []models.ContactID) error { return nil })
+	assert.EqualError(t, err, "error parsing query: goats > 2: can't resolve 'goats' to attribute, scheme or field")
 }
@@ -73,14 +73,21 @@ func (t *BulkCampaignTriggerTask) Perform(ctx context.Context, rt *runtime.Runti
 		return nil
 	}
 
+	var firedIDs []models.ContactID
+	var err error
+
 	if ce.EventType == models.CampaignEventTypeFlow {
-		if err := t.triggerFlow(ctx, rt, oa, ce, contactIDs); err != nil {
-			return err
-		}
+		firedIDs, err = t.triggerFlow(ctx, rt, oa, ce, contactIDs)
 	} else {
-		if err := t.triggerBroadcast(ctx, rt, oa, ce, contactIDs); err != nil {
-			return err
-		}
+		firedIDs, err = t.triggerBroadcast(ctx, rt, oa, ce, contactIDs)
+	}
+	if err != nil {
+		return err
+	}
+
+	// schedule any events which are anchored on this event firing for the contacts it actually fired for
+	if err := models.AddCampaignEventsForEventFire(ctx, rt.DB, oa, ce, firedIDs, dates.Now()); err != nil {
+		return fmt.Errorf("error scheduling events relative to event #%d: %w", ce.ID, err)
 	}
 
 	// store recent fires in redis for this event
@@ -103,23 +110,24 @@ func (t *BulkCampaignTriggerTask) Perform(ctx context.Context, rt *runtime.Runti
 	return nil
 }
 
-func (t *BulkCampaignTriggerTask) triggerFlow(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, ce *models.CampaignEvent, contactIDs []models.ContactID) error {
+// triggers the event's flow for the given contacts, returning the ids of the contacts it was started for
+func (t *BulkCampaignTriggerTask) triggerFlow(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, ce *models.CampaignEvent, contactIDs []models.ContactID) ([]models.ContactID, error) {
 	flow, err := oa.FlowByID(ce.FlowID)
 	if err == models.ErrNotFound {
 		slog.Info("skipping campaign trigger for flow that no longer exists", "event_id", t.EventID, "flow_id", ce.FlowID)
-		return nil
+		return nil, nil
 	}
 	if err != nil {
-		return fmt.Errorf("error loading campaign event flow #%d: %w", ce.FlowID, err)
+		return nil, fmt.Errorf("error loading campaign event flow #%d: %w", ce.FlowID, err)
 	}
 
 	// if this is an ivr flow, we need to create a task to perform the start there
 	if flow.FlowType() == models.FlowTypeVoice {
 		err := handler.TriggerIVRFlow(ctx, rt, oa.OrgID(), flow.ID(), contactIDs, nil)
 		if err != nil {
-			return fmt.Errorf("error triggering ivr flow start: %w", err)
+			return nil, fmt.Errorf("error triggering ivr flow start: %w", err)
 		}
-		return nil
+		return contactIDs, nil
 	}
 
 	flowRef := assets.NewFlowReference(flow.UUID(), flow.Name())
@@ -131,29 +139,40 @@ func (t *BulkCampaignTriggerTask) triggerFlow(ctx context.Context, rt *runtime.R
 		},
 	}
 
-	_, err = runner.StartFlowWithLock(ctx, rt, oa, flow, contactIDs, options, models.NilStartID, nil)
+	sessions, err := runner.StartFlowWithLock(ctx, rt, oa, flow, contactIDs, options, models.NilStartID, nil)
 	if err != nil {
-		return fmt.Errorf("error starting flow for campaign event #%d: %w", ce.ID, err)
+		return nil, fmt.Errorf("error starting flow for campaign event #%d: %w", ce.ID, err)
 	}
 
-	return nil
+	startedIDs := make([]models.ContactID, len(sessions))
+	for i, s := range sessions {
+		startedIDs[i] = s.ContactID()
+	}
+
+	return startedIDs, nil
 }
 
-func (t *BulkCampaignTriggerTask) triggerBroadcast(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, ce *models.CampaignEvent, contactIDs []models.ContactID) error {
+// sends the event's message to the given contacts, returning the ids of the contacts it was created for
+func (t *BulkCampaignTriggerTask) triggerBroadcast(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, ce *models.CampaignEvent, contactIDs []models.ContactID) ([]models.ContactID, error) {
 	// interrupt the contacts if desired
 	if ce.StartMode != models.CampaignEventModePassive {
 		if _, err := models.InterruptSessionsForContacts(ctx, rt.DB, contactIDs); err != nil {
-			return fmt.Errorf("error interrupting contacts: %w", err)
+			return nil, fmt.Errorf("error interrupting contacts: %w", err)
 		}
 	}
 
 	bcast := models.NewBroadcast(oa.OrgID(), ce.Translations, i18n.Language(ce.BaseLanguage), true, models.NilOptInID, nil, contactIDs, nil, "", models.NoExclusions, models.NilUserID)
 	msgs, err := bcast.CreateMessages(ctx, rt, oa, &models.BroadcastBatch{ContactIDs: contactIDs})
 	if err != nil {
-		return fmt.Errorf("error creating campaign event messages: %w", err)
+		return nil, fmt.Errorf("error creating campaign event messages: %w", err)
 	}
 
 	msgio.QueueMessages(ctx, rt, msgs)
 
-	return nil
+	sentIDs := make([]models.ContactID, len(msgs))
+	for i, m := range msgs {
+		sentIDs[i] = m.ContactID()
+	}
+
+	return sentIDs, nil
 }
@@ -1,8 +1,11 @@
 package campaigns_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/dbutil/assertdb"
 	"github.com/nyaruka/gocommon/random"
 	"github.com/nyaruka/mailroom/core/models"
@@ -132,3 +135,35 @@ func TestBulkCampaignTrigger(t *testing.T) {
 	assertdb.Query(t, rt.DB, `SELECT count(*) FROM flows_flowsession WHERE contact_id = $1 AND status = 'I'`, testdata.Cathy.ID).Returns(1)
 	assertdb.Query(t, rt.DB, `SELECT count(*) FROM flows_flowsession WHERE contact_id = $1 AND status = 'I'`, testdata.Alexandria.ID).Returns(1)
 }
+
+func TestBulkCampaignTriggerWithRelativeEvents(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	// create a waiting session for Cathy so that she'll be skipped by event #3
+	testdata.InsertWaitingSession(rt, testdata.Org1, testdata.Cathy, models.FlowTypeVoice, testdata.IVRFlow, models.NilCallID)
+
+	// add an event 3 days after event #3 (Pick A Number, start mode SKIP) actually fires
+	event4 := testdata.InsertCampaignFlowEventRelativeToEvent(rt, testdata.RemindersCampaign, testdata.Favorites, testdata.RemindersEvent3, 3, "D")
+
+	dates.SetNowFunc(dates.NewFixedNow(time.Date(2025, 5, 12, 15, 30, 0, 0, time.UTC)))
+	defer dates.SetNowFunc(time.Now)
+
+	oa := testdata.Org1.Load(rt)
+	assert.Equal(t, []*models.CampaignEvent{oa.CampaignEventByID(event4.ID)}, oa.CampaignEventsRelativeToEventID(testdata.RemindersEvent3.ID))
+
+	task := &campaigns.BulkCampaignTriggerTask{
+		EventID:     testdata.RemindersEvent3.ID,
+		FireVersion: 1,
+		ContactIDs:  []models.ContactID{testdata.Bob.ID, testdata.Cathy.ID},
+	}
+	err := task.Perform(ctx, rt, oa)
+	assert.NoError(t, err)
+
+	// bob gets a fire for the new event but cathy was skipped so doesn't
+	testsuite.AssertContactFires(t, rt, testdata.Bob.ID, map[string]time.Time{
+		fmt.Sprintf("C/%d:1", event4.ID): time.Date(2025, 5, 15, 15, 30, 0, 0, time.UTC),
+	})
+	testsuite.AssertContactFires(t, rt, testdata.Cathy.ID, map[string]time.Time{})
+}
@@ -0,0 +1,111 @@
+package contacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/nyaruka/goflow/contactql"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/search"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+const (
+	// TypeBulkModify is the type of the task to start a bulk modify
+	TypeBulkModify = "bulk_modify"
+
+	bulkModifyBatchSize = 100
+)
+
+func init() {
+	tasks.RegisterType(TypeBulkModify, func() tasks.Task { return &BulkModifyTask{} })
+}
+
+// BulkModifyTask is our task to resolve the contacts of a bulk modify and split them into batches
+type BulkModifyTask struct {
+	ContactBulkModifyID models.ContactBulkModifyID `json:"contact_bulk_modify_id"`
+}
+
+func (t *BulkModifyTask) Type() string {
+	return TypeBulkModify
+}
+
+// Timeout is the maximum amount of time the task can run for
+func (t *BulkModifyTask) Timeout() time.Duration {
+	return time.Minute * 60
+}
+
+func (t *BulkModifyTask) WithAssets() models.Refresh {
+	return models.RefreshGroups
+}
+
+// Perform resolves the contacts to be modified and queues batch tasks to modify them
+func (t *BulkModifyTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets) error {
+	m, err := models.LoadContactBulkModify(ctx, rt.DB, t.ContactBulkModifyID)
+	if err != nil {
+		return err
+	}
+
+	if err := createBulkModifyBatches(ctx, rt, oa, m); err != nil {
+		if ferr := m.SetFinished(ctx, rt.DB, models.ContactBulkModifyStatusFailed); ferr != nil {
+			return errors.Join(err, ferr)
+		}
+
+		// if error is user created query error.. don't escalate error to sentry
+		isQueryError, _ := contactql.IsQueryError(err)
+		if !isQueryError {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createBulkModifyBatches(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, m *models.ContactBulkModify) error {
+	recipients := &search.Recipients{Query: string(m.Query)}
+	if m.GroupID != 0 {
+		recipients.GroupIDs = []models.GroupID{m.GroupID}
+	}
+
+	contactIDs, _, err := search.ResolveRecipients(ctx, rt, oa, m.CreatedByID, nil, recipients, -1)
+	if err != nil {
+		return fmt.Errorf("error resolving bulk modify contacts: %w", err)
+	}
+
+	idBatches := slices.Collect(slices.Chunk(contactIDs, bulkModifyBatchSize))
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	if err := m.SetProcessing(ctx, rt.DB, rc, len(contactIDs), len(idBatches)); err != nil {
+		return err
+	}
+
+	// if there are no contacts to modify, we are done
+	if len(contactIDs) == 0 {
+		return m.SetFinished(ctx, rt.DB, models.ContactBulkModifyStatusComplete)
+	}
+
+	for i, idBatch := range idBatches {
+		err := tasks.Queue(rc, tasks.BatchQueue, m.OrgID, &BulkModifyBatchTask{ContactBulkModifyID: m.ID, ContactIDs: idBatch}, false)
+		if err != nil {
+			if i == 0 {
+				return fmt.Errorf("error queuing bulk modify batch: %w", err)
+			}
+			// if we've already queued other batches.. we don't want to error and have the task be retried, but we do need
+			// to record this batch as errored so that the bulk modify can still finish
+			slog.Error("error queuing bulk modify batch", "bulk_modify_id", m.ID, "error", err)
+
+			if _, err := m.RecordBatch(ctx, rt.DB, rc, 0, 0, len(idBatch)); err != nil {
+				return fmt.Errorf("error recording unqueued bulk modify batch: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/mailroom/core/goflow"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// TypeBulkModifyBatch is the type of the task to modify a batch of contacts in a bulk modify
+const TypeBulkModifyBatch = "bulk_modify_batch"
+
+func init() {
+	tasks.RegisterType(TypeBulkModifyBatch, func() tasks.Task { return &BulkModifyBatchTask{} })
+}
+
+// BulkModifyBatchTask is our task to apply the modifiers of a bulk modify to a batch of contacts
+type BulkModifyBatchTask struct {
+	ContactBulkModifyID models.ContactBulkModifyID `json:"contact_bulk_modify_id"`
+	ContactIDs          []models.ContactID         `json:"contact_ids"`
+}
+
+func (t *BulkModifyBatchTask) Type() string {
+	return TypeBulkModifyBatch
+}
+
+// Timeout is the maximum amount of time the task can run for
+func (t *BulkModifyBatchTask) Timeout() time.Duration {
+	return time.Minute * 10
+}
+
+func (t *BulkModifyBatchTask) WithAssets() models.Refresh {
+	return models.RefreshFields | models.RefreshGroups
+}
+
+// Perform applies the modifiers to the contacts in this batch and records the results
+func (t *BulkModifyBatchTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets) error {
+	m, err := models.LoadContactBulkModify(ctx, rt.DB, t.ContactBulkModifyID)
+	if err != nil {
+		return err
+	}
+
+	numModified, numSkipped, batchErr := t.modify(ctx, rt, oa, m)
+	numErrored := 0
+
+	// if any error occurs, all contacts in this batch that weren't skipped are considered errored
+	if batchErr != nil {
+		slog.Error("error modifying contact bulk modify batch", "bulk_modify_id", m.ID, "error", batchErr)
+
+		numModified, numErrored = 0, len(t.ContactIDs)-numSkipped
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	if _, err := m.RecordBatch(ctx, rt.DB, rc, numModified, numSkipped, numErrored); err != nil {
+		return fmt.Errorf("error recording bulk modify batch: %w", err)
+	}
+
+	return nil
+}
+
+func (t *BulkModifyBatchTask) modify(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, m *models.ContactBulkModify) (int, int, error) {
+	modsJSON, err := m.ModifiersJSON()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// assets may have been deleted since the bulk modify was created
+	mods, err := goflow.ReadModifiers(oa.SessionAssets(), modsJSON, goflow.IgnoreMissing)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	locks, skipped, err := models.LockContacts(ctx, rt, oa.OrgID(), t.ContactIDs, time.Second*10)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer models.UnlockContacts(rt, oa.OrgID(), locks)
+
+	contacts, err := models.LoadContacts(ctx, rt.ReadonlyDB, oa, slices.Collect(maps.Keys(locks)))
+	if err != nil {
+		return 0, len(skipped), fmt.Errorf("error loading contacts: %w", err)
+	}
+
+	modifiersByContact := make(map[*flows.Contact][]flows.Modifier, len(contacts))
+	for _, c := range contacts {
+		fc, err := c.FlowContact(oa)
+		if err != nil {
+			return 0, len(skipped), fmt.Errorf("error creating flow contact: %w", err)
+		}
+
+		modifiersByContact[fc] = mods
+	}
+
+	if _, err := runner.ApplyModifiers(ctx, rt, oa, m.CreatedByID, modifiersByContact); err != nil {
+		return 0, len(skipped), err
+	}
+
+	// contacts which have been deleted since being resolved are counted as skipped
+	return len(contacts), len(t.ContactIDs) - len(contacts), nil
+}
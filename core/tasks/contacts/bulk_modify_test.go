@@ -0,0 +1,51 @@
+package contacts_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkModify(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	group := testdata.InsertContactGroup(rt, testdata.Org1, "e0ab3a7a-a3f4-4b7b-ad2d-3b3b5b0f0a6f", "Movers", "", testdata.Cathy, testdata.Bob, testdata.George)
+
+	// lock George so that he is skipped
+	models.LockContacts(ctx, rt, testdata.Org1.ID, []models.ContactID{testdata.George.ID}, time.Second)
+
+	mods := []json.RawMessage{[]byte(`{"type": "name", "name": "Jim"}`)}
+
+	m := models.NewContactBulkModify(testdata.Org1.ID, testdata.Admin.ID, group.ID, "", mods)
+	require.NoError(t, models.InsertContactBulkModify(ctx, rt.DB, m))
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &contacts.BulkModifyTask{ContactBulkModifyID: m.ID})
+
+	assert.Equal(t, map[string]int{"bulk_modify": 1, "bulk_modify_batch": 1}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+
+	assertdb.Query(t, rt.DB, `SELECT status, num_contacts, num_modified, num_skipped, num_errored FROM contacts_contactbulkmodify WHERE id = $1`, m.ID).
+		Columns(map[string]any{"status": "C", "num_contacts": int64(3), "num_modified": int64(2), "num_skipped": int64(1), "num_errored": int64(0)})
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contact WHERE name = 'Jim'`).Returns(2)
+	assertdb.Query(t, rt.DB, `SELECT name FROM contacts_contact WHERE id = $1`, testdata.George.ID).Returns("George")
+
+	// a query which matches no contacts completes immediately
+	m = models.NewContactBulkModify(testdata.Org1.ID, testdata.Admin.ID, 0, `name = "Nobody"`, mods)
+	require.NoError(t, models.InsertContactBulkModify(ctx, rt.DB, m))
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &contacts.BulkModifyTask{ContactBulkModifyID: m.ID})
+
+	assert.Equal(t, map[string]int{"bulk_modify": 1}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+
+	assertdb.Query(t, rt.DB, `SELECT status, num_contacts FROM contacts_contactbulkmodify WHERE id = $1`, m.ID).
+		Columns(map[string]any{"status": "C", "num_contacts": int64(0)})
+}
@@ -0,0 +1,72 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/goflow/contactql"
+	"github.com/nyaruka/mailroom/core/exports"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// TypeExportContacts is the type of the task to write a contact export
+const TypeExportContacts = "export_contacts"
+
+func init() {
+	tasks.RegisterType(TypeExportContacts, func() tasks.Task { return &ExportContactsTask{} })
+}
+
+// ExportContactsTask is our task to write a contact export file to storage and notify the user who requested it
+type ExportContactsTask struct {
+	ExportID models.ExportID `json:"export_id"`
+}
+
+func (t *ExportContactsTask) Type() string {
+	return TypeExportContacts
+}
+
+// Timeout is the maximum amount of time the task can run for
+func (t *ExportContactsTask) Timeout() time.Duration {
+	return time.Hour * 2
+}
+
+func (t *ExportContactsTask) WithAssets() models.Refresh {
+	return models.RefreshFields | models.RefreshGroups
+}
+
+// Perform writes the export file and notifies the user who requested it
+func (t *ExportContactsTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets) error {
+	e, err := models.LoadExport(ctx, rt.DB, t.ExportID)
+	if err != nil {
+		return err
+	}
+
+	if err := e.SetProcessing(ctx, rt.DB); err != nil {
+		return err
+	}
+
+	numRecords, path, err := exports.ExportContacts(ctx, rt, oa, e)
+	if err != nil {
+		e.SetFailed(ctx, rt.DB)
+
+		// if error is user created query error.. don't escalate error to sentry
+		isQueryError, _ := contactql.IsQueryError(err)
+		if !isQueryError {
+			return fmt.Errorf("error exporting contacts: %w", err)
+		}
+		return nil
+	}
+
+	if err := e.SetComplete(ctx, rt.DB, numRecords, path); err != nil {
+		return err
+	}
+
+	if err := models.NotifyExportFinished(ctx, rt.DB, e); err != nil {
+		return fmt.Errorf("error creating export finished notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package contacts_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportContacts(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	e := models.NewContactExport(testdata.Org1.ID, testdata.Admin.ID, &models.ContactExportConfig{
+		GroupID:    testdata.ActiveGroup.ID,
+		Query:      "cathy OR bob",
+		Format:     models.ExportFormatCSV,
+		Fields:     []string{"gender"},
+		URNSchemes: []string{"tel"},
+		GroupIDs:   []models.GroupID{testdata.DoctorsGroup.ID},
+	})
+	require.NoError(t, models.InsertExport(ctx, rt.DB, e))
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &contacts.ExportContactsTask{ExportID: e.ID})
+
+	assert.Equal(t, map[string]int{"export_contacts": 1}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+
+	path := fmt.Sprintf("orgs/%d/exports/%s.csv", testdata.Org1.ID, e.UUID)
+
+	assertdb.Query(t, rt.DB, `SELECT status, num_records, path FROM orgs_export WHERE id = $1`, e.ID).
+		Columns(map[string]any{"status": "C", "num_records": int64(2), "path": path})
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM notifications_notification WHERE notification_type = 'export:finished' AND export_id = $1 AND user_id = $2`, e.ID, testdata.Admin.ID).Returns(1)
+
+	contentType, body, err := rt.S3.GetObject(ctx, rt.Config.S3AttachmentsBucket, path)
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", contentType)
+	assert.Contains(t, string(body), "Contact UUID,Name,Language,Status,Created On,Last Seen On,URN:Tel,Field:Gender,Group:Doctors\n")
+	assert.Contains(t, string(body), fmt.Sprintf("%s,Cathy,", testdata.Cathy.UUID))
+	assert.Contains(t, string(body), "+16055741111,F,true\n")
+	assert.Contains(t, string(body), fmt.Sprintf("%s,Bob,", testdata.Bob.UUID))
+
+	// an invalid query fails the export without erroring the task
+	e = models.NewContactExport(testdata.Org1.ID, testdata.Admin.ID, &models.ContactExportConfig{
+		GroupID: testdata.ActiveGroup.ID,
+		Query:   "xyz = 123",
+		Format:  models.ExportFormatNDJSON,
+	})
+	require.NoError(t, models.InsertExport(ctx, rt.DB, e))
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &contacts.ExportContactsTask{ExportID: e.ID})
+
+	assert.Equal(t, map[string]int{"export_contacts": 1}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+
+	assertdb.Query(t, rt.DB, `SELECT status FROM orgs_export WHERE id = $1`, e.ID).Returns("F")
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM notifications_notification WHERE export_id = $1`, e.ID).Returns(0)
+}
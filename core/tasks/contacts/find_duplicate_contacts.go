@@ -0,0 +1,82 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// TypeFindDuplicateContacts is the type of the find duplicate contacts task
+const TypeFindDuplicateContacts = "find_duplicate_contacts"
+
+func init() {
+	tasks.RegisterType(TypeFindDuplicateContacts, func() tasks.Task { return &FindDuplicateContactsTask{} })
+}
+
+// FindDuplicateContactsTask is our task to scan an org for contacts which are probably the same person
+type FindDuplicateContactsTask struct {
+	DOBField   string `json:"dob_field,omitempty"`   // key of the date of birth field to match with name
+	EmailField string `json:"email_field,omitempty"` // key of the email field to match
+}
+
+func (t *FindDuplicateContactsTask) Type() string {
+	return TypeFindDuplicateContacts
+}
+
+// Timeout is the maximum amount of time the task can run for
+func (t *FindDuplicateContactsTask) Timeout() time.Duration {
+	return time.Hour
+}
+
+func (t *FindDuplicateContactsTask) WithAssets() models.Refresh {
+	return models.RefreshFields
+}
+
+// Perform finds candidate duplicate contacts and stores them for review
+func (t *FindDuplicateContactsTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets) error {
+	start := time.Now()
+
+	dupes, err := models.FindDuplicateContactsByPhone(ctx, rt.ReadonlyDB, oa.OrgID())
+	if err != nil {
+		return err
+	}
+
+	if t.DOBField != "" {
+		field := oa.FieldByKey(t.DOBField)
+		if field == nil {
+			return fmt.Errorf("no such field: %s", t.DOBField)
+		}
+
+		byNameAndDOB, err := models.FindDuplicateContactsByNameAndDOB(ctx, rt.ReadonlyDB, oa.OrgID(), field)
+		if err != nil {
+			return err
+		}
+		dupes = append(dupes, byNameAndDOB...)
+	}
+
+	if t.EmailField != "" {
+		field := oa.FieldByKey(t.EmailField)
+		if field == nil {
+			return fmt.Errorf("no such field: %s", t.EmailField)
+		}
+
+		byEmail, err := models.FindDuplicateContactsByEmail(ctx, rt.ReadonlyDB, oa.OrgID(), field)
+		if err != nil {
+			return err
+		}
+		dupes = append(dupes, byEmail...)
+	}
+
+	if err := models.InsertContactDuplicates(ctx, rt.DB, dupes); err != nil {
+		return err
+	}
+
+	slog.Info("completed finding duplicate contacts", "org_id", oa.OrgID(), "elapsed", time.Since(start), "found", len(dupes))
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package contacts_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicateContacts(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	oa := testdata.Org1.Load(rt)
+
+	// give Bob a whatsapp URN with the same number as Cathy's tel URN
+	testdata.InsertContactURN(rt, testdata.Org1, testdata.Bob, urns.URN("whatsapp:16055741111"), 500, nil)
+
+	// George and Alexandria have the same name and joined date, Cathy and Alexandria have the same email (gender field)
+	rt.DB.MustExec(`UPDATE contacts_contact SET fields = '{"3a5891e4-756e-4dc9-8e12-b7a766168824": {"text": "Jo@Example.com"}}' WHERE id = $1`, testdata.Cathy.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET fields = NULL WHERE id = $1`, testdata.Bob.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET name = 'Jo Smith', fields = '{"d83aae24-4bbf-49d0-ab85-6bfd201eac6d": {"text": "2000-01-01", "datetime": "2000-01-01T00:00:00Z"}}' WHERE id = $1`, testdata.George.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET name = 'jo smith ', fields = '{"d83aae24-4bbf-49d0-ab85-6bfd201eac6d": {"text": "2000-01-01", "datetime": "2000-01-01T00:00:00Z"}, "3a5891e4-756e-4dc9-8e12-b7a766168824": {"text": "jo@example.com "}}' WHERE id = $1`, testdata.Alexandria.ID)
+
+	// phone matching only
+	task := &contacts.FindDuplicateContactsTask{}
+	err := task.Perform(ctx, rt, oa)
+	require.NoError(t, err)
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactduplicate`).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactduplicate WHERE contact_a_id = $1 AND contact_b_id = $2 AND reason = 'phone' AND confidence = 0.9`, testdata.Cathy.ID, testdata.Bob.ID).Returns(1)
+
+	// with name + DOB and email matching, and existing pairs aren't duplicated
+	task = &contacts.FindDuplicateContactsTask{DOBField: "joined", EmailField: "gender"}
+	err = task.Perform(ctx, rt, oa)
+	require.NoError(t, err)
+
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactduplicate`).Returns(3)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactduplicate WHERE contact_a_id = $1 AND contact_b_id = $2 AND reason = 'name_dob'`, testdata.George.ID, testdata.Alexandria.ID).Returns(1)
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactduplicate WHERE contact_a_id = $1 AND contact_b_id = $2 AND reason = 'email'`, testdata.Cathy.ID, testdata.Alexandria.ID).Returns(1)
+
+	// unknown field is an error
+	task = &contacts.FindDuplicateContactsTask{EmailField: "email"}
+	err = task.Perform(ctx, rt, oa)
+	assert.EqualError(t, err, "no such field: email")
+}
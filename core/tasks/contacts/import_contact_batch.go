@@ -3,12 +3,18 @@ package contacts
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/mailroom/core/imports"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
 	"github.com/nyaruka/mailroom/runtime"
 )
 
@@ -51,14 +57,18 @@ func (t *ImportContactBatchTask) Perform(ctx context.Context, rt *runtime.Runtim
 
 	batchErr := imports.ImportBatch(ctx, rt, oa, batch, imp.CreatedByID)
 
+	// decrement the redis key that holds remaining batches to see if the overall import is now finished
+	rc := rt.RP.Get()
+	defer rc.Close()
+
 	// if any error occurs this batch should be marked as failed
 	if batchErr != nil {
 		batch.SetFailed(ctx, rt.DB)
+	} else if oa.Org().HasNumberLookup() {
+		if err := queueImportedURNLookups(ctx, rt, rc, oa, batch); err != nil {
+			slog.Error("error queuing lookups for imported URNs", "org_id", oa.OrgID(), "batch_id", batch.ID, "error", err)
+		}
 	}
-
-	// decrement the redis key that holds remaining batches to see if the overall import is now finished
-	rc := rt.RP.Get()
-	defer rc.Close()
 	remaining, _ := redis.Int(rc.Do("decr", fmt.Sprintf("contact_import_batches_remaining:%d", batch.ImportID)))
 	if remaining == 0 {
 		// if any batch failed, then import is considered failed
@@ -85,3 +95,34 @@ func (t *ImportContactBatchTask) Perform(ctx context.Context, rt *runtime.Runtim
 
 	return nil
 }
+
+// queues lookups of the tel URNs of the contacts in an imported batch
+func queueImportedURNLookups(ctx context.Context, rt *runtime.Runtime, rc redis.Conn, oa *models.OrgAssets, batch *models.ContactImportBatch) error {
+	var specs []*models.ContactSpec
+	if err := jsonx.Unmarshal(batch.Specs, &specs); err != nil {
+		return fmt.Errorf("error unmarsaling specs: %w", err)
+	}
+
+	telURNs := make([]urns.URN, 0, len(specs))
+	for _, spec := range specs {
+		for _, u := range spec.URNs {
+			if u.Scheme() == urns.Phone.Prefix {
+				telURNs = append(telURNs, u.Normalize())
+			}
+		}
+	}
+
+	owners, err := models.GetContactIDsFromURNs(ctx, rt.DB, oa.OrgID(), telURNs)
+	if err != nil {
+		return err
+	}
+
+	contactIDs := make(map[models.ContactID]bool, len(owners))
+	for _, id := range owners {
+		if id != models.NilContactID {
+			contactIDs[id] = true
+		}
+	}
+
+	return lookups.QueueURNLookups(rc, oa, slices.Sorted(maps.Keys(contactIDs)))
+}
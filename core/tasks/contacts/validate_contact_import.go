@@ -0,0 +1,52 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/mailroom/core/imports"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// TypeValidateContactImport is the type of the validate contact import task
+const TypeValidateContactImport = "validate_contact_import"
+
+func init() {
+	tasks.RegisterType(TypeValidateContactImport, func() tasks.Task { return &ValidateContactImportTask{} })
+}
+
+// ValidateContactImportTask is our task to validate all the batches of a contact import without importing anything
+type ValidateContactImportTask struct {
+	ContactImportID models.ContactImportID `json:"contact_import_id"`
+}
+
+func (t *ValidateContactImportTask) Type() string {
+	return TypeValidateContactImport
+}
+
+// Timeout is the maximum amount of time the task can run for
+func (t *ValidateContactImportTask) Timeout() time.Duration {
+	return time.Minute * 60
+}
+
+func (t *ValidateContactImportTask) WithAssets() models.Refresh {
+	return models.RefreshFields | models.RefreshGroups
+}
+
+// Perform validates the import and records the result on it
+func (t *ValidateContactImportTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets) error {
+	imp, err := models.LoadContactImport(ctx, rt.DB, t.ContactImportID)
+	if err != nil {
+		return fmt.Errorf("error loading contact import: %w", err)
+	}
+
+	validation, err := imports.ValidateImport(ctx, rt, oa, imp.ID)
+	if err != nil {
+		return fmt.Errorf("error validating contact import: %w", err)
+	}
+
+	return imp.SetValidation(ctx, rt.DB, validation)
+}
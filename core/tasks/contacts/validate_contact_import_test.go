@@ -0,0 +1,33 @@
+package contacts_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+)
+
+func TestValidateContactImport(t *testing.T) {
+	_, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData | testsuite.ResetRedis)
+
+	importID := testdata.InsertContactImport(rt, testdata.Org1, testdata.Admin)
+	testdata.InsertContactImportBatch(rt, importID, []byte(`[
+		{"name": "Norbert", "language": "eng", "urns": ["tel:+16055740001"], "_import_row": 2},
+		{"name": "Leah", "language": "xx", "urns": ["tel:+16055740002"], "_import_row": 3}
+	]`))
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &contacts.ValidateContactImportTask{ContactImportID: importID})
+	testsuite.FlushTasks(t, rt)
+
+	assertdb.Query(t, rt.DB, `SELECT validation->>'num_records' AS records, validation->>'num_created' AS created, validation->>'num_errored' AS errored FROM contacts_contactimport WHERE id = $1`, importID).
+		Columns(map[string]any{"records": "2", "created": "2", "errored": "0"})
+	assertdb.Query(t, rt.DB, `SELECT validation->'errors'->0->>'message' FROM contacts_contactimport WHERE id = $1`, importID).
+		Returns("'xx' is not a valid language code")
+
+	// nothing should have been imported
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE identity = 'tel:+16055740001'`).Returns(0)
+}
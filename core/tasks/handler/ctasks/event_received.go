@@ -102,6 +102,10 @@ func (t *EventReceivedTask) handle(ctx context.Context, rt *runtime.Runtime, oa
 		if err != nil {
 			return nil, fmt.Errorf("unable to initialize new contact: %w", err)
 		}
+
+		if err := queueURNLookups(rt, oa, mc); err != nil {
+			return nil, err
+		}
 	}
 
 	// do we have associated trigger?
@@ -3,19 +3,27 @@ package ctasks
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/jmoiron/sqlx"
+	"github.com/nyaruka/gocommon/i18n"
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
 	"github.com/nyaruka/goflow/flows/resumes"
-	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/utils"
+	"github.com/nyaruka/mailroom/core/langdetect"
+	"github.com/nyaruka/mailroom/core/media"
 	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/moderation"
 	"github.com/nyaruka/mailroom/core/msgio"
 	"github.com/nyaruka/mailroom/core/runner"
 	"github.com/nyaruka/mailroom/core/tasks/handler"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
 	"github.com/nyaruka/mailroom/runtime"
 	"github.com/nyaruka/mailroom/utils/clogs"
 )
@@ -49,6 +57,31 @@ func (t *MsgReceivedTask) UseReadOnly() bool {
 func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, mc *models.Contact) error {
 	channel := oa.ChannelByID(t.ChannelID)
 
+	// if this is a redelivery of a message we've already received, ignore it before doing any work like fetching its
+	// attachments, but mark it as handled and archived
+	if channel != nil {
+		rc := rt.RP.Get()
+		duplicate, err := models.IsDuplicateMsgIn(rc, channel, t.MsgID, t.URN, t.MsgExternalID, t.Text, t.Attachments)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("error checking for duplicate message: %w", err)
+		}
+		if duplicate {
+			rt.Stats.RecordDuplicateMsg()
+
+			attachments := make([]utils.Attachment, len(t.Attachments))
+			for i, a := range t.Attachments {
+				attachments[i] = utils.Attachment(a)
+			}
+
+			err := models.MarkMessageHandled(ctx, rt.DB, t.MsgID, models.MsgStatusHandled, models.VisibilityArchived, models.NilFlowID, models.NilTicketID, attachments, nil)
+			if err != nil {
+				return fmt.Errorf("error updating duplicate message: %w", err)
+			}
+			return nil
+		}
+	}
+
 	// fetch the attachments on the message (i.e. ask courier to fetch them)
 	attachments := make([]utils.Attachment, 0, len(t.Attachments))
 	logUUIDs := make([]clogs.UUID, 0, len(t.Attachments))
@@ -80,6 +113,25 @@ func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *
 		return nil
 	}
 
+	// contacts created by courier for this message haven't had their tel URNs looked up yet
+	if t.NewContact {
+		if err := queueURNLookups(rt, oa, mc); err != nil {
+			return err
+		}
+	}
+
+	// run attachment processors which may reject attachments or extract text from them, e.g. transcribing voice notes
+	attachments, extractedText, err := media.Process(ctx, rt, oa, attachments)
+	if err != nil {
+		return fmt.Errorf("error processing attachments: %w", err)
+	}
+
+	// flow sees any extracted text as part of the message text
+	text := t.Text
+	if extractedText != "" {
+		text = strings.TrimSpace(text + "\n" + extractedText)
+	}
+
 	// flow will only see the attachments we were able to fetch
 	availableAttachments := make([]utils.Attachment, 0, len(attachments))
 	for _, att := range attachments {
@@ -88,7 +140,7 @@ func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *
 		}
 	}
 
-	msgIn := flows.NewMsgIn(t.MsgUUID, t.URN, channel.Reference(), t.Text, availableAttachments, string(t.MsgExternalID))
+	msgIn := flows.NewMsgIn(t.MsgUUID, t.URN, channel.Reference(), text, availableAttachments, string(t.MsgExternalID))
 
 	// if we have URNs make sure the message URN is our highest priority (this is usually a noop)
 	if len(mc.URNs()) > 0 {
@@ -121,18 +173,85 @@ func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *
 		}
 	}
 
-	// look up any open tickes for this contact and forward this message to that
+	// if contact is sending us messages faster than allowed, don't let them start or resume flows
+	limited, blocked, err := t.checkRateLimit(ctx, rt, oa, channel, mc, fc)
+	if err != nil {
+		return err
+	}
+
+	// check the message against any moderation policy, which may label it, open a ticket or give us a safeguarding
+	// flow. This happens even for rate limited contacts as they may be in distress.
+	safeguardFlow, err := t.moderate(ctx, rt, oa, fc, msgIn)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		err := models.MarkMessageHandled(ctx, rt.DB, t.MsgID, models.MsgStatusHandled, models.VisibilityArchived, models.NilFlowID, models.NilTicketID, attachments, logUUIDs)
+		if err != nil {
+			return fmt.Errorf("error updating message for blocked contact: %w", err)
+		}
+		return nil
+	}
+
+	// detect the language of the message which may also set the contact's language if they don't have one
+	if !limited {
+		if err := t.detectLanguage(ctx, rt, oa, fc, msgIn); err != nil {
+			return err
+		}
+	}
+
+	// look up any open tickes for this contact (which moderation may have just opened) and forward this message to that
 	ticket, err := models.LoadOpenTicketForContact(ctx, rt.DB, mc)
 	if err != nil {
 		return fmt.Errorf("unable to look up open tickets for contact: %w", err)
 	}
 
+	var flow *models.Flow
+
+	sceneInit := func(scene *runner.Scene) {
+		scene.IncomingMsg = &models.MsgInRef{ID: t.MsgID, ExtID: t.MsgExternalID}
+	}
+
+	// build our hook to mark a flow message as handled
+	flowMsgHook := func(ctx context.Context, tx *sqlx.Tx, rp *redis.Pool, oa *models.OrgAssets, sessions []*models.Session) error {
+		// set our incoming message event on our session
+		if len(sessions) != 1 {
+			return fmt.Errorf("handle hook called with more than one session")
+		}
+		return t.markMsgHandled(ctx, tx, flow, attachments, ticket, logUUIDs)
+	}
+
+	// if moderation flagged this message and there's a safeguarding flow, that takes precedence over rate limiting,
+	// triggers and sessions
+	if safeguardFlow != nil {
+		flow = safeguardFlow
+
+		flowTrigger, err := models.NewMsgTrigger(oa, flow, fc, msgIn, nil, "", nil)
+		if err != nil {
+			return fmt.Errorf("error building msg trigger: %w", err)
+		}
+
+		_, err = runner.StartFlow(ctx, rt, oa, flow, []*models.Contact{mc}, []flows.Trigger{flowTrigger}, true, models.NilStartID, sceneInit, flowMsgHook)
+		if err != nil {
+			return fmt.Errorf("error starting safeguarding flow for contact: %w", err)
+		}
+		return nil
+	}
+
+	// rate limited messages can't trigger or resume flows so are handled as inbox
+	if limited {
+		if err = t.handleAsInbox(ctx, rt, oa, fc, msgIn, attachments, logUUIDs, ticket); err != nil {
+			return fmt.Errorf("error handling inbox message: %w", err)
+		}
+		return nil
+	}
+
 	// find any matching triggers
-	trigger, keyword := models.FindMatchingMsgTrigger(oa, channel, fc, t.Text)
+	trigger, keyword, params := models.FindMatchingMsgTrigger(oa, channel, fc, t.Text)
 
 	// look for a waiting session for this contact
 	var session *models.Session
-	var flow *models.Flow
 
 	if mc.CurrentSessionUUID() != "" {
 		session, err = models.GetWaitingSessionForContact(ctx, rt, oa, fc, mc.CurrentSessionUUID())
@@ -160,19 +279,6 @@ func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *
 		}
 	}
 
-	sceneInit := func(scene *runner.Scene) {
-		scene.IncomingMsg = &models.MsgInRef{ID: t.MsgID, ExtID: t.MsgExternalID}
-	}
-
-	// build our hook to mark a flow message as handled
-	flowMsgHook := func(ctx context.Context, tx *sqlx.Tx, rp *redis.Pool, oa *models.OrgAssets, sessions []*models.Session) error {
-		// set our incoming message event on our session
-		if len(sessions) != 1 {
-			return fmt.Errorf("handle hook called with more than one session")
-		}
-		return t.markMsgHandled(ctx, tx, flow, attachments, ticket, logUUIDs)
-	}
-
 	// we found a trigger and their session is nil or doesn't ignore keywords
 	if (trigger != nil && trigger.TriggerType() != models.CatchallTriggerType && (flow == nil || !flow.IgnoreTriggers())) ||
 		(trigger != nil && trigger.TriggerType() == models.CatchallTriggerType && (flow == nil)) {
@@ -196,15 +302,13 @@ func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *
 				return nil
 			}
 
-			tb := triggers.NewBuilder(oa.Env(), flow.Reference(), fc).Msg(msgIn)
-			if keyword != "" {
-				tb = tb.WithMatch(&triggers.KeywordMatch{Type: trigger.KeywordMatchType(), Keyword: keyword})
-			}
-
 			// otherwise build the trigger and start the flow directly
-			trigger := tb.Build()
+			flowTrigger, err := models.NewMsgTrigger(oa, flow, fc, msgIn, trigger, keyword, params)
+			if err != nil {
+				return fmt.Errorf("error building msg trigger: %w", err)
+			}
 
-			_, err = runner.StartFlow(ctx, rt, oa, flow, []*models.Contact{mc}, []flows.Trigger{trigger}, flow.FlowType().Interrupts(), models.NilStartID, sceneInit, flowMsgHook)
+			_, err = runner.StartFlow(ctx, rt, oa, flow, []*models.Contact{mc}, []flows.Trigger{flowTrigger}, flow.FlowType().Interrupts(), models.NilStartID, sceneInit, flowMsgHook)
 			if err != nil {
 				return fmt.Errorf("error starting flow for contact: %w", err)
 			}
@@ -229,6 +333,114 @@ func (t *MsgReceivedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *
 	return nil
 }
 
+// checks the contact against any inbound rate limit, blocking them if they've exceeded it too many times
+func (t *MsgReceivedTask) checkRateLimit(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, channel *models.Channel, mc *models.Contact, fc *flows.Contact) (bool, bool, error) {
+	limit := models.GetInboundRateLimit(oa, channel)
+	if limit == nil {
+		return false, false, nil
+	}
+
+	rc := rt.RP.Get()
+	exceeded, err := models.CheckInboundRateLimit(rc, limit, channel, mc.ID())
+	rc.Close()
+	if err != nil {
+		return false, false, err
+	}
+	if exceeded == 0 {
+		return false, false, nil
+	}
+
+	rt.Stats.RecordRateLimitedMsg()
+
+	if !limit.ShouldBlock(exceeded) {
+		return true, false, nil
+	}
+
+	mods := map[*flows.Contact][]flows.Modifier{fc: {modifiers.NewStatus(flows.ContactStatusBlocked)}}
+	if _, err := runner.ApplyModifiers(ctx, rt, oa, models.NilUserID, mods); err != nil {
+		return false, false, fmt.Errorf("error blocking rate limited contact: %w", err)
+	}
+
+	if err := models.NotifyContactRateLimited(ctx, rt.DB, oa, mc); err != nil {
+		return false, false, fmt.Errorf("error notifying of rate limited contact: %w", err)
+	}
+
+	return true, true, nil
+}
+
+// detects the language of the message and records it in the message metadata. If the contact doesn't have a language,
+// the detector is reliable and we're confident enough in the detected language, it's set as their language.
+func (t *MsgReceivedTask) detectLanguage(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, fc *flows.Contact, msgIn *flows.MsgIn) error {
+	config, detected, err := langdetect.Detect(ctx, rt, oa, msgIn.Text())
+	if err != nil {
+		// detection failing shouldn't stop the message being handled
+		slog.Error("error detecting language of incoming message", "org_id", oa.OrgID(), "msg_id", t.MsgID, "error", err)
+		return nil
+	}
+	if detected == nil {
+		return nil
+	}
+
+	if err := models.UpdateMsgDetectedLanguage(ctx, rt.DB, t.MsgID, detected); err != nil {
+		return err
+	}
+
+	allowed := oa.Env().AllowedLanguages()
+
+	if fc.Language() == i18n.NilLanguage && langdetect.IsReliable(config.Detector) && detected.Confidence >= config.MinConfidence && (len(allowed) == 0 || slices.Contains(allowed, detected.Language)) {
+		mods := map[*flows.Contact][]flows.Modifier{fc: {modifiers.NewLanguage(detected.Language)}}
+		if _, err := runner.ApplyModifiers(ctx, rt, oa, models.NilUserID, mods); err != nil {
+			return fmt.Errorf("error setting detected language on contact: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checks the message against the org's moderation policy. If it's flagged, the message is labeled, a ticket opened (if
+// contact doesn't already have one) and the safeguarding flow returned, according to what the policy configures.
+func (t *MsgReceivedTask) moderate(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, fc *flows.Contact, msgIn *flows.MsgIn) (*models.Flow, error) {
+	policy, reason, err := moderation.Moderate(ctx, rt, oa, msgIn.Text())
+	if err != nil {
+		// moderation failing shouldn't stop the message being handled
+		slog.Error("error moderating incoming message", "org_id", oa.OrgID(), "msg_id", t.MsgID, "error", err)
+		return nil, nil
+	}
+	if reason == "" {
+		return nil, nil
+	}
+
+	slog.Info("incoming message flagged by moderation", "org_id", oa.OrgID(), "contact", fc.UUID(), "msg_id", t.MsgID, "reason", reason)
+
+	if label := oa.LabelByUUID(policy.LabelUUID); label != nil {
+		evts := []flows.Event{events.NewInputLabelsAdded(flows.InputUUID(msgIn.UUID()), []*flows.Label{flows.NewLabel(label)})}
+
+		if err := runner.ApplyMsgEvents(ctx, rt, oa, fc, &models.MsgInRef{ID: t.MsgID, ExtID: t.MsgExternalID}, evts); err != nil {
+			return nil, fmt.Errorf("error labeling moderated message: %w", err)
+		}
+	}
+
+	if topic := oa.TopicByUUID(policy.TopicUUID); topic != nil {
+		mod := modifiers.NewTicket(oa.SessionAssets().Topics().Get(topic.UUID()), nil, fmt.Sprintf("Message %s", reason))
+
+		if _, err := runner.ApplyModifiers(ctx, rt, oa, models.NilUserID, map[*flows.Contact][]flows.Modifier{fc: {mod}}); err != nil {
+			return nil, fmt.Errorf("error opening ticket for moderated message: %w", err)
+		}
+	}
+
+	if policy.FlowUUID != "" {
+		f, err := oa.FlowByUUID(policy.FlowUUID)
+		if err != nil && err != models.ErrNotFound {
+			return nil, fmt.Errorf("error loading safeguarding flow: %w", err)
+		}
+		if f != nil && f.(*models.Flow).FlowType() == models.FlowTypeMessaging {
+			return f.(*models.Flow), nil
+		}
+	}
+
+	return nil, nil
+}
+
 // handles a message as an inbox message, i.e. no flow
 func (t *MsgReceivedTask) handleAsInbox(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, contact *flows.Contact, msg *flows.MsgIn, attachments []utils.Attachment, logUUIDs []clogs.UUID, ticket *models.Ticket) error {
 	// usually last_seen_on is updated by handling the msg_received event in the engine sprint, but since this is an inbox
@@ -270,3 +482,14 @@ func (t *MsgReceivedTask) markMsgHandled(ctx context.Context, db models.DBorTx,
 
 	return nil
 }
+
+// queues lookups of the tel URNs of a contact which courier has just created
+func queueURNLookups(rt *runtime.Runtime, oa *models.OrgAssets, mc *models.Contact) error {
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	if err := lookups.QueueURNLookups(rc, oa, []models.ContactID{mc.ID()}); err != nil {
+		return fmt.Errorf("error queuing URN lookups for new contact: %w", err)
+	}
+	return nil
+}
@@ -57,14 +57,24 @@ func TestMsgReceivedTask(t *testing.T) {
 	// insert a dummy message into the database that will get the updates from handling each message event which pretends to be it
 	dbMsg := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "", models.MsgStatusPending)
 
+	// and another which will be a redelivery of that message
+	dupeMsg := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.FacebookChannel, testdata.Bob, "", models.MsgStatusPending)
+
 	tcs := []struct {
-		preHook       func()
-		org           *testdata.Org
-		channel       *testdata.Channel
-		contact       *testdata.Contact
-		text          string
-		expectedReply string
-		expectedFlow  *testdata.Flow
+		preHook            func()
+		org                *testdata.Org
+		channel            *testdata.Channel
+		contact            *testdata.Contact
+		msg                *testdata.MsgIn
+		extID              string
+		text               string
+		attachments        []string
+		expectedReply      string
+		expectedFlow       *testdata.Flow
+		expectedVisibility models.MsgVisibility
+		expectedLabel      *testdata.Label
+		expectedAttachment string
+		expectedLanguage   string
 	}{
 		// 0:
 		{
@@ -278,9 +288,201 @@ func TestMsgReceivedTask(t *testing.T) {
 			contact: deleted,
 			text:    "start",
 		},
+
+		// 21: channel deduplicates by external id, first delivery is handled as normal
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE channels_channel SET config = config || '{"dedup_window": 60}' WHERE id = $1`, testdata.FacebookChannel.ID)
+				models.FlushCache()
+			},
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			extID:   "EX123",
+			text:    "hello",
+		},
+
+		// 22: retrying the task for that same message isn't treated as a duplicate
+		{
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			extID:   "EX123",
+			text:    "hello",
+		},
+
+		// 23: but a redelivery of it as a different message is archived
+		{
+			org:                testdata.Org1,
+			channel:            testdata.FacebookChannel,
+			contact:            testdata.Bob,
+			msg:                dupeMsg,
+			extID:              "EX123",
+			text:               "hello",
+			expectedVisibility: models.VisibilityArchived,
+		},
+
+		// 24: channel limits contacts to one message a minute, first message is handled as normal
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE channels_channel SET config = config || '{"contact_msg_rate": 1, "contact_msg_block_after": 2}' WHERE id = $1`, testdata.FacebookChannel.ID)
+				models.FlushCache()
+			},
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			text:    "hello",
+		},
+
+		// 25: next message is rate limited so can't trigger a flow and is handled as inbox
+		{
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			text:    "start",
+		},
+
+		// 26: exceeding the limit again blocks the contact and archives the message
+		{
+			org:                testdata.Org1,
+			channel:            testdata.FacebookChannel,
+			contact:            testdata.Bob,
+			text:               "start",
+			expectedVisibility: models.VisibilityArchived,
+		},
+
+		// 27: org has a moderation policy which still applies to rate limited messages, so message is labeled and the
+		// safeguarding flow started
+		{
+			preHook: func() {
+				assertdb.Query(t, rt.DB, `SELECT status FROM contacts_contact WHERE id = $1`, testdata.Bob.ID).Returns("B")
+
+				rt.DB.MustExec(`UPDATE contacts_contact SET status = 'A' WHERE id = $1`, testdata.Bob.ID)
+				rt.DB.MustExec(`UPDATE channels_channel SET config = config - 'contact_msg_block_after' WHERE id = $1`, testdata.FacebookChannel.ID)
+				rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"moderation": {"words": ["kill"], "llm_uuid": "e5d8900a-ef54-4d2a-8214-ff7d3e903502", "label_uuid": "ebc4dedc-91c4-4ed4-9dd6-daa05ea82698", "flow_uuid": "a7c11d68-f008-496f-b56d-2d5cf4cf16a5"}}' WHERE id = $1`, testdata.Org1.ID)
+				models.FlushCache()
+			},
+			org:           testdata.Org1,
+			channel:       testdata.FacebookChannel,
+			contact:       testdata.Bob,
+			text:          "i will kill you",
+			expectedFlow:  testdata.SingleMessage,
+			expectedLabel: testdata.ReportingLabel,
+		},
+
+		// 28: once not rate limited, message is flagged by word and labeled
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE channels_channel SET config = config - 'contact_msg_rate' WHERE id = $1`, testdata.FacebookChannel.ID)
+				rt.DB.MustExec(`UPDATE orgs_org SET config = config #- '{moderation,flow_uuid}' WHERE id = $1`, testdata.Org1.ID)
+				models.FlushCache()
+			},
+			org:           testdata.Org1,
+			channel:       testdata.FacebookChannel,
+			contact:       testdata.Bob,
+			text:          "i will kill you",
+			expectedLabel: testdata.ReportingLabel,
+		},
+
+		// 29: message flagged by LLM
+		{
+			org:           testdata.Org1,
+			channel:       testdata.FacebookChannel,
+			contact:       testdata.Bob,
+			text:          `\return FLAGGED`,
+			expectedLabel: testdata.ReportingLabel,
+		},
+
+		// 30: LLM erroring doesn't stop message being handled
+		{
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			text:    `\error boom`,
+		},
+
+		// 31: attachments of types the org doesn't allow are marked as unavailable
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"attachment_types": ["image"]}' WHERE id = $1`, testdata.Org1.ID)
+				models.FlushCache()
+			},
+			org:                testdata.Org1,
+			channel:            testdata.FacebookChannel,
+			contact:            testdata.Bob,
+			attachments:        []string{"audio/ogg:https://example.com/voice.ogg"},
+			expectedAttachment: "unavailable:https://example.com/voice.ogg",
+		},
+
+		// 32: text transcribed from audio attachments is treated as part of the message, e.g. by moderation
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE orgs_org SET config = (config - 'attachment_types') || '{"attachment_transcription_llm": "e5d8900a-ef54-4d2a-8214-ff7d3e903502"}' WHERE id = $1`, testdata.Org1.ID)
+				models.FlushCache()
+			},
+			org:                testdata.Org1,
+			channel:            testdata.FacebookChannel,
+			contact:            testdata.Bob,
+			attachments:        []string{"audio/ogg:https://example.com/kill.ogg"},
+			expectedLabel:      testdata.ReportingLabel,
+			expectedAttachment: "audio/ogg:https://example.com/kill.ogg",
+		},
+
+		// 33: language of message is detected and set on contact since they don't have one
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE orgs_org SET config = (config - 'attachment_transcription_llm' - 'moderation') || '{"language_detection": {"detector": "llm", "llm_uuid": "e5d8900a-ef54-4d2a-8214-ff7d3e903502"}}' WHERE id = $1`, testdata.Org1.ID)
+				rt.DB.MustExec(`UPDATE contacts_contact SET language = NULL WHERE id = $1`, testdata.Bob.ID)
+				models.FlushCache()
+			},
+			org:              testdata.Org1,
+			channel:          testdata.FacebookChannel,
+			contact:          testdata.Bob,
+			text:             `\return spa 0.95`,
+			expectedLanguage: "spa",
+		},
+
+		// 34: detector erroring doesn't stop message being handled
+		{
+			preHook: func() {
+				assertdb.Query(t, rt.DB, `SELECT language FROM contacts_contact WHERE id = $1`, testdata.Bob.ID).Returns("spa")
+			},
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			text:    `\error boom`,
+		},
+
+		// 35: language detected by the ngram detector is recorded but not set on the contact
+		{
+			preHook: func() {
+				rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"language_detection": {"detector": "ngram"}}' WHERE id = $1`, testdata.Org1.ID)
+				rt.DB.MustExec(`UPDATE contacts_contact SET language = NULL WHERE id = $1`, testdata.Bob.ID)
+				models.FlushCache()
+			},
+			org:              testdata.Org1,
+			channel:          testdata.FacebookChannel,
+			contact:          testdata.Bob,
+			text:             "Hola, ¿cómo estás? Quiero información sobre la clínica",
+			expectedLanguage: "spa",
+		},
+
+		// 36: rate limited messages don't have their language detected
+		{
+			preHook: func() {
+				assertdb.Query(t, rt.DB, `SELECT language FROM contacts_contact WHERE id = $1`, testdata.Bob.ID).Returns(nil)
+
+				rt.DB.MustExec(`UPDATE channels_channel SET config = config || '{"contact_msg_rate": 1}' WHERE id = $1`, testdata.FacebookChannel.ID)
+				models.FlushCache()
+			},
+			org:     testdata.Org1,
+			channel: testdata.FacebookChannel,
+			contact: testdata.Bob,
+			text:    `\return fra 0.95`,
+		},
 	}
 
-	makeMsgTask := func(channel *testdata.Channel, contact *testdata.Contact, text string) handler.Task {
+	makeMsgTask := func(channel *testdata.Channel, contact *testdata.Contact, text string) *ctasks.MsgReceivedTask {
 		return &ctasks.MsgReceivedTask{
 			ChannelID: channel.ID,
 			MsgID:     dbMsg.ID,
@@ -296,15 +498,27 @@ func TestMsgReceivedTask(t *testing.T) {
 	for i, tc := range tcs {
 		models.FlushCache()
 
-		// reset our dummy db message into an unhandled state
-		rt.DB.MustExec(`UPDATE msgs_msg SET status = 'P', flow_id = NULL WHERE id = $1`, dbMsg.ID)
+		// reset our dummy db messages into an unhandled state
+		rt.DB.MustExec(`UPDATE msgs_msg SET status = 'P', visibility = 'V', flow_id = NULL, metadata = NULL WHERE id IN ($1, $2)`, dbMsg.ID, dupeMsg.ID)
+		rt.DB.MustExec(`DELETE FROM msgs_msg_labels WHERE msg_id IN ($1, $2)`, dbMsg.ID, dupeMsg.ID)
 
 		// run our setup hook if we have one
 		if tc.preHook != nil {
 			tc.preHook()
 		}
 
-		err := handler.QueueTask(rc, tc.org.ID, tc.contact.ID, makeMsgTask(tc.channel, tc.contact, tc.text))
+		msg := dbMsg
+		if tc.msg != nil {
+			msg = tc.msg
+		}
+
+		msgTask := makeMsgTask(tc.channel, tc.contact, tc.text)
+		msgTask.MsgID = msg.ID
+		msgTask.MsgUUID = msg.FlowMsg.UUID()
+		msgTask.MsgExternalID = tc.extID
+		msgTask.Attachments = tc.attachments
+
+		err := handler.QueueTask(rc, tc.org.ID, tc.contact.ID, msgTask)
 		assert.NoError(t, err, "%d: error adding task", i)
 
 		task, err := tasks.HandlerQueue.Pop(rc)
@@ -317,11 +531,34 @@ func TestMsgReceivedTask(t *testing.T) {
 		if tc.expectedFlow != nil {
 			expectedFlowID = int64(tc.expectedFlow.ID)
 		}
+		expectedVisibility := tc.expectedVisibility
+		if expectedVisibility == "" {
+			expectedVisibility = models.VisibilityVisible
+		}
 
 		// check that message is marked as handled
 		if tc.contact != deleted {
-			assertdb.Query(t, rt.DB, `SELECT status, msg_type, flow_id FROM msgs_msg WHERE id = $1`, dbMsg.ID).
-				Columns(map[string]any{"status": "H", "msg_type": "T", "flow_id": expectedFlowID}, "%d: msg state mismatch", i)
+			assertdb.Query(t, rt.DB, `SELECT status, msg_type, flow_id, visibility FROM msgs_msg WHERE id = $1`, msg.ID).
+				Columns(map[string]any{"status": "H", "msg_type": "T", "flow_id": expectedFlowID, "visibility": string(expectedVisibility)}, "%d: msg state mismatch", i)
+		}
+
+		// check attachments after processing
+		if tc.expectedAttachment != "" {
+			assertdb.Query(t, rt.DB, `SELECT attachments[1] FROM msgs_msg WHERE id = $1`, msg.ID).Returns(tc.expectedAttachment, "%d: attachment mismatch", i)
+		}
+
+		// check any detected language recorded in the message metadata
+		if tc.expectedLanguage != "" {
+			assertdb.Query(t, rt.DB, `SELECT metadata::jsonb->'detected_language'->>'language' FROM msgs_msg WHERE id = $1`, msg.ID).Returns(tc.expectedLanguage, "%d: detected language mismatch", i)
+		} else {
+			assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE id = $1 AND metadata IS NOT NULL`, msg.ID).Returns(0, "%d: detected language mismatch", i)
+		}
+
+		// check any label applied by moderation
+		if tc.expectedLabel != nil {
+			assertdb.Query(t, rt.DB, `SELECT label_id FROM msgs_msg_labels WHERE msg_id = $1`, msg.ID).Returns(int64(tc.expectedLabel.ID), "%d: label mismatch", i)
+		} else {
+			assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg_labels WHERE msg_id = $1`, msg.ID).Returns(0, "%d: label mismatch", i)
 		}
 
 		// if we are meant to have a reply, check it
@@ -404,3 +641,40 @@ func TestMsgReceivedTask(t *testing.T) {
 
 	assertdb.Query(t, rt.DB, `SELECT count(*) FROM msgs_msg WHERE contact_id = $1 AND direction = 'O' AND created_on > $2`, testdata.Org2Contact.ID, previous).Returns(0)
 }
+
+func TestMsgReceivedNewContact(t *testing.T) {
+	_, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	// a contact which courier has just created for an incoming message
+	contact := testdata.InsertContact(rt, testdata.Org1, "b7c28d0f-e4e8-4a2a-9a8b-5c9c2b4b0e3a", "", "", models.ContactStatusActive)
+	contact.URN = "tel:+16055741234"
+	contact.URNID = testdata.InsertContactURN(rt, testdata.Org1, contact, contact.URN, 1000, nil)
+
+	handleMsg := func() {
+		msg := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, contact, "hi", models.MsgStatusPending)
+
+		testsuite.QueueContactTask(t, rt, testdata.Org1, contact, &ctasks.MsgReceivedTask{
+			ChannelID:  testdata.TwilioChannel.ID,
+			MsgID:      msg.ID,
+			MsgUUID:    msg.FlowMsg.UUID(),
+			URN:        contact.URN,
+			URNID:      contact.URNID,
+			Text:       "hi",
+			NewContact: true,
+		})
+		testsuite.FlushTasks(t, rt, "handler")
+	}
+
+	// without number lookups configured, nothing is queued
+	handleMsg()
+	testsuite.AssertBatchTasks(t, testdata.Org1.ID, map[string]int{})
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"number_lookup": "dtone", "dtone_key": "key123", "dtone_secret": "sesame"}' WHERE id = $1`, testdata.Org1.ID)
+	models.FlushCache()
+
+	// with them configured, the new contact's number is looked up
+	handleMsg()
+	testsuite.AssertBatchTasks(t, testdata.Org1.ID, map[string]int{"lookup_urns": 1})
+}
@@ -36,8 +36,18 @@ func (t *TicketClosedTask) UseReadOnly() bool {
 }
 
 func (t *TicketClosedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, mc *models.Contact) error {
+	buildTrigger := func(tb *triggers.Builder, ticket *flows.Ticket) flows.Trigger {
+		return tb.Ticket(ticket, triggers.TicketEventTypeClosed).Build()
+	}
+
+	return triggerTicketFlow(ctx, rt, oa, mc, t.TicketID, models.FindMatchingTicketClosedTrigger, buildTrigger)
+}
+
+// starts the flow of the matching ticket trigger (if any) for the given ticket, using the given func to build the
+// engine trigger
+func triggerTicketFlow(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, mc *models.Contact, ticketID models.TicketID, findTrigger func(*models.OrgAssets, *flows.Contact) *models.Trigger, buildTrigger func(*triggers.Builder, *flows.Ticket) flows.Trigger) error {
 	// load our ticket
-	tickets, err := models.LoadTickets(ctx, rt.DB, []models.TicketID{t.TicketID})
+	tickets, err := models.LoadTickets(ctx, rt.DB, []models.TicketID{ticketID})
 	if err != nil {
 		return fmt.Errorf("error loading ticket: %w", err)
 	}
@@ -53,11 +63,11 @@ func (t *TicketClosedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa
 	}
 
 	// do we have associated trigger?
-	trigger := models.FindMatchingTicketClosedTrigger(oa, fc)
+	trigger := findTrigger(oa, fc)
 
 	// no trigger, noop, move on
 	if trigger == nil {
-		slog.Info("ignoring ticket closed event, no trigger found", "ticket_id", t.TicketID)
+		slog.Info("ignoring ticket event, no trigger found", "ticket_id", ticketID)
 		return nil
 	}
 
@@ -83,9 +93,7 @@ func (t *TicketClosedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa
 	ticket := tickets[0].FlowTicket(oa)
 
 	// build our flow trigger
-	flowTrigger := triggers.NewBuilder(oa.Env(), flow.Reference(), fc).
-		Ticket(ticket, triggers.TicketEventTypeClosed).
-		Build()
+	flowTrigger := buildTrigger(triggers.NewBuilder(oa.Env(), flow.Reference(), fc), ticket)
 
 	_, err = runner.StartFlow(ctx, rt, oa, flow, []*models.Contact{mc}, []flows.Trigger{flowTrigger}, flow.FlowType().Interrupts(), models.NilStartID, nil, nil)
 	if err != nil {
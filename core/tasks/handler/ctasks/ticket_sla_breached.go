@@ -0,0 +1,49 @@
+package ctasks
+
+import (
+	"context"
+
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks/handler"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+const TypeTicketSLABreached = "ticket_sla_breached"
+
+func init() {
+	handler.RegisterContactTask(TypeTicketSLABreached, func() handler.Task { return &TicketSLABreachedTask{} })
+}
+
+type TicketSLABreachedTask struct {
+	TicketID models.TicketID      `json:"ticket_id"`
+	SLAType  models.TicketSLAType `json:"sla_type"`
+}
+
+func NewTicketSLABreached(ticketID models.TicketID, slaType models.TicketSLAType) *TicketSLABreachedTask {
+	return &TicketSLABreachedTask{TicketID: ticketID, SLAType: slaType}
+}
+
+func (t *TicketSLABreachedTask) Type() string {
+	return TypeTicketSLABreached
+}
+
+func (t *TicketSLABreachedTask) UseReadOnly() bool {
+	return false
+}
+
+func (t *TicketSLABreachedTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, mc *models.Contact) error {
+	// the engine has no ticket trigger event for SLA breaches, so flows are started manually with the ticket and the
+	// breached SLA as params
+	buildTrigger := func(tb *triggers.Builder, ticket *flows.Ticket) flows.Trigger {
+		params := types.NewXObject(map[string]types.XValue{
+			"ticket_uuid": types.NewXText(string(ticket.UUID())),
+			"sla_type":    types.NewXText(string(t.SLAType)),
+		})
+		return tb.Manual().WithParams(params).Build()
+	}
+
+	return triggerTicketFlow(ctx, rt, oa, mc, t.TicketID, models.FindMatchingTicketSLABreachedTrigger, buildTrigger)
+}
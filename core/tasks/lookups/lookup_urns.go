@@ -0,0 +1,164 @@
+package lookups
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/modifiers"
+	"github.com/nyaruka/mailroom/core/goflow"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/runtime"
+)
+
+// TypeLookupURNs is the type of the task to look up the tel URNs of contacts
+const TypeLookupURNs = "lookup_urns"
+
+// number of times we'll try to look up a URN before giving up until it next changes
+const maxLookupAttempts = 3
+
+func init() {
+	tasks.RegisterType(TypeLookupURNs, func() tasks.Task { return &LookupURNsTask{} })
+}
+
+// LookupURNsTask is our task to look up the carrier, country and line type of the tel URNs of contacts using the
+// org's number lookup service
+type LookupURNsTask struct {
+	ContactIDs []models.ContactID `json:"contact_ids"`
+	ErrorCount int                `json:"error_count,omitempty"`
+}
+
+func (t *LookupURNsTask) Type() string {
+	return TypeLookupURNs
+}
+
+// Timeout is the maximum amount of time the task can run for
+func (t *LookupURNsTask) Timeout() time.Duration {
+	return time.Minute * 10
+}
+
+func (t *LookupURNsTask) WithAssets() models.Refresh {
+	return models.RefreshNone
+}
+
+// Perform looks up each tel URN which hasn't been looked up yet and records the result on the URN
+func (t *LookupURNsTask) Perform(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets) error {
+	httpClient, httpRetries, _ := goflow.HTTP(rt.Config)
+
+	svc, err := oa.Org().NumberLookupService(httpClient, httpRetries)
+	if err != nil {
+		return fmt.Errorf("error creating number lookup service: %w", err)
+	}
+
+	// lookups may have been disabled since this task was queued
+	if svc == nil {
+		return nil
+	}
+
+	urnz, err := models.LoadURNsForLookup(ctx, rt.DB, oa.OrgID(), t.ContactIDs)
+	if err != nil {
+		return err
+	}
+
+	lineTypes := make(map[models.ContactID]models.LineType, len(t.ContactIDs))
+	failed := make([]models.ContactID, 0)
+
+	for _, u := range urnz {
+		lookup, err := svc.LookupNumber(ctx, u.Path)
+		if err != nil {
+			slog.Error("error looking up number", "org_id", oa.OrgID(), "urn_id", u.ID, "error", err, "error_count", t.ErrorCount)
+
+			// URNs are ordered by contact so only need to check the last failed contact
+			if len(failed) == 0 || failed[len(failed)-1] != u.ContactID {
+				failed = append(failed, u.ContactID)
+			}
+			continue
+		}
+
+		if err := models.UpdateURNLookup(ctx, rt.DB, u.ID, lookup); err != nil {
+			return err
+		}
+
+		// URNs are ordered by priority so the first lookup for a contact is for their highest priority tel URN
+		if _, seen := lineTypes[u.ContactID]; !seen {
+			lineTypes[u.ContactID] = lookup.LineType
+		}
+	}
+
+	if err := t.setLineTypes(ctx, rt, oa, lineTypes); err != nil {
+		return err
+	}
+
+	// requeue contacts with failed lookups, and if we've tried too many times, leave them to be looked up again the
+	// next time their URNs change
+	if len(failed) > 0 {
+		if t.ErrorCount+1 < maxLookupAttempts {
+			rc := rt.RP.Get()
+			defer rc.Close()
+
+			if err := tasks.Queue(rc, tasks.BatchQueue, oa.OrgID(), &LookupURNsTask{ContactIDs: failed, ErrorCount: t.ErrorCount + 1}, false); err != nil {
+				return fmt.Errorf("error requeuing failed URN lookups: %w", err)
+			}
+		} else {
+			slog.Error("error looking up numbers, permanent failure", "org_id", oa.OrgID(), "contact_ids", failed)
+		}
+	}
+
+	return nil
+}
+
+// sets the line type field of the given contacts, if the org has one, so that line types can be used in queries
+func (t *LookupURNsTask) setLineTypes(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, lineTypes map[models.ContactID]models.LineType) error {
+	field := oa.SessionAssets().Fields().Get(models.LineTypeFieldKey)
+	if field == nil || len(lineTypes) == 0 {
+		return nil
+	}
+
+	// contacts we can't lock are skipped rather than holding up the lookups of other contacts
+	locks, _, err := models.LockContacts(ctx, rt, oa.OrgID(), slices.Collect(maps.Keys(lineTypes)), time.Second*10)
+	if err != nil {
+		return err
+	}
+
+	defer models.UnlockContacts(rt, oa.OrgID(), locks)
+
+	contacts, err := models.LoadContacts(ctx, rt.ReadonlyDB, oa, slices.Collect(maps.Keys(locks)))
+	if err != nil {
+		return fmt.Errorf("error loading contacts: %w", err)
+	}
+
+	modifiersByContact := make(map[*flows.Contact][]flows.Modifier, len(contacts))
+	for _, c := range contacts {
+		fc, err := c.FlowContact(oa)
+		if err != nil {
+			return fmt.Errorf("error creating flow contact: %w", err)
+		}
+
+		modifiersByContact[fc] = []flows.Modifier{modifiers.NewField(field, string(lineTypes[c.ID()]))}
+	}
+
+	if _, err := runner.ApplyModifiers(ctx, rt, oa, models.NilUserID, modifiersByContact); err != nil {
+		return fmt.Errorf("error setting line type fields: %w", err)
+	}
+
+	return nil
+}
+
+// QueueURNLookups queues a task to look up the tel URNs of the given contacts, if the org has number lookups enabled
+func QueueURNLookups(rc redis.Conn, oa *models.OrgAssets, contactIDs []models.ContactID) error {
+	if len(contactIDs) == 0 || !oa.Org().HasNumberLookup() {
+		return nil
+	}
+
+	if err := tasks.Queue(rc, tasks.BatchQueue, oa.OrgID(), &LookupURNsTask{ContactIDs: contactIDs}, false); err != nil {
+		return fmt.Errorf("error queuing URN lookup task: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package lookups_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
+	_ "github.com/nyaruka/mailroom/services/lookup/dtone"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupURNs(t *testing.T) {
+	_, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	mocks := httpx.NewMockRequestor(map[string][]*httpx.MockResponse{
+		"https://dvs-api.dtone.com/v1/lookup/mobile-number": {
+			httpx.NewMockResponse(200, nil, []byte(`[{"id": 1596, "name": "Verizon", "identified": true}]`)),
+			httpx.MockConnectionError,
+			httpx.NewMockResponse(200, nil, []byte(`[{"id": 1597, "name": "AT&T", "identified": true}]`)),
+			httpx.MockConnectionError,
+			httpx.MockConnectionError,
+			httpx.MockConnectionError,
+		},
+	})
+
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+	httpx.SetRequestor(mocks)
+
+	// without lookups configured, nothing to do
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &lookups.LookupURNsTask{ContactIDs: []models.ContactID{testdata.Cathy.ID, testdata.Bob.ID}})
+
+	assert.Equal(t, map[string]int{"lookup_urns": 1}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE metadata IS NOT NULL`).Returns(0)
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"number_lookup": "dtone", "dtone_key": "key123", "dtone_secret": "sesame"}' WHERE id = $1`, testdata.Org1.ID)
+	lineType := testdata.InsertField(rt, testdata.Org1, "c6aa3e8b-4b3a-4b5e-9e3f-3b6a8f1c2d4e", "line_type", "Line Type")
+	models.FlushCache()
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &lookups.LookupURNsTask{ContactIDs: []models.ContactID{testdata.Cathy.ID, testdata.Bob.ID}})
+
+	// Bob's lookup failed the first time so was retried in a second task
+	assert.Equal(t, map[string]int{"lookup_urns": 2}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+
+	assertdb.Query(t, rt.DB, `SELECT metadata->>'carrier' FROM contacts_contacturn WHERE contact_id = $1`, testdata.Cathy.ID).Returns("Verizon")
+	assertdb.Query(t, rt.DB, `SELECT metadata->>'line_type' FROM contacts_contacturn WHERE contact_id = $1`, testdata.Cathy.ID).Returns("mobile")
+	assertdb.Query(t, rt.DB, `SELECT metadata->>'carrier' FROM contacts_contacturn WHERE contact_id = $1`, testdata.Bob.ID).Returns("AT&T")
+
+	// and line types were written to the line_type field so they can be queried
+	assertdb.Query(t, rt.DB, `SELECT fields->$2->>'text' FROM contacts_contact WHERE id = $1`, testdata.Cathy.ID, lineType.UUID).Returns("mobile")
+	assertdb.Query(t, rt.DB, `SELECT fields->$2->>'text' FROM contacts_contact WHERE id = $1`, testdata.Bob.ID, lineType.UUID).Returns("mobile")
+
+	// if a lookup keeps failing, we give up after 3 attempts and leave it until the URN next changes
+	rt.DB.MustExec(`UPDATE contacts_contacturn SET metadata = NULL WHERE contact_id = $1`, testdata.Bob.ID)
+
+	testsuite.QueueBatchTask(t, rt, testdata.Org1, &lookups.LookupURNsTask{ContactIDs: []models.ContactID{testdata.Bob.ID}})
+
+	assert.Equal(t, map[string]int{"lookup_urns": 3}, testsuite.FlushTasks(t, rt, "batch", "throttled"))
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contacturn WHERE contact_id = $1 AND metadata IS NULL`, testdata.Bob.ID).Returns(1)
+
+	assert.False(t, mocks.HasUnused())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/search"
 	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
 	"github.com/nyaruka/mailroom/runtime"
 )
 
@@ -59,7 +60,7 @@ func (t *SendBroadcastTask) Perform(ctx context.Context, rt *runtime.Runtime, oa
 }
 
 func createBroadcastBatches(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAssets, bcast *models.Broadcast) error {
-	contactIDs, err := search.ResolveRecipients(ctx, rt, oa, bcast.CreatedByID, nil, &search.Recipients{
+	contactIDs, createdIDs, err := search.ResolveRecipients(ctx, rt, oa, bcast.CreatedByID, nil, &search.Recipients{
 		ContactIDs:      bcast.ContactIDs,
 		GroupIDs:        bcast.GroupIDs,
 		URNs:            bcast.URNs,
@@ -71,6 +72,14 @@ func createBroadcastBatches(ctx context.Context, rt *runtime.Runtime, oa *models
 		return fmt.Errorf("error resolving broadcast recipients: %w", err)
 	}
 
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	// look up the numbers of any contacts we created for URNs
+	if err := lookups.QueueURNLookups(rc, oa, createdIDs); err != nil {
+		return err
+	}
+
 	// if a node is specified, add all the contacts at that node
 	if bcast.NodeUUID != "" {
 		nodeContactIDs, err := models.GetContactIDsAtNode(ctx, rt, oa.OrgID(), bcast.NodeUUID)
@@ -105,9 +114,6 @@ func createBroadcastBatches(ctx context.Context, rt *runtime.Runtime, oa *models
 		q = tasks.HandlerQueue
 	}
 
-	rc := rt.RP.Get()
-	defer rc.Close()
-
 	// create tasks for batches of contacts
 	idBatches := slices.Collect(slices.Chunk(contactIDs, startBatchSize))
 	for i, idBatch := range idBatches {
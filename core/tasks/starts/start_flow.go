@@ -12,6 +12,7 @@ import (
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/search"
 	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
 	"github.com/nyaruka/mailroom/runtime"
 )
 
@@ -64,7 +65,7 @@ func createFlowStartBatches(ctx context.Context, rt *runtime.Runtime, oa *models
 		return fmt.Errorf("error loading flow: %w", err)
 	}
 
-	var contactIDs []models.ContactID
+	var contactIDs, createdIDs []models.ContactID
 
 	if start.CreateContact {
 		// if we are meant to create a new contact, do so
@@ -82,7 +83,7 @@ func createFlowStartBatches(ctx context.Context, rt *runtime.Runtime, oa *models
 			limit = 1
 		}
 
-		contactIDs, err = search.ResolveRecipients(ctx, rt, oa, start.CreatedByID, flow, &search.Recipients{
+		contactIDs, createdIDs, err = search.ResolveRecipients(ctx, rt, oa, start.CreatedByID, flow, &search.Recipients{
 			ContactIDs:      start.ContactIDs,
 			GroupIDs:        start.GroupIDs,
 			URNs:            start.URNs,
@@ -95,6 +96,14 @@ func createFlowStartBatches(ctx context.Context, rt *runtime.Runtime, oa *models
 		}
 	}
 
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	// look up the numbers of any contacts we created for URNs
+	if err := lookups.QueueURNLookups(rc, oa, createdIDs); err != nil {
+		return err
+	}
+
 	// mark our start as queued
 	if err := start.SetQueued(ctx, rt.DB, len(contactIDs)); err != nil {
 		return fmt.Errorf("error marking start as queued: %w", err)
@@ -117,9 +126,6 @@ func createFlowStartBatches(ctx context.Context, rt *runtime.Runtime, oa *models
 	// split the contact ids into batches to become batch tasks
 	idBatches := slices.Collect(slices.Chunk(contactIDs, startBatchSize))
 
-	rc := rt.RP.Get()
-	defer rc.Close()
-
 	for i, idBatch := range idBatches {
 		isFirst := (i == 0)
 		isLast := (i == len(idBatches)-1)
@@ -26,6 +26,11 @@ type Stats struct {
 
 	WebhookCallCount    int           // number of webhook calls
 	WebhookCallDuration time.Duration // total time spent handling webhook calls
+
+	DuplicateMsgCount   int // number of incoming messages ignored as duplicates
+	RateLimitedMsgCount int // number of incoming messages from contacts exceeding inbound rate limits
+
+	ContactLockContentionCount int // number of attempts to lock contacts which failed because they were already locked
 }
 
 func newStats() *Stats {
@@ -83,6 +88,9 @@ func (s *Stats) ToMetrics() []types.MetricDatum {
 	metrics = append(metrics,
 		cwatch.Datum("WebhookCallCount", float64(s.WebhookCallCount), types.StandardUnitCount),
 		cwatch.Datum("WebhookCallDuration", float64(avgWebhookDuration)/float64(time.Second), types.StandardUnitSeconds),
+		cwatch.Datum("DuplicateMsgCount", float64(s.DuplicateMsgCount), types.StandardUnitCount),
+		cwatch.Datum("RateLimitedMsgCount", float64(s.RateLimitedMsgCount), types.StandardUnitCount),
+		cwatch.Datum("ContactLockContentionCount", float64(s.ContactLockContentionCount), types.StandardUnitCount),
 	)
 
 	return metrics
@@ -121,6 +129,24 @@ func (c *StatsCollector) RecordWebhookCall(d time.Duration) {
 	c.mutex.Unlock()
 }
 
+func (c *StatsCollector) RecordDuplicateMsg() {
+	c.mutex.Lock()
+	c.stats.DuplicateMsgCount++
+	c.mutex.Unlock()
+}
+
+func (c *StatsCollector) RecordRateLimitedMsg() {
+	c.mutex.Lock()
+	c.stats.RateLimitedMsgCount++
+	c.mutex.Unlock()
+}
+
+func (c *StatsCollector) RecordContactLockContention() {
+	c.mutex.Lock()
+	c.stats.ContactLockContentionCount++
+	c.mutex.Unlock()
+}
+
 func (c *StatsCollector) RecordLLMCall(typ, model string, d time.Duration) {
 	c.mutex.Lock()
 	c.stats.LLMCallCount[LLMTypeAndModel{typ, model}]++
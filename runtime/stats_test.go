@@ -14,6 +14,10 @@ func TestStats(t *testing.T) {
 	sc.RecordLLMCall("openai", "gpt-4", 7*time.Second)
 	sc.RecordLLMCall("openai", "gpt-4", 3*time.Second)
 	sc.RecordLLMCall("anthropic", "claude-3.7", 4*time.Second)
+	sc.RecordDuplicateMsg()
+	sc.RecordRateLimitedMsg()
+	sc.RecordRateLimitedMsg()
+	sc.RecordContactLockContention()
 
 	stats := sc.Extract()
 	assert.Equal(t, 2, stats.CronTaskCount["make_foos"])
@@ -22,7 +26,10 @@ func TestStats(t *testing.T) {
 	assert.Equal(t, 10*time.Second, stats.LLMCallDuration[LLMTypeAndModel{"openai", "gpt-4"}])
 	assert.Equal(t, 1, stats.LLMCallCount[LLMTypeAndModel{"anthropic", "claude-3.7"}])
 	assert.Equal(t, 4*time.Second, stats.LLMCallDuration[LLMTypeAndModel{"anthropic", "claude-3.7"}])
+	assert.Equal(t, 1, stats.DuplicateMsgCount)
+	assert.Equal(t, 2, stats.RateLimitedMsgCount)
+	assert.Equal(t, 1, stats.ContactLockContentionCount)
 
 	datums := stats.ToMetrics()
-	assert.Len(t, datums, 8)
+	assert.Len(t, datums, 11)
 }
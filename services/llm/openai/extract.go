@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/mailroom/core/media"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/responses"
+	"github.com/openai/openai-go/shared"
+)
+
+const (
+	configTranscriptionModel = "transcription_model"
+
+	defaultTranscriptionModel = "whisper-1"
+
+	// OpenAI won't transcribe files larger than this so no point fetching more
+	maxAudioBytes = 25 * 1024 * 1024
+
+	ocrInstructions = "Extract all of the text in the image. Return only the text, or an empty response if there is none."
+)
+
+func init() {
+	media.RegisterTextExtractor(TypeOpenAI, NewExtractor)
+}
+
+// a text extractor implementation for OpenAI which transcribes audio and does OCR of images
+type extractor struct {
+	client             openai.Client
+	httpClient         *http.Client
+	httpAccess         *httpx.AccessConfig
+	model              string
+	transcriptionModel string
+}
+
+func NewExtractor(m *models.LLM, c *http.Client, access *httpx.AccessConfig) (media.TextExtractor, error) {
+	apiKey := m.Config().GetString(configAPIKey, "")
+	if apiKey == "" {
+		return nil, fmt.Errorf("config incomplete for LLM: %s", m.UUID())
+	}
+
+	return &extractor{
+		client:             openai.NewClient(option.WithAPIKey(apiKey), option.WithHTTPClient(c)),
+		httpClient:         c,
+		httpAccess:         access,
+		model:              m.Model(),
+		transcriptionModel: m.Config().GetString(configTranscriptionModel, defaultTranscriptionModel),
+	}, nil
+}
+
+func (e *extractor) ExtractText(ctx context.Context, contentType, url string) (string, error) {
+	if strings.HasPrefix(contentType, "audio") {
+		return e.transcribe(ctx, contentType, url)
+	}
+	return e.ocr(ctx, url)
+}
+
+func (e *extractor) transcribe(ctx context.Context, contentType, url string) (string, error) {
+	req, err := httpx.NewRequest(ctx, http.MethodGet, url, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request for audio: %w", err)
+	}
+
+	trace, err := httpx.DoTrace(e.httpClient, req, nil, e.httpAccess, maxAudioBytes)
+	if err != nil {
+		return "", fmt.Errorf("error fetching audio: %w", err)
+	}
+	if trace.Response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching audio, got status %d", trace.Response.StatusCode)
+	}
+
+	transcription, err := e.client.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
+		File:  openai.File(bytes.NewReader(trace.ResponseBody), audioFilename(contentType), contentType),
+		Model: openai.AudioModel(e.transcriptionModel),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error transcribing audio: %w", err)
+	}
+
+	return transcription.Text, nil
+}
+
+// OpenAI determines the format of audio from the file extension, so we derive that from the content type, e.g.
+// audio/ogg -> audio.ogg, audio/x-wav -> audio.wav
+func audioFilename(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "audio"
+	}
+
+	_, subtype, _ := strings.Cut(mediaType, "/")
+	subtype = strings.TrimPrefix(subtype, "x-")
+	if subtype == "" {
+		return "audio"
+	}
+
+	return "audio." + subtype
+}
+
+func (e *extractor) ocr(ctx context.Context, url string) (string, error) {
+	content := responses.ResponseInputMessageContentListParam{
+		{OfInputImage: &responses.ResponseInputImageParam{Detail: responses.ResponseInputImageDetailAuto, ImageURL: openai.String(url)}},
+	}
+
+	resp, err := e.client.Responses.New(ctx, responses.ResponseNewParams{
+		Model:        shared.ResponsesModel(e.model),
+		Instructions: openai.String(ocrInstructions),
+		Input: responses.ResponseNewParamsInputUnion{
+			OfInputItemList: responses.ResponseInputParam{
+				{OfMessage: &responses.EasyInputMessageParam{
+					Role:    responses.EasyInputMessageRoleUser,
+					Content: responses.EasyInputMessageContentUnionParam{OfInputItemContentList: content},
+				}},
+			},
+		},
+		Temperature: openai.Float(0.000001),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error extracting text from image: %w", err)
+	}
+
+	return resp.OutputText(), nil
+}
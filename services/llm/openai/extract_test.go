@@ -0,0 +1,110 @@
+package openai_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/services/llm/openai"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractor(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	bad := testdata.InsertLLM(rt, testdata.Org1, "c69723d8-fb37-4cf6-9ec4-bc40cb36f2cc", "openai", "gpt-4o", "Bad Config", map[string]any{})
+	good := testdata.InsertLLM(rt, testdata.Org1, "b86966fd-206e-4bdd-a962-06faa3af1182", "openai", "gpt-4o", "Good", map[string]any{"api_key": "sesame"})
+	models.FlushCache()
+
+	oa := testdata.Org1.Load(rt)
+	badLLM := oa.LLMByID(bad.ID)
+	goodLLM := oa.LLMByID(good.ID)
+
+	mocks := httpx.NewMockRequestor(map[string][]*httpx.MockResponse{
+		"http://54.1.2.3/audio1.ogg": {
+			httpx.NewMockResponse(200, map[string]string{"Content-Type": "audio/ogg"}, []byte(`OGG...`)),
+		},
+		"http://54.1.2.3/audio2.wav": {
+			httpx.NewMockResponse(404, nil, []byte(`not found`)),
+		},
+		"https://api.openai.com/v1/audio/transcriptions": {
+			httpx.NewMockResponse(200, map[string]string{"Content-Type": "application/json"}, []byte(`{"text": "Hello world"}`)),
+		},
+		"https://api.openai.com/v1/responses": {
+			httpx.NewMockResponse(200, map[string]string{"Content-Type": "application/json"}, []byte(`{
+				"id": "resp_67ccd2bed1ec8190b14f964abc0542670bb6a6b452d3795b",
+				"object": "response",
+				"created_at": 1741476542,
+				"status": "completed",
+				"output": [
+					{
+						"type": "message",
+						"id": "msg_67ccd2bf17f0819081ff3bb2cf6508e60bb6a6b452d3795b",
+						"status": "completed",
+						"role": "assistant",
+						"content": [{"type": "output_text", "text": "STOP", "annotations": []}]
+					}
+				],
+				"usage": {"input_tokens": 765, "output_tokens": 2, "total_tokens": 767}
+			}`)),
+		},
+	})
+	client := &http.Client{Transport: mocks}
+
+	disallowedIPs, disallowedNets, err := httpx.ParseNetworks("127.0.0.1", "::1", "10.0.0.0/8")
+	require.NoError(t, err)
+	access := httpx.NewAccessConfig(10*time.Second, disallowedIPs, disallowedNets)
+
+	// can't create extractor with bad config
+	ext, err := openai.NewExtractor(badLLM, client, access)
+	assert.EqualError(t, err, "config incomplete for LLM: c69723d8-fb37-4cf6-9ec4-bc40cb36f2cc")
+	assert.Nil(t, ext)
+
+	ext, err = openai.NewExtractor(goodLLM, client, access)
+	assert.NoError(t, err)
+	assert.NotNil(t, ext)
+
+	// audio is fetched and then sent for transcription with a file extension derived from its content type
+	text, err := ext.ExtractText(ctx, "audio/ogg", "http://54.1.2.3/audio1.ogg")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", text)
+
+	reqs := mocks.Requests()
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, "http://54.1.2.3/audio1.ogg", reqs[0].URL.String())
+		assert.Equal(t, "https://api.openai.com/v1/audio/transcriptions", reqs[1].URL.String())
+
+		require.NoError(t, reqs[1].ParseMultipartForm(1024))
+		assert.Equal(t, []string{"whisper-1"}, reqs[1].MultipartForm.Value["model"])
+		if assert.Len(t, reqs[1].MultipartForm.File["file"], 1) {
+			assert.Equal(t, "audio.ogg", reqs[1].MultipartForm.File["file"][0].Filename)
+		}
+	}
+
+	// audio which can't be fetched isn't sent for transcription
+	text, err = ext.ExtractText(ctx, "audio/x-wav", "http://54.1.2.3/audio2.wav")
+	assert.EqualError(t, err, "error fetching audio, got status 404")
+	assert.Equal(t, "", text)
+	assert.Len(t, mocks.Requests(), 3)
+
+	// audio on a disallowed host isn't fetched at all
+	text, err = ext.ExtractText(ctx, "audio/ogg", "http://10.1.2.3/audio3.ogg")
+	assert.EqualError(t, err, "error fetching audio: request not permitted by access config")
+	assert.Equal(t, "", text)
+	assert.Len(t, mocks.Requests(), 3)
+
+	// images are passed by URL for OCR
+	text, err = ext.ExtractText(ctx, "image/jpeg", "http://54.1.2.3/sign.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, "STOP", text)
+	assert.Len(t, mocks.Requests(), 4)
+
+	assert.False(t, mocks.HasUnused())
+}
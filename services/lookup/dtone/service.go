@@ -0,0 +1,77 @@
+package dtone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/services/airtime/dtone"
+)
+
+const (
+	TypeDTOne = "dtone"
+
+	configKey    = "dtone_key"
+	configSecret = "dtone_secret"
+)
+
+func init() {
+	models.RegisterNumberLookupService(TypeDTOne, New)
+}
+
+// a number lookup service which uses the mobile number lookups of the DTOne airtime API. These can only identify
+// mobile numbers, so landlines, VoIP numbers and mobiles DTOne doesn't know the operator of are all reported with an
+// unknown line type. Orgs using this service should select mobile contacts with line_type = mobile rather than try to
+// exclude landlines.
+type service struct {
+	client *dtone.Client
+}
+
+// New creates a new DTOne number lookup service using the org's DTOne airtime credentials
+func New(org *models.Org, httpClient *http.Client, httpRetries *httpx.RetryConfig) (models.NumberLookupService, error) {
+	key := org.ConfigValue(configKey, "")
+	secret := org.ConfigValue(configSecret, "")
+
+	if key == "" || secret == "" {
+		return nil, errors.New("config incomplete for DTOne number lookup")
+	}
+
+	return &service{client: dtone.NewClient(httpClient, httpRetries, key, secret)}, nil
+}
+
+func (s *service) LookupNumber(ctx context.Context, number string) (*models.NumberLookup, error) {
+	if !strings.HasPrefix(number, "+") {
+		number = "+" + number
+	}
+
+	lookup := &models.NumberLookup{LineType: models.LineTypeUnknown}
+
+	// no point asking DTOne about numbers which aren't valid
+	if _, err := urns.ParseNumber(number, i18n.NilCountry, false, false); err != nil {
+		return lookup, nil
+	}
+
+	lookup.Valid = true
+	lookup.Country = i18n.DeriveCountryFromTel(number)
+
+	operators, _, err := s.client.LookupMobileNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("number lookup failed: %w", err)
+	}
+
+	for _, op := range operators {
+		if op.Identified {
+			lookup.Carrier = op.Name
+			lookup.LineType = models.LineTypeMobile
+			break
+		}
+	}
+
+	return lookup, nil
+}
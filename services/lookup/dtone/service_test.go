@@ -0,0 +1,63 @@
+package dtone_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/services/lookup/dtone"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	mocks := httpx.NewMockRequestor(map[string][]*httpx.MockResponse{
+		"https://dvs-api.dtone.com/v1/lookup/mobile-number": {
+			httpx.NewMockResponse(200, nil, []byte(`[{"id": 1596, "name": "Claro Ecuador", "identified": true}]`)),
+			httpx.NewMockResponse(200, nil, []byte(`[]`)), // no matches
+			httpx.NewMockResponse(400, nil, []byte(`{"errors": [{"code": 1000401, "message": "Unauthorized"}]}`)),
+		},
+	})
+	client := &http.Client{Transport: mocks}
+
+	// can't create service without credentials
+	oa := testdata.Org1.Load(rt)
+	svc, err := dtone.New(oa.Org(), client, nil)
+	assert.EqualError(t, err, "config incomplete for DTOne number lookup")
+	assert.Nil(t, svc)
+
+	rt.DB.MustExec(`UPDATE orgs_org SET config = config || '{"number_lookup": "dtone", "dtone_key": "key123", "dtone_secret": "sesame"}' WHERE id = $1`, testdata.Org1.ID)
+
+	oa, err = models.GetOrgAssetsWithRefresh(ctx, rt, testdata.Org1.ID, models.RefreshOrg)
+	require.NoError(t, err)
+
+	svc, err = oa.Org().NumberLookupService(client, nil)
+	require.NoError(t, err)
+	require.NotNil(t, svc)
+
+	lookup, err := svc.LookupNumber(ctx, "593979123456")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.NumberLookup{Valid: true, Country: "EC", Carrier: "Claro Ecuador", LineType: models.LineTypeMobile}, lookup)
+
+	lookup, err = svc.LookupNumber(ctx, "+250788123123")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.NumberLookup{Valid: true, Country: "RW", LineType: models.LineTypeUnknown}, lookup)
+
+	// invalid numbers aren't sent to DTOne
+	lookup, err = svc.LookupNumber(ctx, "+123")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.NumberLookup{Valid: false, LineType: models.LineTypeUnknown}, lookup)
+
+	lookup, err = svc.LookupNumber(ctx, "+250788123124")
+	assert.ErrorContains(t, err, "number lookup failed")
+	assert.Nil(t, lookup)
+
+	assert.False(t, mocks.HasUnused())
+}
@@ -36,3 +36,14 @@ func InsertCampaignFlowEvent(rt *runtime.Runtime, campaign *Campaign, flow *Flow
 	))
 	return &CampaignEvent{id, uuid}
 }
+
+func InsertCampaignFlowEventRelativeToEvent(rt *runtime.Runtime, campaign *Campaign, flow *Flow, relativeTo *CampaignEvent, offset int, unit string) *CampaignEvent {
+	uuid := models.CampaignEventUUID(uuids.NewV4())
+	var id models.CampaignEventID
+	must(rt.DB.Get(&id,
+		`INSERT INTO campaigns_campaignevent(uuid, campaign_id, event_type, status, fire_version, flow_id, relative_to_event_id, "offset", unit, delivery_hour, start_mode, is_active, created_on, modified_on, created_by_id, modified_by_id) 
+		VALUES($1, $2, 'F', 'R', 1, $3, $4, $5, $6, -1, 'I', TRUE, NOW(), NOW(), 1, 1) RETURNING id`,
+		uuid, campaign.ID, flow.ID, relativeTo.ID, offset, unit,
+	))
+	return &CampaignEvent{id, uuid}
+}
@@ -47,6 +47,7 @@ var JoinedField = &Field{5, "d83aae24-4bbf-49d0-ab85-6bfd201eac6d"}
 var WardField = &Field{6, "de6878c1-b174-4947-9a65-8910ebe7d10f"}
 var DistrictField = &Field{7, "3ca3e36b-3d5a-42a4-b292-482282ce9a90"}
 var StateField = &Field{8, "1dddea55-9a3b-449f-9d43-57772614ff50"}
+var GroupJoinedOnField = &Field{11, "b9c3ad1a-0f8e-4bd1-a0b5-7e5a2c0b6d3f"}
 
 var ActiveGroup = &Group{1, "b97f69f7-5edf-45c7-9fda-d37066eae91d"}
 var BlockedGroup = &Group{2, "14f6ea01-456b-4417-b0b8-35e942f549f1"}
@@ -91,6 +91,16 @@ func InsertContactGroup(rt *runtime.Runtime, org *Org, uuid assets.GroupUUID, na
 	return &Group{id, uuid}
 }
 
+// InsertField inserts a text contact field
+func InsertField(rt *runtime.Runtime, org *Org, uuid assets.FieldUUID, key, name string) *Field {
+	var id models.FieldID
+	must(rt.DB.Get(&id,
+		`INSERT INTO contacts_contactfield(org_id, uuid, key, name, value_type, is_system, is_proxy, show_in_table, priority, agent_access, is_active, created_by_id, created_on, modified_by_id, modified_on) 
+		 VALUES($1, $2, $3, $4, 'T', FALSE, FALSE, FALSE, 0, 'V', TRUE, 1, NOW(), 1, NOW()) RETURNING id`, org.ID, uuid, key, name,
+	))
+	return &Field{id, uuid}
+}
+
 // InsertContactURN inserts a contact URN
 func InsertContactURN(rt *runtime.Runtime, org *Org, contact *Contact, urn urns.URN, priority int, authTokens map[string]string) models.URNID {
 	scheme, path, _, display := urn.ToParts()
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/mailroom/core/models"
@@ -60,3 +62,20 @@ func insertTicket(rt *runtime.Runtime, org *Org, contact *Contact, status models
 	))
 	return &Ticket{id, uuid}
 }
+
+type CannedResponse struct {
+	ID   models.CannedResponseID
+	UUID models.CannedResponseUUID
+}
+
+// InsertCannedResponse inserts a canned response (ticket shortcut) with optional translations
+func InsertCannedResponse(rt *runtime.Runtime, org *Org, name, text string, translations map[i18n.Language]string) *CannedResponse {
+	uuid := models.CannedResponseUUID(uuids.NewV4())
+
+	var id models.CannedResponseID
+	must(rt.DB.Get(&id,
+		`INSERT INTO tickets_shortcut(uuid, org_id, name, text, translations, is_system, is_active, created_on, modified_on, created_by_id, modified_by_id)
+		VALUES($1, $2, $3, $4, $5, FALSE, TRUE, NOW(), NOW(), 1, 1) RETURNING id`, uuid, org.ID, name, text, models.JSONB[map[i18n.Language]string]{translations},
+	))
+	return &CannedResponse{id, uuid}
+}
@@ -46,6 +46,10 @@ func InsertTicketClosedTrigger(rt *runtime.Runtime, org *Org, flow *Flow) models
 	return insertTrigger(rt, org, models.TicketClosedTriggerType, flow, nil, "", models.NilScheduleID, nil, nil, nil, "", nil)
 }
 
+func InsertTicketSLABreachedTrigger(rt *runtime.Runtime, org *Org, flow *Flow) models.TriggerID {
+	return insertTrigger(rt, org, models.TicketSLABreachedTriggerType, flow, nil, "", models.NilScheduleID, nil, nil, nil, "", nil)
+}
+
 func insertTrigger(rt *runtime.Runtime, org *Org, triggerType models.TriggerType, flow *Flow, keywords []string, matchType models.MatchType, schedID models.ScheduleID, includeGroups, excludeGroups []*Group, contactIDs []*Contact, referrerID string, channel *Channel) models.TriggerID {
 	channelID := models.NilChannelID
 	if channel != nil {
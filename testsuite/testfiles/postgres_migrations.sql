@@ -0,0 +1,83 @@
+-- RapidPro schema migrations which aren't yet in postgres.dump. These are applied after the dump is restored and this
+-- file should be emptied whenever the dump is regenerated.
+
+-- campaigns: events anchored on the firing of other events
+ALTER TABLE campaigns_campaignevent ALTER COLUMN relative_to_id DROP NOT NULL;
+ALTER TABLE campaigns_campaignevent ADD COLUMN relative_to_event_id integer NULL
+    CONSTRAINT campaigns_campaignevent_relative_to_event_id_fk REFERENCES campaigns_campaignevent(id) DEFERRABLE INITIALLY DEFERRED;
+ALTER TABLE campaigns_campaignevent ADD CONSTRAINT campaigns_campaignevent_relative_to_check
+    CHECK ((relative_to_id IS NOT NULL) != (relative_to_event_id IS NOT NULL));
+CREATE INDEX campaigns_campaignevent_relative_to_event_id ON campaigns_campaignevent(relative_to_event_id);
+
+-- contacts: group_joined_on system field for every org
+INSERT INTO contacts_contactfield(id, is_active, created_on, modified_on, uuid, is_system, key, name, value_type, is_proxy, show_in_table, priority, agent_access, created_by_id, modified_by_id, org_id) VALUES
+    (11, TRUE, NOW(), NOW(), 'b9c3ad1a-0f8e-4bd1-a0b5-7e5a2c0b6d3f', TRUE, 'group_joined_on', 'Group Joined On', 'D', TRUE, FALSE, 0, 'V', 3, 3, 1),
+    (12, TRUE, NOW(), NOW(), '5f2d61b4-3a9c-4f77-9d0e-c8e1b7a4f2a6', TRUE, 'group_joined_on', 'Group Joined On', 'D', TRUE, FALSE, 0, 'V', 3, 3, 2);
+SELECT setval('contacts_contactfield_id_seq', 12);
+
+-- triggers: keywords widened to fit regex patterns
+ALTER TABLE triggers_trigger ALTER COLUMN keywords TYPE varchar(255)[];
+
+-- tickets: per-topic config for assignment and SLA policies, and agent availability
+ALTER TABLE tickets_topic ADD COLUMN config jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE orgs_orgmembership ADD COLUMN is_available boolean NOT NULL DEFAULT TRUE;
+
+-- tickets: open tickets by topic and when they were opened, for finding SLA breaches
+CREATE INDEX tickets_ticket_topic_open ON tickets_ticket(topic_id, opened_on) WHERE status = 'O';
+
+-- tickets: translations of shortcuts (canned responses)
+ALTER TABLE tickets_shortcut ADD COLUMN translations jsonb NULL;
+
+-- contacts: probable duplicate contacts found for review
+CREATE TABLE contacts_contactduplicate (
+    id serial PRIMARY KEY,
+    org_id integer NOT NULL REFERENCES orgs_org(id) DEFERRABLE INITIALLY DEFERRED,
+    contact_a_id integer NOT NULL REFERENCES contacts_contact(id) DEFERRABLE INITIALLY DEFERRED,
+    contact_b_id integer NOT NULL REFERENCES contacts_contact(id) DEFERRABLE INITIALLY DEFERRED,
+    reason varchar(16) NOT NULL,
+    confidence double precision NOT NULL,
+    created_on timestamp with time zone NOT NULL,
+    CONSTRAINT contacts_contactduplicate_unique UNIQUE (contact_a_id, contact_b_id, reason)
+);
+CREATE INDEX contacts_contactduplicate_org_id ON contacts_contactduplicate(org_id);
+CREATE INDEX contacts_contactduplicate_contact_b_id ON contacts_contactduplicate(contact_b_id);
+
+-- contacts: modifiers applied in bulk to the contacts in a group or matching a query
+CREATE TABLE contacts_contactbulkmodify (
+    id serial PRIMARY KEY,
+    org_id integer NOT NULL REFERENCES orgs_org(id) DEFERRABLE INITIALLY DEFERRED,
+    status varchar(1) NOT NULL,
+    group_id integer NULL REFERENCES contacts_contactgroup(id) DEFERRABLE INITIALLY DEFERRED,
+    query text NULL,
+    modifiers jsonb NOT NULL,
+    num_contacts integer NOT NULL,
+    num_modified integer NOT NULL,
+    num_skipped integer NOT NULL,
+    num_errored integer NOT NULL,
+    created_by_id integer NOT NULL REFERENCES users_user(id) DEFERRABLE INITIALLY DEFERRED,
+    created_on timestamp with time zone NOT NULL,
+    finished_on timestamp with time zone NULL
+);
+CREATE INDEX contacts_contactbulkmodify_org_id ON contacts_contactbulkmodify(org_id);
+
+-- contacts: append-only history of changes made to contacts and who made them
+CREATE TABLE contacts_contacthistory (
+    id bigserial PRIMARY KEY,
+    org_id integer NOT NULL REFERENCES orgs_org(id) DEFERRABLE INITIALLY DEFERRED,
+    contact_id integer NOT NULL REFERENCES contacts_contact(id) DEFERRABLE INITIALLY DEFERRED,
+    event_type varchar(32) NOT NULL,
+    event jsonb NOT NULL,
+    created_by_id integer NULL REFERENCES users_user(id) DEFERRABLE INITIALLY DEFERRED,
+    flow_id integer NULL REFERENCES flows_flow(id) DEFERRABLE INITIALLY DEFERRED,
+    created_on timestamp with time zone NOT NULL
+);
+CREATE INDEX contacts_contacthistory_contact ON contacts_contacthistory(org_id, contact_id, id DESC);
+
+-- contacts: result of validating an import without performing it
+ALTER TABLE contacts_contactimport ADD COLUMN validation jsonb NULL;
+
+-- contacts: results of looking up tel URNs with the org's number lookup service
+ALTER TABLE contacts_contacturn ADD COLUMN metadata jsonb NULL;
+
+-- contacts: optional rules that values of a field must satisfy
+ALTER TABLE contacts_contactfield ADD COLUMN validation jsonb NULL;
@@ -171,6 +171,9 @@ func getES() *elasticsearch.TypedClient {
 // then copying the mailroom_test.dump file to your mailroom root directory
 //
 //	% cp mailroom_test.dump ../mailroom
+//
+// RapidPro migrations which aren't yet in the dump are applied from postgres_migrations.sql, which should be emptied
+// when the dump is regenerated.
 func resetDB() {
 	db := getDB()
 	db.MustExec("DROP OWNED BY mailroom_test CASCADE")
@@ -179,17 +182,10 @@ func resetDB() {
 }
 
 func loadTestDump() {
-	dump, err := os.Open(absPath("./testsuite/testfiles/postgres.dump"))
-	must(err)
-	defer dump.Close()
+	execInPostgres("./testsuite/testfiles/postgres.dump", "pg_restore", "-d", "mailroom_test", "-U", "mailroom_test")
 
-	cmd := exec.Command("docker", "exec", "-i", postgresContainerName, "pg_restore", "-d", "mailroom_test", "-U", "mailroom_test")
-	cmd.Stdin = dump
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		panic(fmt.Sprintf("error restoring database: %s: %s", err, string(output)))
-	}
+	// apply the RapidPro migrations which aren't yet in the dump
+	execInPostgres("./testsuite/testfiles/postgres_migrations.sql", "psql", "-d", "mailroom_test", "-U", "mailroom_test", "-q", "-v", "ON_ERROR_STOP=1")
 
 	// force re-connection
 	if _db != nil {
@@ -198,6 +194,21 @@ func loadTestDump() {
 	}
 }
 
+// runs the given command in our postgres container with the given project root relative file as its input
+func execInPostgres(inputPath string, command ...string) {
+	input, err := os.Open(absPath(inputPath))
+	must(err)
+	defer input.Close()
+
+	cmd := exec.Command("docker", append([]string{"exec", "-i", postgresContainerName}, command...)...)
+	cmd.Stdin = input
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		panic(fmt.Sprintf("error restoring database: %s: %s", err, string(output)))
+	}
+}
+
 // Converts a project root relative path to an absolute path usable in any test. This is needed because go tests
 // are run with a working directory set to the current module being tested.
 func absPath(p string) string {
@@ -269,12 +280,15 @@ func resetDynamo(ctx context.Context, rt *runtime.Runtime) {
 
 var sqlResetTestData = `
 UPDATE contacts_contact SET last_seen_on = NULL, current_session_uuid = NULL, current_flow_id = NULL;
+UPDATE tickets_topic SET config = '{}';
+UPDATE orgs_orgmembership SET is_available = TRUE;
 
 DELETE FROM notifications_notification;
 DELETE FROM notifications_incident;
 DELETE FROM request_logs_httplog;
 DELETE FROM tickets_ticketevent;
 DELETE FROM tickets_ticket;
+DELETE FROM tickets_shortcut;
 DELETE FROM triggers_trigger_contacts WHERE trigger_id >= 30000;
 DELETE FROM triggers_trigger_groups WHERE trigger_id >= 30000;
 DELETE FROM triggers_trigger_exclude_groups WHERE trigger_id >= 30000;
@@ -292,6 +306,7 @@ DELETE FROM flows_flowstart_contacts;
 DELETE FROM flows_flowstart_groups;
 DELETE FROM flows_flowstart;
 DELETE FROM flows_flowsession;
+DELETE FROM contacts_contacthistory;
 DELETE FROM flows_flowrevision WHERE flow_id >= 30000;
 DELETE FROM flows_flow WHERE id >= 30000;
 DELETE FROM ai_llm WHERE id >= 30000;
@@ -309,13 +324,18 @@ DELETE FROM schedules_schedule;
 DELETE FROM campaigns_campaignevent WHERE id >= 30000;
 DELETE FROM campaigns_campaign WHERE id >= 30000;
 DELETE FROM contacts_contactfire;
+DELETE FROM contacts_contactduplicate;
+DELETE FROM contacts_contactbulkmodify;
 DELETE FROM contacts_contactimportbatch;
 DELETE FROM contacts_contactimport;
+DELETE FROM orgs_export;
 DELETE FROM contacts_contacturn WHERE id >= 30000;
+UPDATE contacts_contacturn SET metadata = NULL WHERE metadata IS NOT NULL;
 DELETE FROM contacts_contactgroup_contacts WHERE contact_id >= 30000 OR contactgroup_id >= 30000;
 DELETE FROM contacts_contact WHERE id >= 30000;
 DELETE FROM contacts_contactgroupcount WHERE group_id >= 30000;
 DELETE FROM contacts_contactgroup WHERE id >= 30000;
+UPDATE contacts_contactfield SET validation = NULL WHERE validation IS NOT NULL;
 DELETE FROM orgs_itemcount;
 DELETE FROM orgs_dailycount;
 
@@ -323,11 +343,13 @@ ALTER SEQUENCE ai_llm_id_seq RESTART WITH 30000;
 ALTER SEQUENCE flows_flow_id_seq RESTART WITH 30000;
 ALTER SEQUENCE tickets_ticket_id_seq RESTART WITH 1;
 ALTER SEQUENCE channels_channelevent_id_seq RESTART WITH 1;
+ALTER SEQUENCE contacts_contacthistory_id_seq RESTART WITH 1;
 ALTER SEQUENCE msgs_msg_id_seq RESTART WITH 1;
 ALTER SEQUENCE msgs_broadcast_id_seq RESTART WITH 1;
 ALTER SEQUENCE flows_flowrun_id_seq RESTART WITH 1;
 ALTER SEQUENCE flows_flowstart_id_seq RESTART WITH 1;
 ALTER SEQUENCE flows_flowsession_id_seq RESTART WITH 1;
+ALTER SEQUENCE orgs_export_id_seq RESTART WITH 1;
 ALTER SEQUENCE contacts_contact_id_seq RESTART WITH 30000;
 ALTER SEQUENCE contacts_contacturn_id_seq RESTART WITH 30000;
 ALTER SEQUENCE contacts_contactgroup_id_seq RESTART WITH 30000;
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
 	"github.com/nyaruka/mailroom/testsuite"
 	"github.com/nyaruka/mailroom/testsuite/testdata"
 )
@@ -19,3 +20,24 @@ func TestSchedule_event(t *testing.T) {
 
 	testsuite.AssertBatchTasks(t, testdata.Org1.ID, map[string]int{"schedule_campaign_event": 1})
 }
+
+func TestPreviewEvent(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	// add bob, george and alexandria to doctors group which campaign is based on
+	testdata.DoctorsGroup.Add(rt, testdata.Bob, testdata.George, testdata.Alexandria)
+
+	// give bob and george values for joined in the future and alexandria a value in the past
+	rt.DB.MustExec(`UPDATE contacts_contact SET fields = '{"d83aae24-4bbf-49d0-ab85-6bfd201eac6d": {"datetime": "2018-07-07T00:00:00Z"}}' WHERE id = $1`, testdata.Bob.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET fields = '{"d83aae24-4bbf-49d0-ab85-6bfd201eac6d": {"datetime": "2018-07-20T11:31:30Z"}}' WHERE id = $1`, testdata.George.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET fields = '{"d83aae24-4bbf-49d0-ab85-6bfd201eac6d": {"datetime": "2015-01-01T00:00:00Z"}}' WHERE id = $1`, testdata.Alexandria.ID)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/preview_event.json", map[string]string{
+		"campaign_id": fmt.Sprint(testdata.RemindersCampaign.ID),
+	})
+
+	// nothing should have been created
+	assertdb.Query(t, rt.DB, `SELECT count(*) FROM contacts_contactfire`).Returns(0)
+}
@@ -0,0 +1,129 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/campaign/preview_event", web.RequireAuthToken(web.JSONPayload(handlePreviewEvent)))
+}
+
+// Generates a preview of the fires that a campaign event would create, without creating anything. Fires are counted
+// in daily or weekly intervals starting from today in the org's timezone.
+//
+//	{
+//	  "org_id": 1,
+//	  "campaign_id": 123,
+//	  "relative_to": "joined",
+//	  "offset": 2,
+//	  "unit": "D",
+//	  "delivery_hour": 9,
+//	  "interval": "day",
+//	  "intervals": 7
+//	}
+//
+//	{
+//	  "total": 1245,
+//	  "skipped": 3456,
+//	  "intervals": [
+//	    {"start": "2024-06-20", "count": 350},
+//	    {"start": "2024-06-21", "count": 12},
+//	    ...
+//	  ],
+//	  "later": 830
+//	}
+type previewEventRequest struct {
+	OrgID        models.OrgID             `json:"org_id"        validate:"required"`
+	CampaignID   models.CampaignID        `json:"campaign_id"   validate:"required"`
+	RelativeTo   string                   `json:"relative_to"   validate:"required"`
+	Offset       int                      `json:"offset"`
+	Unit         models.CampaignEventUnit `json:"unit"          validate:"required,eq=M|eq=H|eq=D|eq=W"`
+	DeliveryHour int                      `json:"delivery_hour" validate:"min=-1,max=23"`
+	Interval     string                   `json:"interval"      validate:"omitempty,eq=day|eq=week"`
+	Intervals    int                      `json:"intervals"     validate:"omitempty,min=1,max=100"`
+}
+
+type previewEventInterval struct {
+	Start string `json:"start"`
+	Count int    `json:"count"`
+}
+
+type previewEventResponse struct {
+	Total     int                     `json:"total"`
+	Skipped   int                     `json:"skipped"`
+	Intervals []*previewEventInterval `json:"intervals"`
+	Later     int                     `json:"later"`
+}
+
+func handlePreviewEvent(ctx context.Context, rt *runtime.Runtime, r *previewEventRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	campaign := oa.CampaignByID(r.CampaignID)
+	if campaign == nil {
+		return errors.New("no such campaign"), http.StatusBadRequest, nil
+	}
+
+	field := oa.FieldByKey(r.RelativeTo)
+	if field == nil {
+		return fmt.Errorf("no such field with key: %s", r.RelativeTo), http.StatusBadRequest, nil
+	}
+
+	preview, err := models.PreviewCampaignEvent(ctx, rt, oa, campaign, field, r.Offset, r.Unit, r.DeliveryHour)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error previewing campaign event: %w", err)
+	}
+
+	// intervals start at the beginning of today in the org's timezone
+	tz := oa.Env().Timezone()
+	now := dates.Now().In(tz)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+
+	daysPerInterval := 1
+	if r.Interval == "week" {
+		daysPerInterval = 7
+	}
+	numIntervals := r.Intervals
+	if numIntervals == 0 {
+		numIntervals = 7
+	}
+
+	resp := &previewEventResponse{
+		Total:     len(preview.Fires),
+		Skipped:   preview.Skipped,
+		Intervals: make([]*previewEventInterval, numIntervals),
+	}
+	ends := make([]time.Time, numIntervals)
+	for i := range numIntervals {
+		start := today.AddDate(0, 0, i*daysPerInterval)
+		resp.Intervals[i] = &previewEventInterval{Start: start.Format(time.DateOnly)}
+		ends[i] = start.AddDate(0, 0, daysPerInterval)
+	}
+
+	for _, fire := range preview.Fires {
+		counted := false
+		for i, end := range ends {
+			if fire.Before(end) {
+				resp.Intervals[i].Count++
+				counted = true
+				break
+			}
+		}
+		if !counted {
+			resp.Later++
+		}
+	}
+
+	return resp, http.StatusOK, nil
+}
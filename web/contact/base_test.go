@@ -1,9 +1,12 @@
 package contact_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/dbutil/assertdb"
 	"github.com/nyaruka/gocommon/i18n"
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/goflow/assets"
@@ -17,6 +20,14 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestBulkModify(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/bulk_modify.json", nil)
+}
+
 func TestCreate(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
@@ -38,18 +49,66 @@ func TestDeindex(t *testing.T) {
 	testsuite.RunWebTests(t, ctx, rt, "testdata/deindex.json", nil)
 }
 
+func TestErase(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "my secret", models.MsgStatusHandled)
+	testdata.InsertWaitingSession(rt, testdata.Org1, testdata.Cathy, models.FlowTypeMessaging, testdata.Favorites, models.NilCallID)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/erase.json", map[string]string{
+		"cathy_id": fmt.Sprint(testdata.Cathy.ID),
+	})
+}
+
 func TestExport(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
 	testsuite.RunWebTests(t, ctx, rt, "testdata/export.json", nil)
 }
 
+func TestExportData(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/export_data.json", nil)
+}
+
 func TestExportPreview(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
 	testsuite.RunWebTests(t, ctx, rt, "testdata/export_preview.json", nil)
 }
 
+func TestExportStart(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/export_start.json", nil)
+}
+
+func TestHistory(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	rt.DB.MustExec(`ALTER SEQUENCE contacts_contacthistory_id_seq RESTART WITH 1`)
+
+	// give Cathy a name change by a user and a language change by a flow, and Bob a name change
+	rt.DB.MustExec(
+		`INSERT INTO contacts_contacthistory(org_id, contact_id, event_type, event, created_by_id, flow_id, created_on) VALUES
+		($1, $2, 'contact_name_changed', '{"type": "contact_name_changed", "created_on": "2025-01-24T14:20:00Z", "name": "Cat"}', $4, NULL, '2025-01-24T14:20:00Z'),
+		($1, $3, 'contact_name_changed', '{"type": "contact_name_changed", "created_on": "2025-01-24T14:21:00Z", "name": "Bobby"}', $4, NULL, '2025-01-24T14:21:00Z'),
+		($1, $2, 'contact_language_changed', '{"type": "contact_language_changed", "created_on": "2025-01-24T14:22:00Z", "language": "spa"}', NULL, $5, '2025-01-24T14:22:00Z')`,
+		testdata.Org1.ID, testdata.Cathy.ID, testdata.Bob.ID, testdata.Admin.ID, testdata.Favorites.ID,
+	)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/history.json", map[string]string{
+		"cathy_id": fmt.Sprint(testdata.Cathy.ID),
+	})
+}
+
 func TestInspect(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
@@ -61,6 +120,45 @@ func TestInspect(t *testing.T) {
 	testsuite.RunWebTests(t, ctx, rt, "testdata/inspect.json", nil)
 }
 
+func TestLocks(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	defer testsuite.Reset(testsuite.ResetRedis)
+	defer dates.SetNowFunc(time.Now)
+
+	dates.SetNowFunc(dates.NewSequentialNow(time.Date(2018, 7, 6, 12, 28, 0, 0, time.UTC), time.Minute))
+
+	models.LockContacts(ctx, rt, testdata.Org1.ID, []models.ContactID{testdata.Cathy.ID, testdata.Bob.ID, testdata.George.ID}, time.Second)
+
+	// George's lock expires without being released
+	rc.Do("DEL", fmt.Sprintf("lock:c:%d:%d", testdata.Org1.ID, testdata.George.ID))
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/locks.json", map[string]string{"holder": rt.Config.InstanceID})
+}
+
+func TestMerge(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	// give Bob a message and both Cathy and Bob open tickets
+	testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Bob, "hello", models.MsgStatusHandled)
+	testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), nil)
+	bobTicket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.DefaultTopic, time.Now(), nil)
+
+	// give George a waiting session
+	testdata.InsertWaitingSession(rt, testdata.Org1, testdata.George, models.FlowTypeMessaging, testdata.Favorites, models.NilCallID)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/merge.json", map[string]string{
+		"cathy_id":      fmt.Sprint(testdata.Cathy.ID),
+		"bob_id":        fmt.Sprint(testdata.Bob.ID),
+		"george_id":     fmt.Sprint(testdata.George.ID),
+		"bob_ticket_id": fmt.Sprint(bobTicket.ID),
+	})
+}
+
 func TestModify(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
@@ -124,6 +222,44 @@ func TestURNs(t *testing.T) {
 	testsuite.RunWebTests(t, ctx, rt, "testdata/urns.json", nil)
 }
 
+func TestUnlock(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetRedis)
+
+	models.LockContacts(ctx, rt, testdata.Org1.ID, []models.ContactID{testdata.Cathy.ID}, time.Second)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/unlock.json", nil)
+
+	// Cathy can now be locked again
+	locks, skipped, err := models.LockContacts(ctx, rt, testdata.Org1.ID, []models.ContactID{testdata.Cathy.ID}, time.Second)
+	assert.NoError(t, err)
+	assert.Len(t, locks, 1)
+	assert.Len(t, skipped, 0)
+}
+
+func TestValidateImport(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData | testsuite.ResetRedis)
+
+	importID := testdata.InsertContactImport(rt, testdata.Org1, testdata.Admin)
+	testdata.InsertContactImportBatch(rt, importID, []byte(`[
+		{"name": "Ann", "urns": ["tel:+16055700001"], "_import_row": 2},
+		{"name": "Cathy", "language": "xx", "urns": ["tel:+16055741111"], "_import_row": 3}
+	]`))
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/validate_import.json", map[string]string{
+		"import_id": fmt.Sprint(importID),
+	})
+
+	// validation happens in a task which records the result on the import
+	assert.Equal(t, map[string]int{"validate_contact_import": 1}, testsuite.FlushTasks(t, rt, "batch"))
+
+	assertdb.Query(t, rt.DB, `SELECT validation->>'num_created' AS created, validation->>'num_updated' AS updated, validation->'errors'->0->>'message' AS error FROM contacts_contactimport WHERE id = $1`, importID).
+		Columns(map[string]any{"created": "1", "updated": "1", "error": "'xx' is not a valid language code"})
+}
+
 func TestSpecToCreation(t *testing.T) {
 	ctx, rt := testsuite.Runtime()
 
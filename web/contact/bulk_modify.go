@@ -0,0 +1,86 @@
+package contact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/goflow/contactql"
+	"github.com/nyaruka/mailroom/core/goflow"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/bulk_modify", web.RequireAuthToken(web.JSONPayload(handleBulkModify)))
+}
+
+// Request that modifiers are applied to all the contacts in a group or matching a query. This is done in the background
+// and the progress can be tracked on the returned bulk modify.
+//
+//	{
+//	  "org_id": 1,
+//	  "user_id": 1,
+//	  "query": "age > 18",
+//	  "modifiers": [{
+//	     "type": "groups",
+//	     "modification": "add",
+//	     "groups": [{
+//	         "uuid": "a8e8efdb-78ee-46e7-9eb0-6a578da3b02d",
+//	         "name": "Doctors"
+//	     }]
+//	  }]
+//	}
+type bulkModifyRequest struct {
+	OrgID     models.OrgID      `json:"org_id"    validate:"required"`
+	UserID    models.UserID     `json:"user_id"   validate:"required"`
+	GroupID   models.GroupID    `json:"group_id"`
+	Query     string            `json:"query"`
+	Modifiers []json.RawMessage `json:"modifiers" validate:"required,min=1"`
+}
+
+// handles a request to create a bulk modify
+func handleBulkModify(ctx context.Context, rt *runtime.Runtime, r *bulkModifyRequest) (any, int, error) {
+	if (r.GroupID == 0) == (r.Query == "") {
+		return errors.New("must provide one of group_id or query"), http.StatusBadRequest, nil
+	}
+
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, r.OrgID, models.RefreshFields|models.RefreshGroups)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	if r.GroupID != 0 && oa.GroupByID(r.GroupID) == nil {
+		return fmt.Errorf("no such group with id %d", r.GroupID), http.StatusBadRequest, nil
+	}
+	if r.Query != "" {
+		if _, err := contactql.ParseQuery(oa.Env(), r.Query, oa.SessionAssets()); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// check the modifiers are valid now rather than when the batches are processed
+	if _, err := goflow.ReadModifiers(oa.SessionAssets(), r.Modifiers, goflow.ErrorOnMissing); err != nil {
+		return err, http.StatusBadRequest, nil
+	}
+
+	m := models.NewContactBulkModify(r.OrgID, r.UserID, r.GroupID, r.Query, r.Modifiers)
+
+	if err := models.InsertContactBulkModify(ctx, rt.DB, m); err != nil {
+		return nil, 0, fmt.Errorf("error inserting contact bulk modify: %w", err)
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	if err := tasks.Queue(rc, tasks.BatchQueue, m.OrgID, &contacts.BulkModifyTask{ContactBulkModifyID: m.ID}, true); err != nil {
+		return nil, 0, fmt.Errorf("error queuing bulk modify task: %w", err)
+	}
+
+	return map[string]any{"id": m.ID}, http.StatusOK, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
 	"github.com/nyaruka/mailroom/runtime"
 	"github.com/nyaruka/mailroom/web"
 )
@@ -47,7 +48,7 @@ func handleCreate(ctx context.Context, rt *runtime.Runtime, r *createRequest) (a
 		return err, http.StatusBadRequest, nil
 	}
 
-	_, contact, err := models.CreateContact(ctx, rt.DB, oa, r.UserID, c.Name, c.Language, c.Status, c.URNs)
+	mc, contact, err := models.CreateContact(ctx, rt.DB, oa, r.UserID, c.Name, c.Language, c.Status, c.URNs)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -58,5 +59,14 @@ func handleCreate(ctx context.Context, rt *runtime.Runtime, r *createRequest) (a
 		return nil, 0, fmt.Errorf("error modifying new contact: %w", err)
 	}
 
+	if models.HasTelURN(c.URNs) {
+		rc := rt.RP.Get()
+		defer rc.Close()
+
+		if err := lookups.QueueURNLookups(rc, oa, []models.ContactID{mc.ID()}); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return map[string]any{"contact": contact}, http.StatusOK, nil
 }
@@ -0,0 +1,75 @@
+package contact
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/search"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/erase", web.RequireAuthToken(web.JSONPayload(handleErase)))
+}
+
+// Request that a contact's personal data is erased, e.g. to fulfil a right to erasure request. Their sessions are
+// interrupted, their URNs removed, their messages, run results, ticket notes and session outputs redacted, their
+// message attachments deleted from storage, and they are released and removed from the search index.
+//
+//	{
+//	  "org_id": 1,
+//	  "contact_id": 235
+//	}
+type eraseRequest struct {
+	OrgID     models.OrgID     `json:"org_id"     validate:"required"`
+	ContactID models.ContactID `json:"contact_id" validate:"required"`
+}
+
+// handles a request to erase a contact
+func handleErase(ctx context.Context, rt *runtime.Runtime, r *eraseRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	ids := []models.ContactID{r.ContactID}
+
+	locks, skipped, err := models.LockContacts(ctx, rt, oa.OrgID(), ids, time.Second*10)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer models.UnlockContacts(rt, oa.OrgID(), locks)
+
+	if len(skipped) > 0 {
+		return nil, 0, fmt.Errorf("unable to lock contact %d", r.ContactID)
+	}
+
+	contact, err := models.LoadContact(ctx, rt.DB, oa, r.ContactID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no such contact with id %d", r.ContactID), http.StatusBadRequest, nil
+		}
+		return nil, 0, fmt.Errorf("error loading contact: %w", err)
+	}
+
+	if _, err := models.InterruptSessionsForContacts(ctx, rt.DB, ids); err != nil {
+		return nil, 0, fmt.Errorf("error interrupting sessions: %w", err)
+	}
+
+	if err := models.EraseContacts(ctx, rt, oa, []*models.Contact{contact}); err != nil {
+		return nil, 0, fmt.Errorf("error erasing contact: %w", err)
+	}
+
+	if _, err := search.DeindexContactsByID(ctx, rt, oa.OrgID(), ids); err != nil {
+		return nil, 0, fmt.Errorf("error de-indexing contact: %w", err)
+	}
+
+	return map[string]any{"contact_id": r.ContactID}, http.StatusOK, nil
+}
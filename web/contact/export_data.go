@@ -0,0 +1,67 @@
+package contact
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/export_data", web.RequireAuthToken(web.JSONPayload(handleExportData)))
+}
+
+// Request for everything we know about a contact, e.g. to fulfil a data subject access request.
+//
+//	{
+//	  "org_id": 1,
+//	  "contact_id": 235
+//	}
+type exportDataRequest struct {
+	OrgID     models.OrgID     `json:"org_id"     validate:"required"`
+	ContactID models.ContactID `json:"contact_id" validate:"required"`
+}
+
+// handles a request to export a contact's data. The response includes the contact with their fields, URNs and groups,
+// and their messages, runs with results, tickets with notes and calls.
+//
+//	{
+//	  "contact": {
+//	    "uuid": "559d4cf7-8ed3-43db-9bbb-2be85345f87e",
+//	    "name": "Joe",
+//	    "urns": ["tel:+250788123123"],
+//	    "groups": [...],
+//	    "fields": {...},
+//	    ...
+//	  },
+//	  "msgs": [...],
+//	  "runs": [...],
+//	  "tickets": [...],
+//	  "calls": [...]
+//	}
+func handleExportData(ctx context.Context, rt *runtime.Runtime, r *exportDataRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	contact, err := models.LoadContact(ctx, rt.DB, oa, r.ContactID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no such contact with id %d", r.ContactID), http.StatusBadRequest, nil
+		}
+		return nil, 0, fmt.Errorf("error loading contact: %w", err)
+	}
+
+	data, err := models.LoadContactData(ctx, rt.DB, oa, contact)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading contact data: %w", err)
+	}
+
+	return data, http.StatusOK, nil
+}
@@ -0,0 +1,101 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/contactql"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/export_start", web.RequireAuthToken(web.JSONPayload(handleExportStart)))
+}
+
+// Request that the contacts in a group, optionally matching a query, are exported to a file. This is done in the
+// background and the user is notified when the file is ready.
+//
+//	{
+//	  "org_id": 1,
+//	  "user_id": 3,
+//	  "group_id": 45,
+//	  "query": "age < 65",
+//	  "format": "xlsx",
+//	  "fields": ["age", "gender"],
+//	  "urn_schemes": ["tel", "whatsapp"],
+//	  "group_ids": [46, 47]
+//	}
+//
+//	{
+//	  "id": 12
+//	}
+type exportStartRequest struct {
+	OrgID      models.OrgID        `json:"org_id"      validate:"required"`
+	UserID     models.UserID       `json:"user_id"     validate:"required"`
+	GroupID    models.GroupID      `json:"group_id"    validate:"required"`
+	Query      string              `json:"query"`
+	Format     models.ExportFormat `json:"format"      validate:"required,oneof=csv xlsx ndjson"`
+	Fields     []string            `json:"fields"`
+	URNSchemes []string            `json:"urn_schemes"`
+	GroupIDs   []models.GroupID    `json:"group_ids"`
+}
+
+// handles a request to start a contact export
+func handleExportStart(ctx context.Context, rt *runtime.Runtime, r *exportStartRequest) (any, int, error) {
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, r.OrgID, models.RefreshFields|models.RefreshGroups)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	if oa.GroupByID(r.GroupID) == nil {
+		return fmt.Errorf("no such group with id %d", r.GroupID), http.StatusBadRequest, nil
+	}
+	if r.Query != "" {
+		if _, err := contactql.ParseQuery(oa.Env(), r.Query, oa.SessionAssets()); err != nil {
+			return nil, 0, err
+		}
+	}
+	for _, key := range r.Fields {
+		if oa.FieldByKey(key) == nil {
+			return fmt.Errorf("no such field with key '%s'", key), http.StatusBadRequest, nil
+		}
+	}
+	for _, scheme := range r.URNSchemes {
+		if !urns.IsValidScheme(scheme) {
+			return fmt.Errorf("invalid URN scheme '%s'", scheme), http.StatusBadRequest, nil
+		}
+	}
+	for _, groupID := range r.GroupIDs {
+		if oa.GroupByID(groupID) == nil {
+			return fmt.Errorf("no such group with id %d", groupID), http.StatusBadRequest, nil
+		}
+	}
+
+	e := models.NewContactExport(r.OrgID, r.UserID, &models.ContactExportConfig{
+		GroupID:    r.GroupID,
+		Query:      r.Query,
+		Format:     r.Format,
+		Fields:     r.Fields,
+		URNSchemes: r.URNSchemes,
+		GroupIDs:   r.GroupIDs,
+	})
+
+	if err := models.InsertExport(ctx, rt.DB, e); err != nil {
+		return nil, 0, fmt.Errorf("error inserting export: %w", err)
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	if err := tasks.Queue(rc, tasks.BatchQueue, e.OrgID, &contacts.ExportContactsTask{ExportID: e.ID}, true); err != nil {
+		return nil, 0, fmt.Errorf("error queuing export task: %w", err)
+	}
+
+	return map[string]any{"id": e.ID}, http.StatusOK, nil
+}
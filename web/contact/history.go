@@ -0,0 +1,76 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/history", web.RequireAuthToken(web.JSONPayload(handleHistory)))
+}
+
+const (
+	historyDefaultLimit = 50
+	historyMaxLimit     = 250
+)
+
+// Request for a page of the change history of a contact, newest first. The next page can be requested by passing the
+// returned next_before value as before.
+//
+//	{
+//	  "org_id": 1,
+//	  "contact_id": 235,
+//	  "before": 3456,
+//	  "limit": 50
+//	}
+type historyRequest struct {
+	OrgID     models.OrgID            `json:"org_id"     validate:"required"`
+	ContactID models.ContactID        `json:"contact_id" validate:"required"`
+	Before    models.ContactHistoryID `json:"before"`
+	Limit     int                     `json:"limit"      validate:"omitempty,min=1"`
+}
+
+// Response for a contact history request. Each item records who made the change, either a user or a flow.
+//
+//	{
+//	  "history": [
+//	    {
+//	      "id": 3455,
+//	      "type": "contact_name_changed",
+//	      "event": {"type": "contact_name_changed", "created_on": "2025-01-24T14:20:00Z", "name": "Bob"},
+//	      "created_by_id": 3,
+//	      "created_on": "2025-01-24T14:20:00Z"
+//	    }
+//	  ],
+//	  "next_before": 3455
+//	}
+type historyResponse struct {
+	History    []*models.ContactHistory `json:"history"`
+	NextBefore models.ContactHistoryID  `json:"next_before,omitempty"`
+}
+
+// handles a request for a contact's change history
+func handleHistory(ctx context.Context, rt *runtime.Runtime, r *historyRequest) (any, int, error) {
+	limit := r.Limit
+	if limit == 0 {
+		limit = historyDefaultLimit
+	}
+	limit = min(limit, historyMaxLimit)
+
+	history, err := models.LoadContactHistory(ctx, rt.DB, r.OrgID, r.ContactID, r.Before, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading contact history: %w", err)
+	}
+
+	resp := &historyResponse{History: history}
+	if len(history) == limit {
+		resp.NextBefore = history[len(history)-1].ID
+	}
+
+	return resp, http.StatusOK, nil
+}
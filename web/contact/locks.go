@@ -0,0 +1,60 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/locks", web.RequireAuthToken(web.JSONPayload(handleLocks)))
+}
+
+// Request the locks currently held on contacts in an org, oldest first. Age is in seconds.
+//
+//	{
+//	  "org_id": 1
+//	}
+//
+//	{
+//	  "locks": [
+//	    {"contact_id": 235, "holder": "mailroom1", "grabbed_on": "2025-01-02T12:30:00Z", "age": 312}
+//	  ]
+//	}
+type locksRequest struct {
+	OrgID models.OrgID `json:"org_id" validate:"required"`
+}
+
+type lockInfo struct {
+	ContactID models.ContactID `json:"contact_id"`
+	Holder    string           `json:"holder"`
+	GrabbedOn time.Time        `json:"grabbed_on"`
+	Age       int              `json:"age"`
+}
+
+// handles a request to list contact locks
+func handleLocks(ctx context.Context, rt *runtime.Runtime, r *locksRequest) (any, int, error) {
+	locks, err := models.LoadContactLocks(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading contact locks: %w", err)
+	}
+
+	now := dates.Now()
+	infos := make([]*lockInfo, len(locks))
+	for i, l := range locks {
+		infos[i] = &lockInfo{
+			ContactID: l.ContactID,
+			Holder:    l.Holder,
+			GrabbedOn: l.GrabbedOn,
+			Age:       int(now.Sub(l.GrabbedOn).Seconds()),
+		}
+	}
+
+	return map[string]any{"locks": infos}, http.StatusOK, nil
+}
@@ -0,0 +1,181 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/modifiers"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/runner"
+	"github.com/nyaruka/mailroom/core/search"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/merge", web.RequireAuthToken(web.JSONPayload(handleMerge)))
+}
+
+// Request that one or more secondary contacts are merged into a primary contact. The URNs, fields and groups of the
+// secondaries are added to the primary, their history is moved to the primary, and they are then released. A merge
+// that fails part way can be retried.
+//
+//	{
+//	  "org_id": 1,
+//	  "user_id": 3,
+//	  "primary_id": 235,
+//	  "secondary_ids": [236, 237],
+//	  "strategy": "newest_wins"
+//	}
+type mergeRequest struct {
+	OrgID        models.OrgID                `json:"org_id"        validate:"required"`
+	UserID       models.UserID               `json:"user_id"       validate:"required"`
+	PrimaryID    models.ContactID            `json:"primary_id"    validate:"required"`
+	SecondaryIDs []models.ContactID          `json:"secondary_ids" validate:"required,min=1"`
+	Strategy     models.ContactMergeStrategy `json:"strategy"      validate:"omitempty,oneof=primary_wins newest_wins"`
+}
+
+// Response for a contact merge, including the ids of any tickets which were merged into the primary's open ticket.
+//
+//	{
+//	  "contact_id": 235,
+//	  "merged": [236, 237],
+//	  "merged_tickets": [1234]
+//	}
+type mergeResponse struct {
+	ContactID     models.ContactID   `json:"contact_id"`
+	Merged        []models.ContactID `json:"merged"`
+	MergedTickets []models.TicketID  `json:"merged_tickets"`
+}
+
+// handles a request to merge contacts
+func handleMerge(ctx context.Context, rt *runtime.Runtime, r *mergeRequest) (any, int, error) {
+	if slices.Contains(r.SecondaryIDs, r.PrimaryID) {
+		return fmt.Errorf("primary contact can't also be a secondary contact"), http.StatusBadRequest, nil
+	}
+	if r.Strategy == "" {
+		r.Strategy = models.ContactMergePrimaryWins
+	}
+
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	ids := append([]models.ContactID{r.PrimaryID}, r.SecondaryIDs...)
+
+	locks, skipped, err := models.LockContacts(ctx, rt, oa.OrgID(), ids, time.Second*10)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer models.UnlockContacts(rt, oa.OrgID(), locks)
+
+	if len(skipped) > 0 {
+		return nil, 0, fmt.Errorf("unable to lock contacts %v", skipped)
+	}
+
+	contacts, err := models.LoadContacts(ctx, rt.DB, oa, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load contacts: %w", err)
+	}
+	if len(contacts) != len(ids) {
+		return fmt.Errorf("no such contacts to merge"), http.StatusBadRequest, nil
+	}
+
+	var primary *models.Contact
+	secondaries := make([]*models.Contact, 0, len(r.SecondaryIDs))
+	for _, c := range contacts {
+		if c.ID() == r.PrimaryID {
+			primary = c
+		} else {
+			secondaries = append(secondaries, c)
+		}
+	}
+
+	fc, err := primary.FlowContact(oa)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating flow contact: %w", err)
+	}
+
+	// add the URNs, fields and groups of the secondaries to the primary via modifiers so that the resulting events are
+	// handled like any other change, i.e. URNs are moved by UpdateContactURNs, and campaign fires and smart groups are
+	// recalculated
+	mods := mergeModifiers(oa, primary, secondaries, r.Strategy)
+
+	if _, err := runner.ApplyModifiers(ctx, rt, oa, r.UserID, map[*flows.Contact][]flows.Modifier{fc: mods}); err != nil {
+		return nil, 0, fmt.Errorf("error applying merge modifiers: %w", err)
+	}
+
+	// if this fails the modifiers above have already been applied, but re-applying them on a retry is a noop
+	tx, err := rt.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	ticketEvents, err := models.MergeContactHistory(ctx, tx, oa, r.UserID, primary, secondaries)
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("error merging contact history: %w", err)
+	}
+
+	if err := models.ReleaseContacts(ctx, tx, r.UserID, r.SecondaryIDs); err != nil {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("error releasing secondary contacts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	if _, err := search.DeindexContactsByID(ctx, rt, oa.OrgID(), r.SecondaryIDs); err != nil {
+		return nil, 0, fmt.Errorf("error de-indexing secondary contacts: %w", err)
+	}
+
+	mergedTickets := make([]models.TicketID, 0, len(ticketEvents))
+	for t := range ticketEvents {
+		mergedTickets = append(mergedTickets, t.ID())
+	}
+	slices.Sort(mergedTickets)
+
+	return &mergeResponse{ContactID: primary.ID(), Merged: r.SecondaryIDs, MergedTickets: mergedTickets}, http.StatusOK, nil
+}
+
+// builds the modifiers to add the URNs, fields and groups of the secondaries to the primary
+func mergeModifiers(oa *models.OrgAssets, primary *models.Contact, secondaries []*models.Contact, strategy models.ContactMergeStrategy) []flows.Modifier {
+	mods := make([]flows.Modifier, 0, 5)
+
+	// URNs are added without their ids so that they're re-assigned to the primary
+	urnz := make([]urns.URN, 0, 5)
+	for _, c := range secondaries {
+		for _, u := range c.URNs() {
+			urnz = append(urnz, u.Identity())
+		}
+	}
+	if len(urnz) > 0 {
+		mods = append(mods, modifiers.NewURNs(urnz, modifiers.URNsAppend))
+	}
+
+	for key, value := range models.MergeContactFields(primary, secondaries, strategy) {
+		if field := oa.SessionAssets().Fields().Get(key); field != nil {
+			mods = append(mods, modifiers.NewField(field, value.Text.Native()))
+		}
+	}
+
+	groups := make([]*flows.Group, 0, 5)
+	for _, g := range models.MergeContactGroups(primary, secondaries) {
+		if group := oa.SessionAssets().Groups().Get(g.UUID()); group != nil {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) > 0 {
+		mods = append(mods, modifiers.NewGroups(groups, modifiers.GroupsAdd))
+	}
+
+	return mods
+}
@@ -0,0 +1,41 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/unlock", web.RequireAuthToken(web.JSONPayload(handleUnlock)))
+}
+
+// Request that the lock on a contact is released regardless of who holds it. This is for locks left behind by
+// holders which failed without releasing them.
+//
+//	{
+//	  "org_id": 1,
+//	  "contact_id": 235
+//	}
+//
+//	{
+//	  "released": true
+//	}
+type unlockRequest struct {
+	OrgID     models.OrgID     `json:"org_id"     validate:"required"`
+	ContactID models.ContactID `json:"contact_id" validate:"required"`
+}
+
+// handles a request to force release a contact lock
+func handleUnlock(ctx context.Context, rt *runtime.Runtime, r *unlockRequest) (any, int, error) {
+	released, err := models.ForceUnlockContact(ctx, rt, r.OrgID, r.ContactID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error releasing contact lock: %w", err)
+	}
+
+	return map[string]any{"released": released}, http.StatusOK, nil
+}
@@ -0,0 +1,59 @@
+package contact
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/tasks"
+	"github.com/nyaruka/mailroom/core/tasks/contacts"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/contact/validate_import", web.RequireAuthToken(web.JSONPayload(handleValidateImport)))
+}
+
+// Request that a contact import is validated without importing anything, so that problems can be fixed first.
+// Validation happens in a background task which writes a summary of what the import would do, and the errors for
+// each record, to the validation column of the import.
+//
+//	{
+//	  "org_id": 1,
+//	  "import_id": 123
+//	}
+type validateImportRequest struct {
+	OrgID    models.OrgID           `json:"org_id"    validate:"required"`
+	ImportID models.ContactImportID `json:"import_id" validate:"required"`
+}
+
+// handles a request to validate a contact import
+//
+//	{
+//	  "import_id": 123
+//	}
+func handleValidateImport(ctx context.Context, rt *runtime.Runtime, r *validateImportRequest) (any, int, error) {
+	imp, err := models.LoadContactImport(ctx, rt.DB, r.ImportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no such contact import with id %d", r.ImportID), http.StatusBadRequest, nil
+		}
+		return nil, 0, err
+	}
+	if imp.OrgID != r.OrgID {
+		return fmt.Errorf("no such contact import with id %d", r.ImportID), http.StatusBadRequest, nil
+	}
+
+	rc := rt.RP.Get()
+	defer rc.Close()
+
+	if err := tasks.Queue(rc, tasks.BatchQueue, imp.OrgID, &contacts.ValidateContactImportTask{ContactImportID: imp.ID}, true); err != nil {
+		return nil, 0, fmt.Errorf("error queuing validate contact import task: %w", err)
+	}
+
+	return map[string]any{"import_id": imp.ID}, http.StatusOK, nil
+}
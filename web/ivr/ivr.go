@@ -16,6 +16,7 @@ import (
 	"github.com/nyaruka/mailroom/core/ivr"
 	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/core/tasks/handler/ctasks"
+	"github.com/nyaruka/mailroom/core/tasks/lookups"
 	"github.com/nyaruka/mailroom/runtime"
 	"github.com/nyaruka/mailroom/utils/clogs"
 	"github.com/nyaruka/mailroom/web"
@@ -104,11 +105,21 @@ func handleIncoming(ctx context.Context, rt *runtime.Runtime, oa *models.OrgAsse
 	}
 
 	// get the contact for this URN
-	contact, _, _, err := models.GetOrCreateContact(ctx, rt.DB, oa, userID, []urns.URN{urn}, ch.ID())
+	contact, _, created, err := models.GetOrCreateContact(ctx, rt.DB, oa, userID, []urns.URN{urn}, ch.ID())
 	if err != nil {
 		return nil, svc.WriteErrorResponse(w, fmt.Errorf("unable to get contact by urn: %w", err))
 	}
 
+	// if we created a new contact, look up its number
+	if created {
+		rc := rt.RP.Get()
+		err := lookups.QueueURNLookups(rc, oa, []models.ContactID{contact.ID()})
+		rc.Close()
+		if err != nil {
+			return nil, svc.WriteErrorResponse(w, fmt.Errorf("unable to queue URN lookups for new contact: %w", err))
+		}
+	}
+
 	urn, err = models.URNForURN(ctx, rt.DB, oa, urn)
 	if err != nil {
 		return nil, svc.WriteErrorResponse(w, fmt.Errorf("unable to load urn: %w", err))
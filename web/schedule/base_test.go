@@ -0,0 +1,13 @@
+package schedule_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/mailroom/testsuite"
+)
+
+func TestPreview(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/preview.json", nil)
+}
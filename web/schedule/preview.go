@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/schedule/preview", web.RequireAuthToken(web.JSONPayload(handlePreview)))
+}
+
+// Generates a preview of the upcoming fires of a schedule in the org's timezone.
+//
+//	{
+//	  "org_id": 1,
+//	  "schedule": {
+//	    "start": "2024-01-31T09:00:00Z",
+//	    "repeat_period": "M",
+//	    "repeat_days_of_week": ""
+//	  },
+//	  "count": 5
+//	}
+//
+//	{
+//	  "timezone": "America/Los_Angeles",
+//	  "fires": [
+//	    {"time": "2024-01-31T01:00:00-08:00"},
+//	    {"time": "2024-02-29T01:00:00-08:00", "notes": ["month has no day 31 so fires on the last day of the month"]},
+//	    {"time": "2024-03-31T01:00:00-07:00", "notes": ["UTC offset changes from -08:00 to -07:00 (PDT)"]},
+//	    ...
+//	  ]
+//	}
+type previewRequest struct {
+	OrgID    models.OrgID `json:"org_id"   validate:"required"`
+	Schedule struct {
+		Start            time.Time           `json:"start"               validate:"required"`
+		RepeatPeriod     models.RepeatPeriod `json:"repeat_period"       validate:"required"`
+		RepeatDaysOfWeek string              `json:"repeat_days_of_week"`
+	} `json:"schedule"`
+	Count int `json:"count" validate:"required,min=1,max=100"`
+}
+
+type previewResponse struct {
+	Timezone string                 `json:"timezone"`
+	Fires    []*models.ScheduleFire `json:"fires"`
+}
+
+func handlePreview(ctx context.Context, rt *runtime.Runtime, r *previewRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	// build the schedule exactly as it would be built if it were being created
+	sched, err := models.NewSchedule(oa, r.Schedule.Start, r.Schedule.RepeatPeriod, r.Schedule.RepeatDaysOfWeek)
+	if err != nil {
+		return fmt.Errorf("error creating schedule: %w", err), http.StatusBadRequest, nil
+	}
+
+	fires, err := sched.GetNextFires(r.Count)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error calculating schedule fires: %w", err)
+	}
+
+	return &previewResponse{Timezone: sched.Timezone, Fires: fires}, http.StatusOK, nil
+}
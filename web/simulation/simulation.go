@@ -199,7 +199,7 @@ func handleResume(ctx context.Context, rt *runtime.Runtime, r *resumeRequest) (a
 	// if this is a msg resume we want to check whether it might be caught by a trigger
 	if resume.Type() == resumes.TypeMsg {
 		msgResume := resume.(*resumes.MsgResume)
-		trigger, keyword := models.FindMatchingMsgTrigger(oa, nil, msgResume.Contact(), msgResume.Msg().Text())
+		trigger, keyword, params := models.FindMatchingMsgTrigger(oa, nil, msgResume.Contact(), msgResume.Msg().Text())
 		if trigger != nil {
 			var flow *models.Flow
 			for _, r := range session.Runs() {
@@ -228,11 +228,10 @@ func handleResume(ctx context.Context, rt *runtime.Runtime, r *resumeRequest) (a
 						// non-simulation IVR triggers to use that so that this is consistent.
 						sessionTrigger = tb.Manual().WithCall(testChannel, testURN).Build()
 					} else {
-						mtb := tb.Msg(msgResume.Msg())
-						if keyword != "" {
-							mtb = mtb.WithMatch(&triggers.KeywordMatch{Type: trigger.KeywordMatchType(), Keyword: keyword})
+						sessionTrigger, err = models.NewMsgTrigger(oa, triggeredFlow, resume.Contact(), msgResume.Msg(), trigger, keyword, params)
+						if err != nil {
+							return nil, 0, fmt.Errorf("unable to build msg trigger: %w", err)
 						}
-						sessionTrigger = mtb.Build()
 					}
 
 					return triggerFlow(ctx, rt, oa, sessionTrigger)
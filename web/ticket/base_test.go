@@ -1,9 +1,12 @@
 package ticket
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/nyaruka/gocommon/i18n"
+	"github.com/nyaruka/mailroom/core/models"
 	"github.com/nyaruka/mailroom/testsuite"
 	"github.com/nyaruka/mailroom/testsuite/testdata"
 )
@@ -74,3 +77,60 @@ func TestTicketReopen(t *testing.T) {
 
 	testsuite.RunWebTests(t, ctx, rt, "testdata/reopen.json", nil)
 }
+
+func TestTicketReply(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	cathyTicket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), testdata.Agent)
+	bobTicket := testdata.InsertClosedTicket(rt, testdata.Org1, testdata.Bob, testdata.DefaultTopic, nil)
+	greeting := testdata.InsertCannedResponse(rt, testdata.Org1, "Greeting", "Hi @contact.first_name, how can we help?", map[i18n.Language]string{"spa": "Hola @contact.first_name, ¿cómo podemos ayudar?"})
+	empty := testdata.InsertCannedResponse(rt, testdata.Org1, "Empty", "", nil)
+
+	// Bob prefers Spanish
+	rt.DB.MustExec(`UPDATE orgs_org SET flow_languages = '{"eng", "spa"}' WHERE id = $1`, testdata.Org1.ID)
+	rt.DB.MustExec(`UPDATE contacts_contact SET language = 'spa' WHERE id = $1`, testdata.Bob.ID)
+
+	models.FlushCache()
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/reply.json", map[string]string{
+		"cathy_ticket_id": fmt.Sprint(cathyTicket.ID),
+		"bob_ticket_id":   fmt.Sprint(bobTicket.ID),
+		"greeting_id":     fmt.Sprint(greeting.ID),
+		"empty_id":        fmt.Sprint(empty.ID),
+	})
+}
+
+func TestTicketMerge(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	cathyTicket1 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), testdata.Admin)
+	cathyTicket2 := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.SalesTopic, time.Now(), nil)
+	bobTicket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Bob, testdata.DefaultTopic, time.Now(), nil)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/merge.json", map[string]string{
+		"cathy_ticket1_id": fmt.Sprint(cathyTicket1.ID),
+		"cathy_ticket2_id": fmt.Sprint(cathyTicket2.ID),
+		"bob_ticket_id":    fmt.Sprint(bobTicket.ID),
+	})
+}
+
+func TestTicketSplit(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetData)
+
+	ticket := testdata.InsertOpenTicket(rt, testdata.Org1, testdata.Cathy, testdata.DefaultTopic, time.Now(), testdata.Admin)
+	msg1 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "hi", models.MsgStatusHandled)
+	msg2 := testdata.InsertIncomingMsg(rt, testdata.Org1, testdata.TwilioChannel, testdata.Cathy, "other question", models.MsgStatusHandled)
+	rt.DB.MustExec(`UPDATE msgs_msg SET ticket_id = $1 WHERE id = $2 OR id = $3`, ticket.ID, msg1.ID, msg2.ID)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/split.json", map[string]string{
+		"ticket_id": fmt.Sprint(ticket.ID),
+		"msg1_id":   fmt.Sprint(msg1.ID),
+		"msg2_id":   fmt.Sprint(msg2.ID),
+	})
+}
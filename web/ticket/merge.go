@@ -0,0 +1,79 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/ticket/merge", web.RequireAuthToken(web.JSONPayload(handleMerge)))
+}
+
+// Merges the tickets with the given ids into the target ticket. All tickets must belong to the same contact.
+//
+//	{
+//	  "org_id": 123,
+//	  "user_id": 234,
+//	  "ticket_id": 1234,
+//	  "ticket_ids": [2345, 3456]
+//	}
+type mergeRequest struct {
+	bulkTicketRequest
+
+	TicketID models.TicketID `json:"ticket_id" validate:"required"`
+}
+
+func handleMerge(ctx context.Context, rt *runtime.Runtime, r *mergeRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	tickets, err := models.LoadTickets(ctx, rt.DB, append([]models.TicketID{r.TicketID}, r.TicketIDs...))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading tickets for org: %d: %w", r.OrgID, err)
+	}
+
+	var target *models.Ticket
+	merged := make([]*models.Ticket, 0, len(tickets))
+	for _, t := range tickets {
+		if t.OrgID() != r.OrgID {
+			return fmt.Errorf("no such ticket: %d", t.ID()), http.StatusBadRequest, nil
+		}
+		if t.ID() == r.TicketID {
+			target = t
+		} else {
+			merged = append(merged, t)
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("no such ticket: %d", r.TicketID), http.StatusBadRequest, nil
+	}
+	for _, t := range merged {
+		if t.ContactID() != target.ContactID() {
+			return fmt.Errorf("ticket %d belongs to a different contact", t.ID()), http.StatusBadRequest, nil
+		}
+	}
+
+	tx, err := rt.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	evts, err := models.MergeTickets(ctx, tx, oa, r.UserID, target, merged)
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("error merging tickets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return newBulkResponse(evts), http.StatusOK, nil
+}
@@ -0,0 +1,98 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/core/msgio"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/ticket/reply", web.RequireAuthToken(web.JSONPayload(handleReply)))
+}
+
+// Replies to the given ticket using a canned response, evaluating any expressions against the contact.
+//
+//	{
+//	  "org_id": 123,
+//	  "user_id": 234,
+//	  "ticket_id": 1234,
+//	  "canned_response_id": 345
+//	}
+type replyRequest struct {
+	OrgID            models.OrgID            `json:"org_id"             validate:"required"`
+	UserID           models.UserID           `json:"user_id"            validate:"required"`
+	TicketID         models.TicketID         `json:"ticket_id"          validate:"required"`
+	CannedResponseID models.CannedResponseID `json:"canned_response_id" validate:"required"`
+}
+
+func handleReply(ctx context.Context, rt *runtime.Runtime, r *replyRequest) (any, int, error) {
+	// canned responses are edited by agents so always use the latest
+	oa, err := models.GetOrgAssetsWithRefresh(ctx, rt, r.OrgID, models.RefreshCannedResps)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	response := oa.CannedResponseByID(r.CannedResponseID)
+	if response == nil {
+		return fmt.Errorf("no such canned response: %d", r.CannedResponseID), http.StatusBadRequest, nil
+	}
+
+	tickets, err := models.LoadTickets(ctx, rt.DB, []models.TicketID{r.TicketID})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading ticket: %w", err)
+	}
+	if len(tickets) == 0 || tickets[0].OrgID() != r.OrgID {
+		return fmt.Errorf("no such ticket: %d", r.TicketID), http.StatusBadRequest, nil
+	}
+	ticket := tickets[0]
+
+	// load the contact and generate as a flow contact
+	c, err := models.LoadContact(ctx, rt.DB, oa, ticket.ContactID())
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading contact: %w", err)
+	}
+
+	contact, err := c.FlowContact(oa)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating flow contact: %w", err)
+	}
+
+	content, locale, expressionsContext := response.Content(oa, contact)
+	if content.Empty() {
+		return fmt.Errorf("canned response %d has no content", r.CannedResponseID), http.StatusBadRequest, nil
+	}
+
+	out, ch := models.CreateMsgOut(rt, oa, contact, content, models.NilTemplateID, nil, locale, expressionsContext)
+
+	msg, err := models.NewOutgoingTicketMsg(rt, oa.Org(), ch, contact, out, ticket.ID(), r.UserID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating outgoing message: %w", err)
+	}
+
+	if err := models.InsertMessages(ctx, rt.DB, []*models.Msg{msg}); err != nil {
+		return nil, 0, fmt.Errorf("error inserting outgoing message: %w", err)
+	}
+
+	if err := models.RecordTicketReply(ctx, rt.DB, oa, ticket.ID(), r.UserID, dates.Now()); err != nil {
+		return nil, 0, fmt.Errorf("error recording ticket reply: %w", err)
+	}
+
+	msgio.QueueMessages(ctx, rt, []*models.Msg{msg})
+
+	return map[string]any{
+		"id":          msg.ID(),
+		"channel":     out.Channel(),
+		"contact":     contact.Reference(),
+		"urn":         out.URN(),
+		"text":        msg.Text(),
+		"status":      msg.Status(),
+		"created_on":  msg.CreatedOn(),
+		"modified_on": msg.ModifiedOn(),
+	}, http.StatusOK, nil
+}
@@ -0,0 +1,67 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/ticket/split", web.RequireAuthToken(web.JSONPayload(handleSplit)))
+}
+
+// Splits the given messages of a ticket off into a new ticket for the same contact.
+//
+//	{
+//	  "org_id": 123,
+//	  "user_id": 234,
+//	  "ticket_id": 1234,
+//	  "msg_ids": [4567, 5678]
+//	}
+type splitRequest struct {
+	OrgID    models.OrgID    `json:"org_id"    validate:"required"`
+	UserID   models.UserID   `json:"user_id"   validate:"required"`
+	TicketID models.TicketID `json:"ticket_id" validate:"required"`
+	MsgIDs   []models.MsgID  `json:"msg_ids"   validate:"required,min=1"`
+}
+
+func handleSplit(ctx context.Context, rt *runtime.Runtime, r *splitRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	tickets, err := models.LoadTickets(ctx, rt.DB, []models.TicketID{r.TicketID})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading ticket: %w", err)
+	}
+	if len(tickets) == 0 || tickets[0].OrgID() != r.OrgID {
+		return fmt.Errorf("no such ticket: %d", r.TicketID), http.StatusBadRequest, nil
+	}
+
+	tx, err := rt.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	split, err := models.SplitTicket(ctx, tx, oa, r.UserID, tickets[0], r.MsgIDs)
+	if err != nil {
+		tx.Rollback()
+
+		if errors.Is(err, models.ErrNotFound) {
+			return fmt.Errorf("error splitting ticket: %w", err), http.StatusBadRequest, nil
+		}
+		return nil, 0, fmt.Errorf("error splitting ticket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return map[string]any{"ticket_id": split.ID()}, http.StatusOK, nil
+}
@@ -0,0 +1,38 @@
+package trigger_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/testsuite"
+	"github.com/nyaruka/mailroom/testsuite/testdata"
+)
+
+func TestExplain(t *testing.T) {
+	ctx, rt := testsuite.Runtime()
+
+	defer testsuite.Reset(testsuite.ResetAll)
+
+	rt.DB.MustExec(`DELETE FROM triggers_trigger`)
+
+	joinID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{"join"}, models.MatchFirst, nil, nil, nil)
+	joinTwilioID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{"join"}, models.MatchFirst, nil, nil, testdata.TwilioChannel)
+	joinDoctorsID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{"join"}, models.MatchFirst, []*testdata.Group{testdata.DoctorsGroup}, nil, nil)
+	stopID := testdata.InsertKeywordTrigger(rt, testdata.Org1, testdata.Favorites, []string{"stop"}, models.MatchOnly, nil, nil, nil)
+	catchallID := testdata.InsertCatchallTrigger(rt, testdata.Org1, testdata.Favorites, nil, nil, nil)
+	referralAcmeID := testdata.InsertReferralTrigger(rt, testdata.Org1, testdata.Favorites, "acme", nil)
+	referralAnyID := testdata.InsertReferralTrigger(rt, testdata.Org1, testdata.Favorites, "", nil)
+
+	testsuite.RunWebTests(t, ctx, rt, "testdata/explain.json", map[string]string{
+		"join_id":           fmt.Sprint(joinID),
+		"join_twilio_id":    fmt.Sprint(joinTwilioID),
+		"join_doctors_id":   fmt.Sprint(joinDoctorsID),
+		"stop_id":           fmt.Sprint(stopID),
+		"catchall_id":       fmt.Sprint(catchallID),
+		"referral_acme_id":  fmt.Sprint(referralAcmeID),
+		"referral_any_id":   fmt.Sprint(referralAnyID),
+		"twilio_channel_id": fmt.Sprint(testdata.TwilioChannel.ID),
+		"vonage_channel_id": fmt.Sprint(testdata.VonageChannel.ID),
+	})
+}
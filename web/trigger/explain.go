@@ -0,0 +1,149 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/mailroom/core/models"
+	"github.com/nyaruka/mailroom/runtime"
+	"github.com/nyaruka/mailroom/web"
+)
+
+func init() {
+	web.RegisterRoute(http.MethodPost, "/mr/trigger/explain", web.RequireAuthToken(web.JSONPayload(handleExplain)))
+}
+
+var eventTriggerTypes = map[string]models.TriggerType{
+	"incoming_call":       models.IncomingCallTriggerType,
+	"missed_call":         models.MissedCallTriggerType,
+	"new_conversation":    models.NewConversationTriggerType,
+	"referral":            models.ReferralTriggerType,
+	"optin":               models.OptInTriggerType,
+	"optout":              models.OptOutTriggerType,
+	"ticket_closed":       models.TicketClosedTriggerType,
+	"ticket_sla_breached": models.TicketSLABreachedTriggerType,
+}
+
+// Explains which trigger would be selected for an incoming message or event from a contact, and why.
+//
+//	{
+//	  "org_id": 1,
+//	  "contact_id": 10000,
+//	  "channel_id": 10,
+//	  "text": "join now"
+//	}
+//
+//	{
+//	  "org_id": 1,
+//	  "contact_id": 10000,
+//	  "channel_id": 10,
+//	  "event_type": "referral",
+//	  "referrer_id": "acme"
+//	}
+type explainRequest struct {
+	OrgID      models.OrgID     `json:"org_id"      validate:"required"`
+	ContactID  models.ContactID `json:"contact_id"  validate:"required"`
+	ChannelID  models.ChannelID `json:"channel_id"`
+	Text       string           `json:"text"`
+	EventType  string           `json:"event_type"  validate:"omitempty,eq=incoming_call|eq=missed_call|eq=new_conversation|eq=referral|eq=optin|eq=optout|eq=ticket_closed|eq=ticket_sla_breached"`
+	ReferrerID string           `json:"referrer_id"`
+}
+
+//	{
+//	  "candidates": [
+//	    {
+//	      "trigger_id": 123,
+//	      "trigger_type": "K",
+//	      "flow": {"uuid": "9de3663f-c5c5-4c92-9f45-ecbc09abcc85", "name": "Favorites"},
+//	      "keyword": "join",
+//	      "score": 4
+//	    },
+//	    {
+//	      "trigger_id": 124,
+//	      "trigger_type": "K",
+//	      "flow": {"uuid": "9de3663f-c5c5-4c92-9f45-ecbc09abcc85", "name": "Favorites"},
+//	      "keyword": "join",
+//	      "score": 0,
+//	      "rejection": "outscored"
+//	    },
+//	    {
+//	      "trigger_id": 125,
+//	      "trigger_type": "C",
+//	      "flow": {"uuid": "a7c11d68-f008-496f-b56d-2d5cf4cf16a5", "name": "Single Message"},
+//	      "score": 0,
+//	      "rejection": "keyword_matched"
+//	    }
+//	  ],
+//	  "winner_id": 123
+//	}
+type candidateInfo struct {
+	TriggerID   models.TriggerID        `json:"trigger_id"`
+	TriggerType models.TriggerType      `json:"trigger_type"`
+	Flow        *assets.FlowReference   `json:"flow"`
+	Keyword     string                  `json:"keyword,omitempty"`
+	Score       int                     `json:"score"`
+	Rejection   models.TriggerRejection `json:"rejection,omitempty"`
+}
+
+type explainResponse struct {
+	Candidates []*candidateInfo `json:"candidates"`
+	WinnerID   models.TriggerID `json:"winner_id,omitempty"`
+}
+
+// handles a request to explain trigger selection
+func handleExplain(ctx context.Context, rt *runtime.Runtime, r *explainRequest) (any, int, error) {
+	oa, err := models.GetOrgAssets(ctx, rt, r.OrgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to load org assets: %w", err)
+	}
+
+	var channel *models.Channel
+	if r.ChannelID != models.NilChannelID {
+		channel = oa.ChannelByID(r.ChannelID)
+		if channel == nil {
+			return fmt.Errorf("no such channel with id: %d", r.ChannelID), http.StatusBadRequest, nil
+		}
+	}
+
+	mc, err := models.LoadContact(ctx, rt.DB, oa, r.ContactID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error loading contact: %w", err)
+	}
+	contact, err := mc.FlowContact(oa)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating flow contact: %w", err)
+	}
+
+	var candidates []*models.TriggerCandidate
+	var winner *models.Trigger
+
+	if r.EventType != "" {
+		candidates, winner = models.ExplainEventTrigger(oa, eventTriggerTypes[r.EventType], channel, contact, r.ReferrerID)
+	} else {
+		candidates, winner = models.ExplainMsgTrigger(oa, channel, contact, r.Text)
+	}
+
+	resp := &explainResponse{Candidates: make([]*candidateInfo, len(candidates))}
+	for i, c := range candidates {
+		var flowRef *assets.FlowReference
+		if flow, err := oa.FlowByID(c.Trigger.FlowID()); err == nil {
+			flowRef = flow.Reference()
+		}
+
+		resp.Candidates[i] = &candidateInfo{
+			TriggerID:   c.Trigger.ID(),
+			TriggerType: c.Trigger.TriggerType(),
+			Flow:        flowRef,
+			Keyword:     c.Keyword,
+			Score:       c.Score,
+			Rejection:   c.Rejection,
+		}
+	}
+	if winner != nil {
+		resp.WinnerID = winner.ID()
+	}
+
+	return resp, http.StatusOK, nil
+}